@@ -0,0 +1,165 @@
+package autodiff
+
+import (
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/multivariate"
+	"github.com/btracey/gofunopter/univariate"
+
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestDerivatives(t *testing.T) {
+	c := Const(1.7)
+	for _, test := range []struct {
+		name string
+		f    func(x Dual) Dual
+		x    float64
+	}{
+		{"Add", func(x Dual) Dual { return Add(x, c) }, 0.3},
+		{"Sub", func(x Dual) Dual { return Sub(c, x) }, 0.3},
+		{"Neg", Neg, 0.3},
+		{"Mul", func(x Dual) Dual { return Mul(x, Mul(x, c)) }, 0.3},
+		{"Div", func(x Dual) Dual { return Div(c, AddConst(2, x)) }, 0.3},
+		{"Scale", func(x Dual) Dual { return Scale(-3, x) }, 0.3},
+		{"Inv", Inv, 0.3},
+		{"PowReal", func(x Dual) Dual { return PowReal(x, 3.5) }, 0.3},
+		{"PowRealSquare", func(x Dual) Dual { return PowReal(x, 2) }, -0.3},
+		{"PowBase", func(x Dual) Dual { return Pow(c, x) }, 0.3},
+		{"Pow", func(x Dual) Dual { return Pow(x, x) }, 0.3},
+		{"Sqrt", Sqrt, 0.3},
+		{"Exp", Exp, 0.3},
+		{"Log", Log, 0.3},
+		{"Sin", Sin, 0.3},
+		{"Cos", Cos, 0.3},
+		{"Tan", Tan, 0.3},
+		{"Atan", Atan, 0.3},
+		{"Sinh", Sinh, 0.3},
+		{"Cosh", Cosh, 0.3},
+		{"Tanh", Tanh, 0.3},
+		{"Abs", Abs, -0.3},
+		{"Max", func(x Dual) Dual { return Max(x, Scale(2, x)) }, 0.3},
+		{"Min", func(x Dual) Dual { return Min(x, Scale(2, x)) }, 0.3},
+		{"Sum", func(x Dual) Dual { return Sum([]Dual{x, Sin(x), c}) }, 0.3},
+		{"Dot", func(x Dual) Dual { return Dot([]Dual{x, c}, []Dual{Exp(x), x}) }, 0.3},
+	} {
+		d := test.f(Variable(test.x))
+		if v := test.f(Const(test.x)); v.Real != d.Real || v.Deriv != 0 {
+			t.Errorf("%v: constant evaluated to %v", test.name, v)
+		}
+		const h = 1E-6
+		fd := (test.f(Const(test.x+h)).Real - test.f(Const(test.x-h)).Real) / (2 * h)
+		if math.Abs(d.Deriv-fd) > 1E-7*math.Max(1, math.Abs(fd)) {
+			t.Errorf("%v: derivative is %v, finite difference is %v", test.name, d.Deriv, fd)
+		}
+	}
+}
+
+func rosenbrock(x []Dual) (Dual, error) {
+	var sum Dual
+	for i := 0; i < len(x)-1; i++ {
+		a := Sub(Const(1), x[i])
+		b := Sub(x[i+1], Mul(x[i], x[i]))
+		sum = Add(sum, Add(Mul(a, a), Scale(100, Mul(b, b))))
+	}
+	return sum, nil
+}
+
+func TestMultiFunc(t *testing.T) {
+	f := MultiFunc(rosenbrock)
+	x := []float64{-1.2, 1, 0.5, 2}
+	obj, grad, err := f.ObjGrad(x)
+	if err != nil {
+		t.Fatalf("error evaluating: %v", err)
+	}
+	if o, _ := f.Objective(x); o != obj {
+		t.Errorf("objective mismatch between Objective and ObjGrad")
+	}
+	// The derivatives written out by hand
+	want := make([]float64, len(x))
+	for i := 0; i < len(x)-1; i++ {
+		want[i] += -2*(1-x[i]) - 400*(x[i+1]-x[i]*x[i])*x[i]
+		want[i+1] += 200 * (x[i+1] - x[i]*x[i])
+	}
+	for i := range want {
+		if math.Abs(grad[i]-want[i]) > 1E-12*math.Max(1, math.Abs(want[i])) {
+			t.Errorf("derivative %v is %v, expected %v", i, grad[i], want[i])
+		}
+	}
+
+	settings := multivariate.NewMultiGradSettings()
+	settings.Display = false
+	settings.GradientAbsoluteTolerance = 1E-10
+	obj, loc, result, err := multivariate.OptimizeGrad(f, []float64{-1.2, 1}, settings, nil)
+	if err != nil {
+		t.Fatalf("error optimizing: %v", err)
+	}
+	if result.Status != status.GradAbsTol {
+		t.Errorf("status is %v, expected GradAbsTol", result.Status)
+	}
+	for i := range loc {
+		if math.Abs(loc[i]-1) > 1E-5 {
+			t.Errorf("optimum %v at %v, expected 0 at ones", obj, loc)
+			break
+		}
+	}
+
+	errUser := errors.New("user error")
+	bad := MultiFunc(func(x []Dual) (Dual, error) { return Dual{}, errUser })
+	if _, _, err := bad.ObjGrad(x); err != errUser {
+		t.Errorf("error %v not returned", errUser)
+	}
+}
+
+func TestZero(t *testing.T) {
+	// A variable at zero which is not seeded must not make the derivatives
+	// with respect to the other variables NaN
+	for _, test := range []struct {
+		name string
+		f    func(x Dual) Dual
+	}{
+		{"Sqrt", Sqrt},
+		{"Log", Log},
+		{"Inv", Inv},
+		{"Div", func(x Dual) Dual { return Div(Const(1), x) }},
+	} {
+		f := MultiFunc(func(x []Dual) (Dual, error) {
+			return Add(x[0], test.f(x[1])), nil
+		})
+		_, grad, err := f.ObjGrad([]float64{1, 0})
+		if err != nil {
+			t.Fatalf("%v: error evaluating: %v", test.name, err)
+		}
+		if grad[0] != 1 {
+			t.Errorf("%v: derivative 0 is %v, expected 1", test.name, grad[0])
+		}
+	}
+}
+
+func TestUniFunc(t *testing.T) {
+	// exp(x) - 2x has its minimum at log(2)
+	f := UniFunc(func(x Dual) (Dual, error) {
+		return Sub(Exp(x), Scale(2, x)), nil
+	})
+	obj, grad, err := f.ObjGrad(1)
+	if err != nil {
+		t.Fatalf("error evaluating: %v", err)
+	}
+	if math.Abs(obj-(math.E-2)) > 1E-15 || math.Abs(grad-(math.E-2)) > 1E-15 {
+		t.Errorf("got %v and %v, expected %v for both", obj, grad, math.E-2)
+	}
+	if g, _ := f.Gradient(1); g != grad {
+		t.Errorf("gradient mismatch between Gradient and ObjGrad")
+	}
+
+	settings := univariate.NewBoundedSettings()
+	settings.Display = false
+	_, loc, _, err := univariate.OptimizeBounded(f, 0, 2, settings, univariate.NewBoundedBisection())
+	if err != nil {
+		t.Fatalf("error optimizing: %v", err)
+	}
+	if math.Abs(loc-math.Ln2) > 1E-6 {
+		t.Errorf("optimum at %v, expected %v", loc, math.Ln2)
+	}
+}
@@ -0,0 +1,216 @@
+// Package autodiff computes exact derivatives by forward mode automatic
+// differentiation with dual numbers. A function written once in terms of
+// Dual numbers gives both its value and its derivative, and MultiFunc and
+// UniFunc turn such functions into optimize.MultiObjGrad and
+// optimize.UniObjGrad.
+//
+// For example the Rosenbrock function is
+//
+//	f := autodiff.MultiFunc(func(x []autodiff.Dual) (autodiff.Dual, error) {
+//		var sum autodiff.Dual
+//		for i := 0; i < len(x)-1; i++ {
+//			a := autodiff.Sub(autodiff.Const(1), x[i])
+//			b := autodiff.Sub(x[i+1], autodiff.Mul(x[i], x[i]))
+//			sum = autodiff.Add(sum, autodiff.Add(autodiff.Mul(a, a), autodiff.Scale(100, autodiff.Mul(b, b))))
+//		}
+//		return sum, nil
+//	})
+package autodiff
+
+import (
+	"math"
+)
+
+// Dual is the dual number Real + Deriv ε, where ε^2 = 0. Evaluating a
+// function at x + ε gives f(x) + f'(x) ε, so the Deriv of the result is
+// the derivative of the function
+type Dual struct {
+	Real  float64
+	Deriv float64
+}
+
+// Const returns the dual number of a constant, whose derivative is zero
+func Const(v float64) Dual {
+	return Dual{Real: v}
+}
+
+// Variable returns the dual number of the variable with respect to which
+// the derivative is taken, whose derivative is one
+func Variable(v float64) Dual {
+	return Dual{Real: v, Deriv: 1}
+}
+
+func Add(a, b Dual) Dual {
+	return Dual{a.Real + b.Real, a.Deriv + b.Deriv}
+}
+
+func Sub(a, b Dual) Dual {
+	return Dual{a.Real - b.Real, a.Deriv - b.Deriv}
+}
+
+func Neg(a Dual) Dual {
+	return Dual{-a.Real, -a.Deriv}
+}
+
+func Mul(a, b Dual) Dual {
+	return Dual{a.Real * b.Real, a.Deriv*b.Real + a.Real*b.Deriv}
+}
+
+func Div(a, b Dual) Dual {
+	if a.Deriv == 0 && b.Deriv == 0 {
+		return Dual{a.Real / b.Real, 0}
+	}
+	return Dual{a.Real / b.Real, (a.Deriv*b.Real - a.Real*b.Deriv) / (b.Real * b.Real)}
+}
+
+// Scale returns c * a
+func Scale(c float64, a Dual) Dual {
+	return Dual{c * a.Real, c * a.Deriv}
+}
+
+// AddConst returns c + a
+func AddConst(c float64, a Dual) Dual {
+	return Dual{c + a.Real, a.Deriv}
+}
+
+// Inv returns 1 / a
+func Inv(a Dual) Dual {
+	if a.Deriv == 0 {
+		return Dual{1 / a.Real, 0}
+	}
+	return Dual{1 / a.Real, -a.Deriv / (a.Real * a.Real)}
+}
+
+// PowReal returns a^p for a constant power p
+func PowReal(a Dual, p float64) Dual {
+	switch p {
+	case 0:
+		return Dual{1, 0}
+	case 1:
+		return a
+	case 2:
+		return Mul(a, a)
+	}
+	if a.Deriv == 0 {
+		return Dual{math.Pow(a.Real, p), 0}
+	}
+	return Dual{math.Pow(a.Real, p), p * math.Pow(a.Real, p-1) * a.Deriv}
+}
+
+// Pow returns a^b. The base must be positive where the power depends on
+// the variable
+func Pow(a, b Dual) Dual {
+	if b.Deriv == 0 {
+		return PowReal(a, b.Real)
+	}
+	v := math.Pow(a.Real, b.Real)
+	d := v * b.Deriv * math.Log(a.Real)
+	if a.Deriv != 0 {
+		d += v * b.Real * a.Deriv / a.Real
+	}
+	return Dual{v, d}
+}
+
+// Sqrt returns the square root of a. As in the other functions which
+// divide by a, the derivative is zero rather than NaN where a is zero and
+// does not depend on the variable
+func Sqrt(a Dual) Dual {
+	v := math.Sqrt(a.Real)
+	if a.Deriv == 0 {
+		return Dual{v, 0}
+	}
+	return Dual{v, a.Deriv / (2 * v)}
+}
+
+func Exp(a Dual) Dual {
+	v := math.Exp(a.Real)
+	return Dual{v, v * a.Deriv}
+}
+
+func Log(a Dual) Dual {
+	if a.Deriv == 0 {
+		return Dual{math.Log(a.Real), 0}
+	}
+	return Dual{math.Log(a.Real), a.Deriv / a.Real}
+}
+
+func Sin(a Dual) Dual {
+	return Dual{math.Sin(a.Real), math.Cos(a.Real) * a.Deriv}
+}
+
+func Cos(a Dual) Dual {
+	return Dual{math.Cos(a.Real), -math.Sin(a.Real) * a.Deriv}
+}
+
+func Tan(a Dual) Dual {
+	v := math.Tan(a.Real)
+	return Dual{v, (1 + v*v) * a.Deriv}
+}
+
+func Atan(a Dual) Dual {
+	return Dual{math.Atan(a.Real), a.Deriv / (1 + a.Real*a.Real)}
+}
+
+func Sinh(a Dual) Dual {
+	return Dual{math.Sinh(a.Real), math.Cosh(a.Real) * a.Deriv}
+}
+
+func Cosh(a Dual) Dual {
+	return Dual{math.Cosh(a.Real), math.Sinh(a.Real) * a.Deriv}
+}
+
+func Tanh(a Dual) Dual {
+	v := math.Tanh(a.Real)
+	return Dual{v, (1 - v*v) * a.Deriv}
+}
+
+// Abs returns |a|. The derivative at zero is taken to be zero
+func Abs(a Dual) Dual {
+	switch {
+	case a.Real > 0:
+		return a
+	case a.Real < 0:
+		return Neg(a)
+	}
+	return Dual{math.Abs(a.Real), 0}
+}
+
+// Max returns the larger of a and b, with the derivative of a if they are
+// equal
+func Max(a, b Dual) Dual {
+	if b.Real > a.Real {
+		return b
+	}
+	return a
+}
+
+// Min returns the smaller of a and b, with the derivative of a if they are
+// equal
+func Min(a, b Dual) Dual {
+	if b.Real < a.Real {
+		return b
+	}
+	return a
+}
+
+// Sum returns the sum of the dual numbers
+func Sum(s []Dual) Dual {
+	var sum Dual
+	for _, v := range s {
+		sum.Real += v.Real
+		sum.Deriv += v.Deriv
+	}
+	return sum
+}
+
+// Dot returns the dot product of the dual numbers
+func Dot(s, t []Dual) Dual {
+	if len(s) != len(t) {
+		panic("autodiff: slice length mismatch")
+	}
+	var sum Dual
+	for i, v := range s {
+		sum = Add(sum, Mul(v, t[i]))
+	}
+	return sum
+}
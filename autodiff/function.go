@@ -0,0 +1,63 @@
+package autodiff
+
+import (
+	"math"
+)
+
+// MultiFunc is a multivariate function written in dual numbers. It
+// implements optimize.MultiObj and optimize.MultiObjGrad. ObjGrad finds
+// the exact gradient by evaluating the function once per dimension, each
+// time with the derivative seeded on one of the variables
+type MultiFunc func(x []Dual) (Dual, error)
+
+func (f MultiFunc) Objective(x []float64) (obj float64, err error) {
+	xd := make([]Dual, len(x))
+	for i, v := range x {
+		xd[i] = Const(v)
+	}
+	o, err := f(xd)
+	return o.Real, err
+}
+
+func (f MultiFunc) ObjGrad(x []float64) (obj float64, grad []float64, err error) {
+	if len(x) == 0 {
+		obj, err = f.Objective(x)
+		return obj, []float64{}, err
+	}
+	xd := make([]Dual, len(x))
+	for i, v := range x {
+		xd[i] = Const(v)
+	}
+	grad = make([]float64, len(x))
+	for i := range x {
+		xd[i].Deriv = 1
+		o, err := f(xd)
+		if err != nil {
+			return math.NaN(), nil, err
+		}
+		xd[i].Deriv = 0
+		obj = o.Real
+		grad[i] = o.Deriv
+	}
+	return obj, grad, nil
+}
+
+// UniFunc is a univariate function written in dual numbers. It implements
+// optimize.UniObj, optimize.UniGrad and optimize.UniObjGrad, with the
+// derivative found in the same evaluation as the value
+type UniFunc func(x Dual) (Dual, error)
+
+func (f UniFunc) Objective(x float64) (obj float64, err error) {
+	o, err := f(Const(x))
+	return o.Real, err
+}
+
+func (f UniFunc) Gradient(x float64) (grad float64, err error) {
+	o, err := f(Variable(x))
+	return o.Deriv, err
+}
+
+func (f UniFunc) ObjGrad(x float64) (obj float64, grad float64, err error) {
+	o, err := f(Variable(x))
+	return o.Real, o.Deriv, err
+}
@@ -0,0 +1,187 @@
+// Package benchmark compares optimizers over a library of test functions.
+// Every optimizer is run from every starting location of every problem,
+// and the runs are summarized with the performance profiles of Dolan and
+// Moré ("Benchmarking optimization software with performance profiles",
+// Math. Program. 91, 2002) and the data profiles of Moré and Wild
+// ("Benchmarking derivative-free optimization algorithms", SIAM J. Optim.
+// 20, 2009). All of the output is CSV
+package benchmark
+
+import (
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/multivariate"
+	"github.com/btracey/gofunopter/testfunctions"
+
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+// Solver is an optimizer to benchmark. New is called for every run, so
+// runs do not share state
+type Solver struct {
+	Name string
+	New  func() multivariate.MultiGradOptimizer
+}
+
+// Problem is a test function and the starting locations to run it from
+type Problem struct {
+	Function testfunctions.Function
+	Starts   [][]float64
+}
+
+// NewProblems returns a problem for each function, started from its
+// standard location and from nStarts-1 random perturbations of it. Each
+// coordinate is perturbed by up to scale times the larger of one and its
+// magnitude
+func NewProblems(funcs []testfunctions.Function, nStarts int, scale float64, rnd *rand.Rand) []Problem {
+	problems := make([]Problem, len(funcs))
+	for i, f := range funcs {
+		init := f.InitLoc()
+		problems[i].Function = f
+		problems[i].Starts = [][]float64{init}
+		for k := 1; k < nStarts; k++ {
+			x := make([]float64, len(init))
+			for j, v := range init {
+				x[j] = v + scale*(2*rnd.Float64()-1)*math.Max(1, math.Abs(v))
+			}
+			problems[i].Starts = append(problems[i].Starts, x)
+		}
+	}
+	return problems
+}
+
+// Record is the outcome of one run of a solver
+type Record struct {
+	Solver              string
+	Problem             string
+	Start               int
+	Dim                 int
+	Iterations          int
+	FunctionEvaluations int
+	Runtime             time.Duration
+	Status              status.Status
+	Err                 error
+	InitialObjective    float64
+	Objective           float64 // Best objective value found
+	OptVal              float64 // Known minimum of the test function
+
+	// EvaluationsToSolve is the number of function evaluations after which
+	// the run first met the convergence test (see Results), and zero if it
+	// never did
+	EvaluationsToSolve int
+
+	history []float64
+}
+
+// Solved returns true if the run met the convergence test
+func (r *Record) Solved() bool {
+	return r.EvaluationsToSolve > 0
+}
+
+// Results are the records of a benchmark. Records[i][j] is the run of
+// solver j from the ith starting location (in the order of the problems
+// and then of their starting locations). A run solves its problem when
+// it finds an objective value no larger than
+// fL + Tolerance * (f0 - fL), where f0 is the value at the starting
+// location and fL is the smallest value found by any of the solvers from
+// that starting location
+type Results struct {
+	Solvers   []string
+	Tolerance float64
+	Records   [][]*Record
+}
+
+// Benchmark runs every solver from every starting location of every
+// problem. The settings are shared by all of the runs, except that the
+// objective history is always kept (it is needed to find when a run
+// solved its problem) and the display is turned off
+func Benchmark(problems []Problem, solvers []Solver, settings *multivariate.MultiGradSettings, tolerance float64) *Results {
+	if settings == nil {
+		settings = multivariate.NewMultiGradSettings()
+	}
+	s := *settings
+	commonSettings := *settings.CommonSettings
+	displaySettings := *settings.DisplaySettings
+	displaySettings.Display = false
+	commonSettings.DisplaySettings = &displaySettings
+	s.CommonSettings = &commonSettings
+	objSettings := *settings.ObjectiveSettings
+	objSettings.KeepObjectiveHistory = true
+	s.ObjectiveSettings = &objSettings
+
+	results := &Results{Tolerance: tolerance}
+	for _, solver := range solvers {
+		results.Solvers = append(results.Solvers, solver.Name)
+	}
+	for _, p := range problems {
+		for k, start := range p.Starts {
+			records := make([]*Record, len(solvers))
+			for j, solver := range solvers {
+				records[j] = run(p.Function, k, start, solver, &s)
+			}
+			results.Records = append(results.Records, records)
+			setSolved(records, tolerance)
+		}
+	}
+	return results
+}
+
+func run(f testfunctions.Function, k int, start []float64, solver Solver, settings *multivariate.MultiGradSettings) *Record {
+	x := make([]float64, len(start))
+	copy(x, start)
+	obj, _, result, err := multivariate.OptimizeGrad(f, x, settings, solver.New())
+	r := &Record{
+		Solver:              solver.Name,
+		Problem:             f.Name(),
+		Start:               k,
+		Dim:                 len(start),
+		Iterations:          result.Iterations,
+		FunctionEvaluations: result.FunctionEvaluations,
+		Runtime:             result.Runtime,
+		Status:              result.Status,
+		Err:                 err,
+		InitialObjective:    math.NaN(),
+		Objective:           obj,
+		OptVal:              f.OptVal(),
+		history:             result.ObjectiveHistory,
+	}
+	if len(r.history) > 0 {
+		r.InitialObjective = r.history[0]
+	}
+	for _, v := range r.history {
+		if v < r.Objective || math.IsNaN(r.Objective) {
+			r.Objective = v
+		}
+	}
+	return r
+}
+
+// setSolved applies the convergence test to the runs from one starting
+// location
+func setSolved(records []*Record, tolerance float64) {
+	fL := math.Inf(1)
+	for _, r := range records {
+		if r.Objective < fL {
+			fL = r.Objective
+		}
+	}
+	for _, r := range records {
+		r.EvaluationsToSolve = 0
+		if len(r.history) == 0 {
+			continue
+		}
+		threshold := fL + tolerance*(r.history[0]-fL)
+		for i, v := range r.history {
+			if v <= threshold {
+				r.EvaluationsToSolve = i + 1
+				break
+			}
+		}
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,105 @@
+package benchmark
+
+import (
+	"github.com/btracey/gofunopter/multivariate"
+	"github.com/btracey/gofunopter/testfunctions"
+
+	"bytes"
+	"encoding/csv"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func testSolvers() []Solver {
+	return []Solver{
+		{Name: "Lbfgs", New: func() multivariate.MultiGradOptimizer { return multivariate.NewLbfgs() }},
+		{Name: "CoordinateDescent", New: func() multivariate.MultiGradOptimizer { return multivariate.NewCoordinateDescent() }},
+	}
+}
+
+func readCSV(t *testing.T, b *bytes.Buffer) [][]string {
+	rows, err := csv.NewReader(b).ReadAll()
+	if err != nil {
+		t.Fatalf("error reading csv: %v", err)
+	}
+	return rows
+}
+
+// checkProfile checks that a profile is nondecreasing with fractions
+// between zero and one
+func checkProfile(t *testing.T, name string, rows [][]string, nSolvers int) {
+	if len(rows) < 2 || len(rows[0]) != nSolvers+1 {
+		t.Fatalf("%v: wrong shape", name)
+	}
+	prev := make([]float64, nSolvers+1)
+	for _, row := range rows[1:] {
+		for j, s := range row {
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				t.Fatalf("%v: bad value %v", name, s)
+			}
+			if v < prev[j] || (j > 0 && v > 1) {
+				t.Errorf("%v: column %v not a nondecreasing fraction: %v after %v", name, j, v, prev[j])
+			}
+			prev[j] = v
+		}
+	}
+}
+
+func TestBenchmark(t *testing.T) {
+	funcs := []testfunctions.Function{
+		testfunctions.ExtendedRosenbrock{Dim: 2},
+		testfunctions.Beale{},
+		testfunctions.Wood{},
+	}
+	problems := NewProblems(funcs, 2, 0.1, rand.New(rand.NewSource(1)))
+	settings := multivariate.NewMultiGradSettings()
+	settings.MaximumFunctionEvaluations = 2000
+	settings.Display = true // turned off by Benchmark
+	results := Benchmark(problems, testSolvers(), settings, 1E-5)
+	if !settings.Display || settings.KeepObjectiveHistory {
+		t.Errorf("settings modified")
+	}
+	if len(results.Records) != 6 {
+		t.Fatalf("%v rows of records, expected 6", len(results.Records))
+	}
+	for _, records := range results.Records {
+		var solved bool
+		for _, r := range records {
+			if r.Solved() {
+				solved = true
+				if r.EvaluationsToSolve > r.FunctionEvaluations {
+					t.Errorf("%v %v: solved after %v of %v evaluations", r.Solver, r.Problem, r.EvaluationsToSolve, r.FunctionEvaluations)
+				}
+			}
+		}
+		if !solved {
+			t.Errorf("%v start %v: solved by no solver", records[0].Problem, records[0].Start)
+		}
+	}
+
+	var b bytes.Buffer
+	if err := results.WriteRecords(&b); err != nil {
+		t.Fatalf("error writing records: %v", err)
+	}
+	if rows := readCSV(t, &b); len(rows) != 13 {
+		t.Errorf("%v rows in the records table, expected 13", len(rows))
+	}
+	for _, m := range []Measure{FunctionEvaluations, Iterations, Runtime} {
+		b.Reset()
+		if err := results.PerformanceProfile(&b, m); err != nil {
+			t.Fatalf("error writing performance profile: %v", err)
+		}
+		rows := readCSV(t, &b)
+		checkProfile(t, "performance profile", rows, 2)
+		if rows[1][0] != "1" {
+			t.Errorf("performance profile starts at tau = %v", rows[1][0])
+		}
+	}
+	b.Reset()
+	if err := results.DataProfile(&b); err != nil {
+		t.Fatalf("error writing data profile: %v", err)
+	}
+	checkProfile(t, "data profile", readCSV(t, &b), 2)
+}
@@ -0,0 +1,155 @@
+package benchmark
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Measure is the cost of a run used in a performance profile
+type Measure int
+
+const (
+	// FunctionEvaluations is the number of function evaluations until
+	// the run met the convergence test
+	FunctionEvaluations Measure = iota
+	// Iterations is the number of iterations of a run which met the
+	// convergence test
+	Iterations
+	// Runtime is the runtime of a run which met the convergence test
+	Runtime
+)
+
+// cost returns the cost of a run, which is infinite if the run did not
+// solve its problem
+func (r *Record) cost(m Measure) float64 {
+	if !r.Solved() {
+		return math.Inf(1)
+	}
+	switch m {
+	case Iterations:
+		return float64(r.Iterations)
+	case Runtime:
+		return r.Runtime.Seconds()
+	default:
+		return float64(r.EvaluationsToSolve)
+	}
+}
+
+// WriteRecords writes every run as a row of a CSV table
+func (r *Results) WriteRecords(w io.Writer) error {
+	c := csv.NewWriter(w)
+	c.Write([]string{"solver", "problem", "start", "dim", "iterations", "evaluations", "runtime", "status", "error",
+		"initial", "objective", "optval", "solved", "evaluations_to_solve"})
+	for _, records := range r.Records {
+		for _, rec := range records {
+			var errString string
+			if rec.Err != nil {
+				errString = rec.Err.Error()
+			}
+			c.Write([]string{
+				rec.Solver,
+				rec.Problem,
+				strconv.Itoa(rec.Start),
+				strconv.Itoa(rec.Dim),
+				strconv.Itoa(rec.Iterations),
+				strconv.Itoa(rec.FunctionEvaluations),
+				formatFloat(rec.Runtime.Seconds()),
+				strconv.Itoa(int(rec.Status)),
+				errString,
+				formatFloat(rec.InitialObjective),
+				formatFloat(rec.Objective),
+				formatFloat(rec.OptVal),
+				strconv.FormatBool(rec.Solved()),
+				strconv.Itoa(rec.EvaluationsToSolve),
+			})
+		}
+	}
+	c.Flush()
+	return c.Error()
+}
+
+// writeProfile writes the fraction of problems for which each solver's
+// value is at most each breakpoint, where values[i][j] is the value of
+// solver j on problem i
+func (r *Results) writeProfile(w io.Writer, heading string, values [][]float64) error {
+	var breaks []float64
+	for _, row := range values {
+		for _, v := range row {
+			if !math.IsInf(v, 1) && !math.IsNaN(v) {
+				breaks = append(breaks, v)
+			}
+		}
+	}
+	sort.Float64s(breaks)
+
+	c := csv.NewWriter(w)
+	c.Write(append([]string{heading}, r.Solvers...))
+	row := make([]string, len(r.Solvers)+1)
+	for i, b := range breaks {
+		if i > 0 && b == breaks[i-1] {
+			continue
+		}
+		row[0] = formatFloat(b)
+		for j := range r.Solvers {
+			var n int
+			for _, v := range values {
+				if v[j] <= b {
+					n++
+				}
+			}
+			row[j+1] = formatFloat(float64(n) / float64(len(values)))
+		}
+		c.Write(row)
+	}
+	c.Flush()
+	return c.Error()
+}
+
+// PerformanceProfile writes the performance profile of the solvers with
+// the cost m. The performance ratio of a solver on a problem is its cost
+// divided by the smallest cost of any solver, and each row gives the
+// fraction of problems on which each solver has a ratio of at most tau.
+// There is a row for every distinct ratio, and the fractions at larger
+// values of tau are those of the last row
+func (r *Results) PerformanceProfile(w io.Writer, m Measure) error {
+	ratios := make([][]float64, len(r.Records))
+	for i, records := range r.Records {
+		best := math.Inf(1)
+		for _, rec := range records {
+			best = math.Min(best, rec.cost(m))
+		}
+		ratios[i] = make([]float64, len(records))
+		for j, rec := range records {
+			cost := rec.cost(m)
+			switch {
+			case math.IsInf(cost, 1):
+				ratios[i][j] = cost
+			case cost == best:
+				// Handles zero runtimes
+				ratios[i][j] = 1
+			default:
+				ratios[i][j] = cost / best
+			}
+		}
+	}
+	return r.writeProfile(w, "tau", ratios)
+}
+
+// DataProfile writes the data profile of the solvers. Each row gives the
+// fraction of problems which each solver solves within a budget of
+// alpha simplex gradients, that is alpha * (n + 1) function evaluations
+// for a problem of dimension n. There is a row for every distinct budget
+// at which a problem is solved
+func (r *Results) DataProfile(w io.Writer) error {
+	budgets := make([][]float64, len(r.Records))
+	for i, records := range r.Records {
+		budgets[i] = make([]float64, len(records))
+		for j, rec := range records {
+			budgets[i][j] = rec.cost(FunctionEvaluations) / float64(rec.Dim+1)
+		}
+	}
+	return r.writeProfile(w, "alpha", budgets)
+}
@@ -0,0 +1,74 @@
+// Command gofunbench runs the gradient-based optimizers over the
+// Moré-Garbow-Hillstrom test functions and writes the records of the runs,
+// the performance profiles and the data profile as CSV files
+package main
+
+import (
+	"github.com/btracey/gofunopter/benchmark"
+	"github.com/btracey/gofunopter/multivariate"
+	"github.com/btracey/gofunopter/testfunctions"
+
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	out := flag.String("out", ".", "directory for the csv files")
+	dim := flag.Int("dim", 8, "dimension of the variable dimension problems (a multiple of 4)")
+	starts := flag.Int("starts", 5, "starting locations per problem")
+	scale := flag.Float64("scale", 0.5, "relative size of the random perturbations of the starting locations")
+	tol := flag.Float64("tol", 1E-5, "tolerance of the convergence test")
+	maxEvals := flag.Int("maxevals", 10000, "maximum function evaluations per run")
+	gradTol := flag.Float64("gradtol", 1E-8, "gradient absolute tolerance")
+	seed := flag.Int64("seed", 1, "seed for the starting locations")
+	flag.Parse()
+	if *dim <= 0 || *dim%4 != 0 {
+		fmt.Fprintln(os.Stderr, "gofunbench: dim must be a positive multiple of 4")
+		os.Exit(2)
+	}
+
+	solvers := []benchmark.Solver{
+		{Name: "Lbfgs", New: func() multivariate.MultiGradOptimizer { return multivariate.NewLbfgs() }},
+		{Name: "Owlqn", New: func() multivariate.MultiGradOptimizer { return multivariate.NewOwlqn(0) }},
+		{Name: "CoordinateDescent", New: func() multivariate.MultiGradOptimizer { return multivariate.NewCoordinateDescent() }},
+	}
+	problems := benchmark.NewProblems(testfunctions.MoreGarbowHillstrom(*dim), *starts, *scale, rand.New(rand.NewSource(*seed)))
+
+	settings := multivariate.NewMultiGradSettings()
+	settings.MaximumFunctionEvaluations = *maxEvals
+	settings.GradientAbsoluteTolerance = *gradTol
+	results := benchmark.Benchmark(problems, solvers, settings, *tol)
+
+	files := []struct {
+		name  string
+		write func(io.Writer) error
+	}{
+		{"runs.csv", results.WriteRecords},
+		{"perf_evaluations.csv", func(w io.Writer) error { return results.PerformanceProfile(w, benchmark.FunctionEvaluations) }},
+		{"perf_iterations.csv", func(w io.Writer) error { return results.PerformanceProfile(w, benchmark.Iterations) }},
+		{"perf_runtime.csv", func(w io.Writer) error { return results.PerformanceProfile(w, benchmark.Runtime) }},
+		{"data.csv", results.DataProfile},
+	}
+	for _, file := range files {
+		if err := writeFile(filepath.Join(*out, file.name), file.write); err != nil {
+			fmt.Fprintln(os.Stderr, "gofunbench:", err)
+			os.Exit(1)
+		}
+	}
+}
+
+func writeFile(name string, write func(io.Writer) error) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,121 @@
+// Command gofunopter minimizes a function described by a spec file and
+// prints the iteration table and the result.
+//
+// The spec file is JSON, or YAML if its name ends in .yaml or .yml. For
+// example
+//
+//	function: ExtendedRosenbrock
+//	dim: 4
+//	optimizer: Lbfgs
+//	settings:
+//	  MaximumFunctionEvaluations: 1000
+//	  GradientAbsoluteTolerance: 1e-10
+//
+// The fields are those of Spec, and the settings are the fields of
+// multivariate.MultiGradSettings. The starting location defaults to the
+// standard one of the test function.
+//
+// Instead of a test function, the function may be given as an expression
+// in the variables x0, x1, ..., whose gradient is found by symbolic
+// differentiation (see package expr)
+//
+//	expression: (1-x0)^2 + 100*(x1-x0^2)^2
+//	start: [-1.2, 1]
+package main
+
+import (
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/multivariate"
+
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+var statusNames = map[status.Status]string{
+	status.Continue:                    "Continue",
+	status.GradAbsTol:                  "GradAbsTol",
+	status.GradRelTol:                  "GradRelTol",
+	status.ObjAbsTol:                   "ObjAbsTol",
+	status.ObjRelTol:                   "ObjRelTol",
+	status.StepAbsTol:                  "StepAbsTol",
+	status.StepRelTol:                  "StepRelTol",
+	status.WolfeConditionsMet:          "WolfeConditionsMet",
+	status.ProjGradAbsTol:              "ProjGradAbsTol",
+	status.ObjRangeTol:                 "ObjRangeTol",
+	status.StagnationTol:               "StagnationTol",
+	status.BoundaryOptimum:             "BoundaryOptimum",
+	status.UserFunctionError:           "UserFunctionError",
+	status.OptimizerError:              "OptimizerError",
+	status.Infeasible:                  "Infeasible",
+	status.MaximumIterations:           "MaximumIterations",
+	status.MaximumFunctionEvaluations:  "MaximumFunctionEvaluations",
+	status.MaximumRuntime:              "MaximumRuntime",
+	status.LinesearchFailure:           "LinesearchFailure",
+	status.MaximumEpochs:               "MaximumEpochs",
+	status.MaximumComponentEvaluations: "MaximumComponentEvaluations",
+	status.IllConditioned:              "IllConditioned",
+}
+
+func statusName(s status.Status) string {
+	if name, ok := statusNames[s]; ok {
+		return name
+	}
+	return fmt.Sprint(int(s))
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: gofunopter spec.(json|yaml)")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	name := flag.Arg(0)
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gofunopter:", err)
+		os.Exit(1)
+	}
+	if err := run(os.Stdout, name, data); err != nil {
+		fmt.Fprintln(os.Stderr, "gofunopter:", err)
+		os.Exit(1)
+	}
+}
+
+// run optimizes the problem in the spec file, and writes the result to w.
+// The iteration table is written to standard output by the optimizer
+func run(w io.Writer, name string, data []byte) error {
+	spec, err := parseSpec(name, data)
+	if err != nil {
+		return err
+	}
+	f, fName, start, err := spec.function()
+	if err != nil {
+		return err
+	}
+	optimizer, err := spec.optimizer()
+	if err != nil {
+		return err
+	}
+	settings, err := spec.settings()
+	if err != nil {
+		return err
+	}
+
+	obj, loc, result, err := multivariate.OptimizeGrad(f, start, settings, optimizer)
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Function:             %v\n", fName)
+	fmt.Fprintf(w, "Status:               %v\n", statusName(result.Status))
+	fmt.Fprintf(w, "Objective:            %v\n", obj)
+	fmt.Fprintf(w, "Location:             %v\n", loc)
+	fmt.Fprintf(w, "Iterations:           %v\n", result.Iterations)
+	fmt.Fprintf(w, "Function evaluations: %v\n", result.FunctionEvaluations)
+	fmt.Fprintf(w, "Runtime:              %v\n", result.Runtime)
+	return err
+}
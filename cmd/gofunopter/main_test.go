@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	data := `
+# a comment
+name: "quoted # not a comment"
+other: 'it''s'   # a comment
+number: -1.5e-3
+flag: true
+empty:
+list: [1, 2.5, three]
+block:
+  - 1
+  - x
+nested:
+  a: 1
+  b:
+    c: null
+items:
+- k: 1
+  v: 2
+- 3
+`
+	v, err := parseYAML([]byte(data))
+	if err != nil {
+		t.Fatalf("error parsing: %v", err)
+	}
+	want := map[string]interface{}{
+		"name":   "quoted # not a comment",
+		"other":  "it's",
+		"number": -1.5e-3,
+		"flag":   true,
+		"empty":  nil,
+		"list":   []interface{}{1.0, 2.5, "three"},
+		"block":  []interface{}{1.0, "x"},
+		"nested": map[string]interface{}{
+			"a": 1.0,
+			"b": map[string]interface{}{"c": nil},
+		},
+		"items": []interface{}{
+			map[string]interface{}{"k": 1.0, "v": 2.0},
+			3.0,
+		},
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("parsed\n%#v\nexpected\n%#v", v, want)
+	}
+
+	for _, bad := range []string{
+		"a: 1\n  b: 2\n",
+		"a: 1\na: 2\n",
+		"a: {b: 1}\n",
+		"just text\n",
+	} {
+		if _, err := parseYAML([]byte(bad)); err == nil {
+			t.Errorf("no error parsing %q", bad)
+		}
+	}
+}
+
+func TestParseSpec(t *testing.T) {
+	yamlSpec := `
+function: Wood
+start: [-3, -1, -3, -1]
+optimizer: lbfgs
+settings:
+  Display: false
+  MaximumFunctionEvaluations: 500
+  GradientAbsoluteTolerance: 1e-10
+`
+	jsonSpec := `{
+	"function": "Wood",
+	"start": [-3, -1, -3, -1],
+	"optimizer": "lbfgs",
+	"settings": {"Display": false, "MaximumFunctionEvaluations": 500, "GradientAbsoluteTolerance": 1e-10}
+}`
+	for _, test := range []struct {
+		name string
+		data string
+	}{
+		{"spec.yaml", yamlSpec},
+		{"spec.json", jsonSpec},
+	} {
+		spec, err := parseSpec(test.name, []byte(test.data))
+		if err != nil {
+			t.Errorf("%v: error parsing: %v", test.name, err)
+			continue
+		}
+		settings, err := spec.settings()
+		if err != nil {
+			t.Errorf("%v: error in settings: %v", test.name, err)
+			continue
+		}
+		if settings.Display || settings.MaximumFunctionEvaluations != 500 || settings.GradientAbsoluteTolerance != 1E-10 {
+			t.Errorf("%v: settings not set", test.name)
+		}
+		if !settings.DisplayObjective {
+			t.Errorf("%v: default setting lost", test.name)
+		}
+		var b bytes.Buffer
+		if err := run(&b, test.name, []byte(test.data)); err != nil {
+			t.Errorf("%v: error running: %v", test.name, err)
+		}
+		if !strings.Contains(b.String(), "Status:               GradAbsTol") {
+			t.Errorf("%v: unexpected output\n%v", test.name, b.String())
+		}
+	}
+
+	for _, bad := range []string{
+		`{"function": "NoSuchFunction", "dim": 2}`,
+		`{"function": "Wood", "optimizer": "NoSuchOptimizer"}`,
+		`{"function": "Wood", "settings": {"NoSuchSetting": 1}}`,
+		`{"function": "Trigonometric"}`,
+		`{"function": "ExtendedRosenbrock", "dim": 2, "start": [-1.2, 1, -1.2, 1]}`,
+		`{"function": "Beale", "dim": 3}`,
+		`{"function": "Wood", "start": [-3, -1]}`,
+	} {
+		if err := run(&bytes.Buffer{}, "spec.json", []byte(bad)); err == nil {
+			t.Errorf("no error running %v", bad)
+		}
+	}
+}
+
+func TestExpressionSpec(t *testing.T) {
+	spec := `
+expression: (1-x0)^2 + 100*(x1-x0^2)^2
+start: [-1.2, 1]
+settings:
+  Display: false
+  GradientAbsoluteTolerance: 1e-10
+`
+	var b bytes.Buffer
+	if err := run(&b, "spec.yaml", []byte(spec)); err != nil {
+		t.Fatalf("error running: %v", err)
+	}
+	for _, want := range []string{
+		"Function:             (1-x0)^2 + 100*(x1-x0^2)^2",
+		"Status:               GradAbsTol",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("output does not contain %q\n%v", want, b.String())
+		}
+	}
+
+	for _, bad := range []string{
+		`{"expression": "(1-x0)^2 +"}`,
+		`{"expression": "x0^2", "function": "Wood"}`,
+		`{"expression": "x0 + x2", "start": [1, 2]}`,
+		`{"expression": "2"}`,
+		`{"expression": "x0^2", "dim": 2, "start": [1]}`,
+	} {
+		if err := run(&bytes.Buffer{}, "spec.json", []byte(bad)); err == nil {
+			t.Errorf("no error running %v", bad)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/expr"
+	"github.com/btracey/gofunopter/multivariate"
+	"github.com/btracey/gofunopter/testfunctions"
+
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Spec is the description of a problem read from the spec file
+type Spec struct {
+	Function   string    // Name of the test function
+	Expression string    // Expression of the function in the variables x0, x1, ..., used instead of a test function
+	Dim        int       // Dimension of the problem, which must agree with that of fixed dimension test functions
+	Start      []float64 // Starting location, defaults to the standard one of the test function, or to zero for expressions
+	Optimizer  string    // Lbfgs, Owlqn or CoordinateDescent, defaults to Lbfgs
+	L1Weight   float64   // Weight of the L1 penalty for Owlqn
+
+	// Settings are the fields of multivariate.MultiGradSettings. Fields
+	// which are not given keep their defaults
+	Settings json.RawMessage
+}
+
+// testFunctions are the test functions by name
+var testFunctions = map[string]func(dim int) testfunctions.Function{
+	"ExtendedRosenbrock":     func(n int) testfunctions.Function { return testfunctions.ExtendedRosenbrock{Dim: n} },
+	"FreudensteinRoth":       func(n int) testfunctions.Function { return testfunctions.FreudensteinRoth{} },
+	"PowellBadlyScaled":      func(n int) testfunctions.Function { return testfunctions.PowellBadlyScaled{} },
+	"BrownBadlyScaled":       func(n int) testfunctions.Function { return testfunctions.BrownBadlyScaled{} },
+	"Beale":                  func(n int) testfunctions.Function { return testfunctions.Beale{} },
+	"HelicalValley":          func(n int) testfunctions.Function { return testfunctions.HelicalValley{} },
+	"Box3D":                  func(n int) testfunctions.Function { return testfunctions.Box3D{} },
+	"PowellSingular":         func(n int) testfunctions.Function { return testfunctions.PowellSingular{} },
+	"ExtendedPowellSingular": func(n int) testfunctions.Function { return testfunctions.ExtendedPowellSingular{Dim: n} },
+	"Wood":                   func(n int) testfunctions.Function { return testfunctions.Wood{} },
+	"VariablyDimensioned":    func(n int) testfunctions.Function { return testfunctions.VariablyDimensioned{Dim: n} },
+	"Trigonometric":          func(n int) testfunctions.Function { return testfunctions.Trigonometric{Dim: n} },
+	"Rastrigin":              func(n int) testfunctions.Function { return testfunctions.Rastrigin{Dim: n} },
+	"Ackley":                 func(n int) testfunctions.Function { return testfunctions.Ackley{Dim: n} },
+	"Griewank":               func(n int) testfunctions.Function { return testfunctions.Griewank{Dim: n} },
+}
+
+// parseSpec parses a spec from JSON, or from YAML if the name of the file
+// ends in .yaml or .yml
+func parseSpec(name string, data []byte) (*Spec, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		v, err := parseYAML(data)
+		if err != nil {
+			return nil, err
+		}
+		data, err = json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	spec := &Spec{}
+	if err := dec.Decode(spec); err != nil {
+		return nil, errors.New("spec: " + err.Error())
+	}
+	return spec, nil
+}
+
+// function returns the function, its name and the starting location
+func (s *Spec) function() (optimize.MultiObjGrad, string, []float64, error) {
+	if s.Dim != 0 && s.Start != nil && len(s.Start) != s.Dim {
+		return nil, "", nil, fmt.Errorf("spec: start has length %v, dim is %v", len(s.Start), s.Dim)
+	}
+	if s.Expression != "" {
+		if s.Function != "" {
+			return nil, "", nil, errors.New("spec: only one of function and expression may be given")
+		}
+		return s.expression()
+	}
+	newFunction, ok := testFunctions[s.Function]
+	if !ok {
+		return nil, "", nil, fmt.Errorf("spec: unknown function %q", s.Function)
+	}
+	dim := s.Dim
+	if dim == 0 {
+		dim = len(s.Start)
+	}
+	f := newFunction(dim)
+	// Functions of a fixed dimension ignore dim, so it is checked against
+	// the length of their standard starting location
+	if n := len(f.InitLoc()); dim != 0 && n != dim {
+		return nil, "", nil, fmt.Errorf("spec: %v has dimension %v, %v given", s.Function, n, dim)
+	}
+	start := s.Start
+	if start == nil {
+		start = f.InitLoc()
+	}
+	if len(start) == 0 {
+		return nil, "", nil, errors.New("spec: dim or start must be given for " + s.Function)
+	}
+	return f, f.Name(), start, nil
+}
+
+// expression returns the function given by the expression. The dimension
+// is that of the starting location if given, and otherwise Dim or the
+// number of variables in the expression
+func (s *Spec) expression() (optimize.MultiObjGrad, string, []float64, error) {
+	f, err := expr.NewFunction(s.Expression)
+	if err != nil {
+		return nil, "", nil, errors.New("spec: " + err.Error())
+	}
+	start := s.Start
+	if start == nil {
+		dim := s.Dim
+		if dim == 0 {
+			dim = f.Dim()
+		}
+		start = make([]float64, dim)
+	}
+	if len(start) == 0 {
+		return nil, "", nil, errors.New("spec: dim or start must be given for a constant expression")
+	}
+	if len(start) < f.Dim() {
+		return nil, "", nil, fmt.Errorf("spec: expression has %v variables, start has length %v", f.Dim(), len(start))
+	}
+	return f, s.Expression, start, nil
+}
+
+func (s *Spec) optimizer() (multivariate.MultiGradOptimizer, error) {
+	switch strings.ToLower(s.Optimizer) {
+	case "", "lbfgs":
+		return multivariate.NewLbfgs(), nil
+	case "owlqn":
+		return multivariate.NewOwlqn(s.L1Weight), nil
+	case "coordinatedescent":
+		return multivariate.NewCoordinateDescent(), nil
+	}
+	return nil, fmt.Errorf("spec: unknown optimizer %q", s.Optimizer)
+}
+
+func (s *Spec) settings() (*multivariate.MultiGradSettings, error) {
+	settings := multivariate.NewMultiGradSettings()
+	if len(s.Settings) == 0 {
+		return settings, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(s.Settings))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(settings); err != nil {
+		return nil, errors.New("spec: settings: " + err.Error())
+	}
+	return settings, nil
+}
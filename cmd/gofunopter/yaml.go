@@ -0,0 +1,222 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a line of a YAML file without its indentation and comment
+type yamlLine struct {
+	num    int
+	indent int
+	text   string
+}
+
+// parseYAML parses the subset of YAML needed for spec files, so that no
+// external package is needed. It supports block mappings and block
+// sequences nested by indentation, flow sequences of scalars ([1, 2]),
+// comments, and scalars which are numbers, booleans, null, or plain or
+// quoted strings. Mappings are returned as map[string]interface{} and
+// sequences as []interface{}, ready to be converted to JSON
+func parseYAML(data []byte) (interface{}, error) {
+	var lines []yamlLine
+	for i, s := range strings.Split(string(data), "\n") {
+		s = strings.TrimRight(stripComment(s), " \t\r")
+		trimmed := strings.TrimLeft(s, " ")
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("yaml: line %v: tabs are not allowed for indentation", i+1)
+		}
+		lines = append(lines, yamlLine{num: i + 1, indent: len(s) - len(trimmed), text: trimmed})
+	}
+	if len(lines) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	v, rest, err := parseYAMLBlock(lines, lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("yaml: line %v: unexpected indentation", rest[0].num)
+	}
+	return v, nil
+}
+
+// stripComment removes a comment which is not inside quotes
+func stripComment(s string) string {
+	var quote rune
+	for i, c := range s {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitKey splits a mapping entry into its key and value
+func splitKey(text string) (key, value string, ok bool) {
+	i := strings.Index(text, ": ")
+	if i < 0 {
+		if !strings.HasSuffix(text, ":") {
+			return "", "", false
+		}
+		i = len(text) - 1
+	}
+	key = strings.TrimSpace(text[:i])
+	if k, err := parseYAMLScalar(key); err == nil {
+		if s, isString := k.(string); isString {
+			key = s
+		}
+	}
+	return key, strings.TrimSpace(text[i+1:]), true
+}
+
+// parseYAMLBlock parses the mapping or sequence whose entries start at
+// the given indentation, and returns the lines after it
+func parseYAMLBlock(lines []yamlLine, indent int) (interface{}, []yamlLine, error) {
+	if isSequenceItem(lines[0].text) {
+		return parseYAMLSequence(lines, indent)
+	}
+	return parseYAMLMapping(lines, indent)
+}
+
+func parseYAMLMapping(lines []yamlLine, indent int) (interface{}, []yamlLine, error) {
+	m := make(map[string]interface{})
+	for len(lines) > 0 && lines[0].indent == indent && !isSequenceItem(lines[0].text) {
+		l := lines[0]
+		lines = lines[1:]
+		key, value, ok := splitKey(l.text)
+		if !ok {
+			return nil, nil, fmt.Errorf("yaml: line %v: expected key: value", l.num)
+		}
+		if _, dup := m[key]; dup {
+			return nil, nil, fmt.Errorf("yaml: line %v: duplicate key %q", l.num, key)
+		}
+		var v interface{}
+		var err error
+		switch {
+		case value != "":
+			v, err = parseYAMLValue(value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("yaml: line %v: %v", l.num, err)
+			}
+		case len(lines) > 0 && lines[0].indent > indent:
+			v, lines, err = parseYAMLBlock(lines, lines[0].indent)
+		case len(lines) > 0 && lines[0].indent == indent && isSequenceItem(lines[0].text):
+			// A sequence may have the same indentation as its key
+			v, lines, err = parseYAMLSequence(lines, indent)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		m[key] = v
+	}
+	if len(lines) > 0 && lines[0].indent > indent {
+		return nil, nil, fmt.Errorf("yaml: line %v: unexpected indentation", lines[0].num)
+	}
+	return m, lines, nil
+}
+
+func parseYAMLSequence(lines []yamlLine, indent int) (interface{}, []yamlLine, error) {
+	s := []interface{}{}
+	for len(lines) > 0 && lines[0].indent == indent && isSequenceItem(lines[0].text) {
+		l := lines[0]
+		lines = lines[1:]
+		item := strings.TrimSpace(l.text[1:])
+		var v interface{}
+		var err error
+		switch {
+		case item == "":
+			if len(lines) > 0 && lines[0].indent > indent {
+				v, lines, err = parseYAMLBlock(lines, lines[0].indent)
+			}
+		case strings.HasSuffix(item, ":") || strings.Contains(item, ": "):
+			// A mapping which starts on the line of the item
+			first := yamlLine{num: l.num, indent: indent + len(l.text) - len(item), text: item}
+			v, lines, err = parseYAMLMapping(append([]yamlLine{first}, lines...), first.indent)
+		default:
+			v, err = parseYAMLValue(item)
+			if err != nil {
+				err = fmt.Errorf("yaml: line %v: %v", l.num, err)
+			}
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		s = append(s, v)
+	}
+	return s, lines, nil
+}
+
+// parseYAMLValue parses a flow sequence or a scalar
+func parseYAMLValue(text string) (interface{}, error) {
+	if strings.HasPrefix(text, "{") {
+		return nil, errors.New("flow mappings are not supported")
+	}
+	if !strings.HasPrefix(text, "[") {
+		return parseYAMLScalar(text)
+	}
+	if !strings.HasSuffix(text, "]") {
+		return nil, errors.New("unterminated flow sequence")
+	}
+	s := []interface{}{}
+	inner := strings.TrimSpace(text[1 : len(text)-1])
+	if inner == "" {
+		return s, nil
+	}
+	for _, item := range strings.Split(inner, ",") {
+		item = strings.TrimSpace(item)
+		if strings.HasPrefix(item, "[") {
+			return nil, errors.New("nested flow sequences are not supported")
+		}
+		v, err := parseYAMLScalar(item)
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, v)
+	}
+	return s, nil
+}
+
+func parseYAMLScalar(text string) (interface{}, error) {
+	switch text {
+	case "null", "Null", "NULL", "~", "":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	switch text[0] {
+	case '"':
+		s, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, errors.New("bad double quoted string " + text)
+		}
+		return s, nil
+	case '\'':
+		if len(text) < 2 || text[len(text)-1] != '\'' {
+			return nil, errors.New("bad single quoted string " + text)
+		}
+		return strings.Replace(text[1:len(text)-1], "''", "'", -1), nil
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil {
+		return f, nil
+	}
+	return text, nil
+}
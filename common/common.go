@@ -135,7 +135,7 @@ func NewIterations() *Iterations {
 }
 
 func (i *Iterations) Initialize() error {
-	i.Incrementor.curr = 0
+	i.setCurr(0)
 	return nil
 }
 
@@ -155,7 +155,7 @@ func NewFunctionEvaluations() *FunctionEvaluations {
 }
 
 func (f *FunctionEvaluations) Initialize() error {
-	f.Incrementor.curr = 0
+	f.setCurr(0)
 	return nil
 }
 
@@ -0,0 +1,46 @@
+package common
+
+import (
+	"github.com/btracey/gofunopter/common/status"
+	"math"
+)
+
+// ComponentEvaluations counts the number of evaluations of individual
+// components of a finite sum function. Evaluations of the full sum are
+// counted by FunctionEvaluations
+type ComponentEvaluations struct {
+	*Incrementor
+}
+
+func NewComponentEvaluations() *ComponentEvaluations {
+	return &ComponentEvaluations{
+		Incrementor: NewIncrementor("CompEval", math.MaxInt32-1, status.MaximumComponentEvaluations, true),
+	}
+}
+
+func (c *ComponentEvaluations) Initialize() error {
+	c.setCurr(0)
+	return nil
+}
+
+// ComponentSettings is a list of settings for the component evaluations
+// of a finite sum function
+type ComponentSettings struct {
+	MaximumComponentEvaluations int  // Sets the maximum number of component evaluations that can occur
+	DisplayComponentEvaluations bool // A toggle if the component evaluations should display during the optimization
+}
+
+// NewComponentSettings creates the default component settings structure
+func NewComponentSettings() *ComponentSettings {
+	return &ComponentSettings{
+		MaximumComponentEvaluations: math.MaxInt32 - 1, // Defaults to no maximum component evaluations
+		DisplayComponentEvaluations: true,
+	}
+}
+
+// SetSettings takes the settings from ComponentSettings and translates them
+// into the ComponentEvaluations structure
+func (c *ComponentEvaluations) SetSettings(s *ComponentSettings) {
+	c.SetMax(s.MaximumComponentEvaluations)
+	c.SetDisp(s.DisplayComponentEvaluations)
+}
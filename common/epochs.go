@@ -0,0 +1,52 @@
+package common
+
+import (
+	"github.com/btracey/gofunopter/common/status"
+	"math"
+)
+
+// Epochs counts the number of passes through the data set made by a
+// stochastic optimizer
+type Epochs struct {
+	*Incrementor
+}
+
+func NewEpochs() *Epochs {
+	return &Epochs{
+		Incrementor: NewIncrementor("Epoch", math.MaxInt32-1, status.MaximumEpochs, true),
+	}
+}
+
+func (e *Epochs) Initialize() error {
+	e.setCurr(0)
+	return nil
+}
+
+// EpochSettings is a list of settings for optimizers which make
+// passes through a data set
+// See NewEpochSettings for a list of default values
+type EpochSettings struct {
+	MaximumEpochs int   // Sets the maximum number of passes through the data
+	BatchSize     int   // Number of samples used per iteration
+	Shuffle       bool  // Toggle if the order of the samples should be randomized every epoch
+	Seed          int64 // Seed for the random number generator used to shuffle samples
+	DisplayEpochs bool  // A toggle if the epoch number should display during the optimization
+}
+
+// NewEpochSettings creates the default epoch settings structure
+func NewEpochSettings() *EpochSettings {
+	return &EpochSettings{
+		MaximumEpochs: 100,
+		BatchSize:     32,
+		Shuffle:       true,
+		Seed:          1,
+		DisplayEpochs: true,
+	}
+}
+
+// SetSettings takes the settings from EpochSettings and translates them
+// into the Epochs structure
+func (e *Epochs) SetSettings(s *EpochSettings) {
+	e.SetMax(s.MaximumEpochs)
+	e.SetDisp(s.DisplayEpochs)
+}
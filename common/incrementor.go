@@ -3,14 +3,18 @@ package common
 import (
 	"github.com/btracey/gofunopter/common/display"
 	"github.com/btracey/gofunopter/common/status"
+
+	"sync/atomic"
 )
 
 // A Incrementor is a type for incrementing a value. Has methods for testing
 // if the value is over a set max and for displaying the value.
+// The current value is accessed atomically, so Add may be called from
+// multiple goroutines (for example by concurrent function evaluations)
 type Incrementor struct {
-	max   int // Maximum allowable value of the Incrementor
-	curr  int // current value of the Incrementor
-	total int // Total number at the end of the optimization run
+	curr  int64 // current value of the Incrementor (first for 64-bit alignment of atomic access)
+	max   int   // Maximum allowable value of the Incrementor
+	total int   // Total number at the end of the optimization run
 	conv  status.Status
 	name  string
 	disp  bool
@@ -29,12 +33,16 @@ func NewIncrementor(name string, max int, conv status.Status, disp bool) *Increm
 
 // Add increments the value delta to the Incrementor
 func (i *Incrementor) Add(delta int) {
-	i.curr += delta
+	atomic.AddInt64(&i.curr, int64(delta))
+}
+
+func (i *Incrementor) setCurr(val int) {
+	atomic.StoreInt64(&i.curr, int64(val))
 }
 
 func (i *Incrementor) AddToDisplay(d []*display.Struct) []*display.Struct {
 	if i.disp {
-		d = append(d, &display.Struct{Value: i.curr, Heading: i.name})
+		d = append(d, &display.Struct{Value: i.Curr(), Heading: i.name})
 	}
 	return d
 }
@@ -42,7 +50,7 @@ func (i *Incrementor) AddToDisplay(d []*display.Struct) []*display.Struct {
 // Converged checks if the value of the Incrementor is greater
 // than the maximum set value
 func (i *Incrementor) Status() status.Status {
-	if i.curr >= i.max {
+	if i.Curr() >= i.max {
 		return i.conv
 	}
 	return status.Continue
@@ -50,7 +58,7 @@ func (i *Incrementor) Status() status.Status {
 
 // Curr returns the current value of the Incrementor
 func (i *Incrementor) Curr() int {
-	return i.curr
+	return int(atomic.LoadInt64(&i.curr))
 }
 
 // Disp returns true if the value will be displayed during optimization
@@ -64,7 +72,7 @@ func (i *Incrementor) SetDisp(val bool) {
 }
 
 func (i *Incrementor) Initiailize(val int) {
-	i.curr = val
+	i.setCurr(val)
 }
 
 // Max returns the current setting for the  maximum value of the Incrementor
@@ -84,8 +92,8 @@ func (i *Incrementor) Opt() int {
 
 // SetResult sets the result of the Incrementor at the end of the optimization
 func (i *Incrementor) SetResult() {
-	i.total = i.curr
-	i.curr = 0
+	i.total = i.Curr()
+	i.setCurr(0)
 }
 
 // Incrementor doesn't need to implement Reset because there are no settings
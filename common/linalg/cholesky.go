@@ -0,0 +1,98 @@
+// Package linalg contains the small dense linear algebra routines needed
+// by the optimizers. Matrices are stored row-major as [][]float64.
+package linalg
+
+import (
+	"errors"
+	"math"
+)
+
+// Cholesky is the Cholesky factorization A = L * L^T of a symmetric
+// positive definite matrix
+type Cholesky struct {
+	l [][]float64
+}
+
+// NewCholesky factors the symmetric positive definite matrix a. Only the
+// lower triangle of a is read, and a is not modified
+func NewCholesky(a [][]float64) (*Cholesky, error) {
+	n := len(a)
+	l := make([][]float64, n)
+	for i := range l {
+		if len(a[i]) != n {
+			return nil, errors.New("linalg: matrix is not square")
+		}
+		l[i] = make([]float64, n)
+	}
+	for j := 0; j < n; j++ {
+		d := a[j][j]
+		for k := 0; k < j; k++ {
+			d -= l[j][k] * l[j][k]
+		}
+		if d <= 0 || math.IsNaN(d) {
+			return nil, errors.New("linalg: matrix is not positive definite")
+		}
+		d = math.Sqrt(d)
+		l[j][j] = d
+		for i := j + 1; i < n; i++ {
+			s := a[i][j]
+			for k := 0; k < j; k++ {
+				s -= l[i][k] * l[j][k]
+			}
+			l[i][j] = s / d
+		}
+	}
+	return &Cholesky{l: l}, nil
+}
+
+// Solve solves A * x = b, storing the result in dst. dst and b may
+// be the same slice
+func (c *Cholesky) Solve(dst, b []float64) {
+	n := len(c.l)
+	if len(dst) != n || len(b) != n {
+		panic("linalg: slice length mismatch")
+	}
+	copy(dst, b)
+	// Forward substitution L y = b
+	for i := 0; i < n; i++ {
+		s := dst[i]
+		for k := 0; k < i; k++ {
+			s -= c.l[i][k] * dst[k]
+		}
+		dst[i] = s / c.l[i][i]
+	}
+	// Back substitution L^T x = y
+	for i := n - 1; i >= 0; i-- {
+		s := dst[i]
+		for k := i + 1; k < n; k++ {
+			s -= c.l[k][i] * dst[k]
+		}
+		dst[i] = s / c.l[i][i]
+	}
+}
+
+// SolveL solves L * x = b, storing the result in dst. dst and b may be
+// the same slice
+func (c *Cholesky) SolveL(dst, b []float64) {
+	n := len(c.l)
+	if len(dst) != n || len(b) != n {
+		panic("linalg: slice length mismatch")
+	}
+	copy(dst, b)
+	for i := 0; i < n; i++ {
+		s := dst[i]
+		for k := 0; k < i; k++ {
+			s -= c.l[i][k] * dst[k]
+		}
+		dst[i] = s / c.l[i][i]
+	}
+}
+
+// LogDet returns the log of the determinant of A
+func (c *Cholesky) LogDet() float64 {
+	var s float64
+	for i := range c.l {
+		s += math.Log(c.l[i][i])
+	}
+	return 2 * s
+}
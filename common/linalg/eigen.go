@@ -0,0 +1,107 @@
+package linalg
+
+import (
+	"errors"
+	"math"
+)
+
+// EigenSym is the eigendecomposition A = V * diag(values) * V^T of a
+// symmetric matrix
+type EigenSym struct {
+	values  []float64
+	vectors [][]float64
+}
+
+// maxSweeps is the maximum number of Jacobi sweeps. Convergence is
+// quadratic, so this is only reached for matrices containing NaN or Inf
+const maxSweeps = 100
+
+// NewEigenSym computes the eigendecomposition of the symmetric matrix a
+// with the cyclic Jacobi method. Only the upper triangle of a is read,
+// and a is not modified
+func NewEigenSym(a [][]float64) (*EigenSym, error) {
+	n := len(a)
+	m := make([][]float64, n)
+	v := make([][]float64, n)
+	for i := range m {
+		if len(a[i]) != n {
+			return nil, errors.New("linalg: matrix is not square")
+		}
+		m[i] = make([]float64, n)
+		v[i] = make([]float64, n)
+		v[i][i] = 1
+	}
+	var norm float64
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			m[i][j] = a[i][j]
+			m[j][i] = a[i][j]
+			norm += a[i][j] * a[i][j]
+		}
+	}
+	if math.IsNaN(norm) || math.IsInf(norm, 0) {
+		return nil, errors.New("linalg: matrix has non-finite elements")
+	}
+
+	for sweep := 0; ; sweep++ {
+		var off float64
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				off += m[i][j] * m[i][j]
+			}
+		}
+		if off <= 1e-30*norm || off == 0 {
+			break
+		}
+		if sweep == maxSweeps {
+			return nil, errors.New("linalg: eigendecomposition did not converge")
+		}
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				if m[p][q] == 0 {
+					continue
+				}
+				// Rotation which zeros m[p][q]
+				theta := (m[q][q] - m[p][p]) / (2 * m[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < n; k++ {
+					mkp, mkq := m[k][p], m[k][q]
+					m[k][p] = c*mkp - s*mkq
+					m[k][q] = s*mkp + c*mkq
+				}
+				for k := 0; k < n; k++ {
+					mpk, mqk := m[p][k], m[q][k]
+					m[p][k] = c*mpk - s*mqk
+					m[q][k] = s*mpk + c*mqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := v[k][p], v[k][q]
+					v[k][p] = c*vkp - s*vkq
+					v[k][q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = m[i][i]
+	}
+	return &EigenSym{values: values, vectors: v}, nil
+}
+
+// Values returns the eigenvalues. The slice is not a copy
+func (e *EigenSym) Values() []float64 {
+	return e.values
+}
+
+// Vectors returns the matrix whose columns are the eigenvectors, in
+// the same order as Values. The matrix is not a copy
+func (e *EigenSym) Vectors() [][]float64 {
+	return e.vectors
+}
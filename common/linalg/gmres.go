@@ -0,0 +1,133 @@
+package linalg
+
+import (
+	"math"
+)
+
+// GMRES approximately solves A x = b, where A is only available through
+// mul, which sets dst = A v. x is the initial guess on input and the
+// solution on output. The iterations end when the norm of the residual
+// b - A x is at most tol, after maxIter products with A, or if mul
+// returns an error. The Krylov space is rebuilt from the current solution
+// every restart products. GMRES returns the norm of the final residual
+// (as estimated by the least squares problem if the iterations ended part
+// way through a restart cycle) and the number of products with A
+func GMRES(mul func(dst, v []float64) error, x, b []float64, tol float64, restart, maxIter int) (resNorm float64, iters int, err error) {
+	n := len(b)
+	if restart <= 0 || restart > n {
+		restart = n
+	}
+	r := make([]float64, n)
+	w := make([]float64, n)
+	// Krylov basis and upper Hessenberg matrix (stored by column)
+	v := make([][]float64, restart+1)
+	for i := range v {
+		v[i] = make([]float64, n)
+	}
+	h := make([][]float64, restart)
+	for j := range h {
+		h[j] = make([]float64, restart+1)
+	}
+	cs := make([]float64, restart)
+	sn := make([]float64, restart)
+	g := make([]float64, restart+1)
+	y := make([]float64, restart)
+
+	for {
+		// r = b - A x
+		if err = mul(w, x); err != nil {
+			return math.NaN(), iters, err
+		}
+		iters++
+		for i := range r {
+			r[i] = b[i] - w[i]
+		}
+		resNorm = norm2(r)
+		if resNorm <= tol || iters >= maxIter {
+			return resNorm, iters, nil
+		}
+		for i := range r {
+			v[0][i] = r[i] / resNorm
+		}
+		for i := range g {
+			g[i] = 0
+		}
+		g[0] = resNorm
+
+		k := 0
+		for ; k < restart && iters < maxIter; k++ {
+			if err = mul(w, v[k]); err != nil {
+				return math.NaN(), iters, err
+			}
+			iters++
+			// Modified Gram-Schmidt
+			for i := 0; i <= k; i++ {
+				var d float64
+				for l := range w {
+					d += w[l] * v[i][l]
+				}
+				h[k][i] = d
+				for l := range w {
+					w[l] -= d * v[i][l]
+				}
+			}
+			h[k][k+1] = norm2(w)
+			if h[k][k+1] != 0 {
+				for l := range w {
+					v[k+1][l] = w[l] / h[k][k+1]
+				}
+			}
+			// Apply the previous rotations to the new column, and find
+			// the rotation which zeros its last entry
+			for i := 0; i < k; i++ {
+				a, c := h[k][i], h[k][i+1]
+				h[k][i] = cs[i]*a + sn[i]*c
+				h[k][i+1] = -sn[i]*a + cs[i]*c
+			}
+			den := math.Hypot(h[k][k], h[k][k+1])
+			if den == 0 {
+				cs[k], sn[k] = 1, 0
+			} else {
+				cs[k], sn[k] = h[k][k]/den, h[k][k+1]/den
+			}
+			h[k][k] = den
+			h[k][k+1] = 0
+			g[k+1] = -sn[k] * g[k]
+			g[k] = cs[k] * g[k]
+			resNorm = math.Abs(g[k+1])
+			if resNorm <= tol || h[k][k] == 0 {
+				k++
+				break
+			}
+		}
+
+		// Solve the triangular system and update x
+		for i := k - 1; i >= 0; i-- {
+			y[i] = g[i]
+			for j := i + 1; j < k; j++ {
+				y[i] -= h[j][i] * y[j]
+			}
+			if h[i][i] != 0 {
+				y[i] /= h[i][i]
+			} else {
+				y[i] = 0
+			}
+		}
+		for j := 0; j < k; j++ {
+			for l := range x {
+				x[l] += y[j] * v[j][l]
+			}
+		}
+		if resNorm <= tol || iters >= maxIter {
+			return resNorm, iters, nil
+		}
+	}
+}
+
+func norm2(x []float64) float64 {
+	var s float64
+	for _, v := range x {
+		s += v * v
+	}
+	return math.Sqrt(s)
+}
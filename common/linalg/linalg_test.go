@@ -0,0 +1,161 @@
+package linalg
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEigenSym(t *testing.T) {
+	a := [][]float64{
+		{4, 1, -2, 2},
+		{1, 2, 0, 1},
+		{-2, 0, 3, -2},
+		{2, 1, -2, -1},
+	}
+	e, err := NewEigenSym(a)
+	if err != nil {
+		t.Fatalf("error during decomposition: %v", err)
+	}
+	n := len(a)
+	values := e.Values()
+	v := e.Vectors()
+	// Check A * v_k = lambda_k * v_k and that the vectors are orthonormal
+	for k := 0; k < n; k++ {
+		for i := 0; i < n; i++ {
+			var av float64
+			for j := 0; j < n; j++ {
+				av += a[i][j] * v[j][k]
+			}
+			if math.Abs(av-values[k]*v[i][k]) > 1e-10 {
+				t.Errorf("eigenpair %v does not satisfy A v = lambda v", k)
+			}
+		}
+		for l := 0; l < n; l++ {
+			var dot float64
+			for i := 0; i < n; i++ {
+				dot += v[i][k] * v[i][l]
+			}
+			want := 0.0
+			if k == l {
+				want = 1
+			}
+			if math.Abs(dot-want) > 1e-10 {
+				t.Errorf("eigenvectors %v and %v are not orthonormal", k, l)
+			}
+		}
+	}
+	var trace, sum float64
+	for i := range a {
+		trace += a[i][i]
+		sum += values[i]
+	}
+	if math.Abs(trace-sum) > 1e-10 {
+		t.Errorf("sum of eigenvalues %v does not match the trace %v", sum, trace)
+	}
+}
+
+func TestCholesky(t *testing.T) {
+	a := [][]float64{
+		{4, 2, -2},
+		{2, 10, 2},
+		{-2, 2, 6},
+	}
+	c, err := NewCholesky(a)
+	if err != nil {
+		t.Fatalf("error during factorization: %v", err)
+	}
+	if logDet := c.LogDet(); math.Abs(logDet-math.Log(144)) > 1e-12 {
+		t.Errorf("log determinant %v, %v expected", logDet, math.Log(144))
+	}
+	b := []float64{1, -2, 3}
+	x := make([]float64, 3)
+	c.Solve(x, b)
+	for i := range a {
+		var ax float64
+		for j := range x {
+			ax += a[i][j] * x[j]
+		}
+		if math.Abs(ax-b[i]) > 1e-12 {
+			t.Errorf("solution does not satisfy A x = b")
+		}
+	}
+	// |L^-1 b|^2 = b^T A^-1 b
+	y := make([]float64, 3)
+	c.SolveL(y, b)
+	var yy, bx float64
+	for i := range y {
+		yy += y[i] * y[i]
+		bx += b[i] * x[i]
+	}
+	if math.Abs(yy-bx) > 1e-12 {
+		t.Errorf("|L^-1 b|^2 = %v does not match b^T A^-1 b = %v", yy, bx)
+	}
+}
+
+func TestLU(t *testing.T) {
+	a := [][]float64{
+		{0, 2, -1, 3},
+		{1, 1, 4, -2},
+		{-3, 0, 2, 1},
+		{2, -1, 0, 5},
+	}
+	f, err := NewLU(a)
+	if err != nil {
+		t.Fatalf("error during factorization: %v", err)
+	}
+	b := []float64{1, 2, -3, 4}
+	x := make([]float64, 4)
+	f.Solve(x, b)
+	for i := range a {
+		var ax float64
+		for j := range x {
+			ax += a[i][j] * x[j]
+		}
+		if math.Abs(ax-b[i]) > 1e-12 {
+			t.Errorf("solution does not satisfy A x = b")
+		}
+	}
+	_, err = NewLU([][]float64{{1, 2}, {2, 4}})
+	if err == nil {
+		t.Errorf("no error for a singular matrix")
+	}
+}
+
+func TestGMRES(t *testing.T) {
+	a := [][]float64{
+		{4, 1, 0, 0, 2},
+		{-1, 3, 1, 0, 0},
+		{0, 2, 5, -1, 0},
+		{1, 0, -1, 4, 1},
+		{0, 0, 2, 1, 3},
+	}
+	b := []float64{1, 2, -1, 0, 3}
+	mul := func(dst, v []float64) error {
+		for i, row := range a {
+			dst[i] = 0
+			for j, aij := range row {
+				dst[i] += aij * v[j]
+			}
+		}
+		return nil
+	}
+	// Full GMRES and restarted GMRES both converge
+	for _, restart := range []int{0, 2} {
+		x := make([]float64, len(b))
+		resNorm, _, err := GMRES(mul, x, b, 1e-12, restart, 1000)
+		if err != nil {
+			t.Fatalf("restart %v: error: %v", restart, err)
+		}
+		if resNorm > 1e-12 {
+			t.Errorf("restart %v: residual norm %v", restart, resNorm)
+		}
+		ax := make([]float64, len(b))
+		mul(ax, x)
+		for i := range b {
+			if math.Abs(ax[i]-b[i]) > 1e-10 {
+				t.Errorf("restart %v: A x != b. Found %v, expected %v", restart, ax, b)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package linalg
+
+import (
+	"errors"
+	"math"
+)
+
+// LU is the LU factorization with partial pivoting P * A = L * U of a
+// square matrix
+type LU struct {
+	lu  [][]float64
+	piv []int
+}
+
+// NewLU factors the square matrix a. a is not modified. An error is
+// returned if a is singular to working precision
+func NewLU(a [][]float64) (*LU, error) {
+	n := len(a)
+	lu := make([][]float64, n)
+	var maxAbs float64
+	for i := range lu {
+		if len(a[i]) != n {
+			return nil, errors.New("linalg: matrix is not square")
+		}
+		lu[i] = make([]float64, n)
+		copy(lu[i], a[i])
+		for _, v := range a[i] {
+			maxAbs = math.Max(maxAbs, math.Abs(v))
+		}
+	}
+	piv := make([]int, n)
+	for i := range piv {
+		piv[i] = i
+	}
+	for k := 0; k < n; k++ {
+		p := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(lu[i][k]) > math.Abs(lu[p][k]) {
+				p = i
+			}
+		}
+		if !(math.Abs(lu[p][k]) > 1e-14*maxAbs) {
+			return nil, errors.New("linalg: matrix is singular")
+		}
+		lu[k], lu[p] = lu[p], lu[k]
+		piv[k], piv[p] = piv[p], piv[k]
+		for i := k + 1; i < n; i++ {
+			lu[i][k] /= lu[k][k]
+			l := lu[i][k]
+			for j := k + 1; j < n; j++ {
+				lu[i][j] -= l * lu[k][j]
+			}
+		}
+	}
+	return &LU{lu: lu, piv: piv}, nil
+}
+
+// Solve solves A * x = b, storing the result in dst. dst and b may not
+// be the same slice
+func (f *LU) Solve(dst, b []float64) {
+	n := len(f.lu)
+	if len(dst) != n || len(b) != n {
+		panic("linalg: slice length mismatch")
+	}
+	for i, p := range f.piv {
+		dst[i] = b[p]
+	}
+	for i := 0; i < n; i++ {
+		for k := 0; k < i; k++ {
+			dst[i] -= f.lu[i][k] * dst[k]
+		}
+	}
+	for i := n - 1; i >= 0; i-- {
+		for k := i + 1; k < n; k++ {
+			dst[i] -= f.lu[i][k] * dst[k]
+		}
+		dst[i] /= f.lu[i][i]
+	}
+}
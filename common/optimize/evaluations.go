@@ -0,0 +1,141 @@
+package optimize
+
+import (
+	"github.com/btracey/gofunopter/common"
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+	"math"
+)
+
+// ErrMaximumFunctionEvaluations is returned by the function wrappers when
+// the evaluation budget has been spent. Many optimizers evaluate several
+// points per iteration, so the budget is checked at every evaluation
+// rather than only between iterations
+var ErrMaximumFunctionEvaluations = errors.New("maximum function evaluations reached")
+
+// ErrNoDerivative is returned by LimitedUniObj.ObjGrad if the user defined
+// function does not provide the derivative
+var ErrNoDerivative = errors.New("user defined function does not implement optimize.UniObjGrad")
+
+// EvalStatus converts an error from a function evaluation into the status
+// to be returned from Iterate by the optimizer called name. Running out of
+// function evaluations is not an error
+func EvalStatus(name string, err error) (status.Status, error) {
+	switch err {
+	case ErrMaximumFunctionEvaluations:
+		return status.MaximumFunctionEvaluations, nil
+	case ErrNoDerivative:
+		return status.OptimizerError, errors.New(name + ": " + err.Error())
+	}
+	return status.UserFunctionError, errors.New(name + ": user defined function error: " + err.Error())
+}
+
+// Less returns true if a is a better objective value than b. NaN is worse
+// than any other value
+func Less(a, b float64) bool {
+	if math.IsNaN(a) {
+		return false
+	}
+	if math.IsNaN(b) {
+		return true
+	}
+	return a < b
+}
+
+// LimitedMultiObj wraps the user defined function for the drivers. It
+// counts the evaluations in FunEvals, returns
+// ErrMaximumFunctionEvaluations once they reach the maximum, and adds each
+// location and objective value to the histories
+type LimitedMultiObj struct {
+	Fun      MultiObj
+	Loc      *multi.Location
+	Obj      *uni.Objective
+	FunEvals *common.FunctionEvaluations
+}
+
+func (m *LimitedMultiObj) Objective(x []float64) (obj float64, err error) {
+	if m.FunEvals.Curr() >= m.FunEvals.Max() {
+		return math.NaN(), ErrMaximumFunctionEvaluations
+	}
+	obj, err = m.Fun.Objective(x)
+	m.Loc.AddToHist(x)
+	m.Obj.AddToHist(obj)
+	m.FunEvals.Add(1)
+	return
+}
+
+// LimitedMultiObjGrad is LimitedMultiObj for functions with a gradient.
+// Loc and Obj may be nil if there are no histories to keep
+type LimitedMultiObjGrad struct {
+	Fun      MultiObjGrad
+	Loc      *multi.Location
+	Obj      *uni.Objective
+	FunEvals *common.FunctionEvaluations
+}
+
+func (m *LimitedMultiObjGrad) ObjGrad(x []float64) (obj float64, grad []float64, err error) {
+	if m.FunEvals.Curr() >= m.FunEvals.Max() {
+		return math.NaN(), nil, ErrMaximumFunctionEvaluations
+	}
+	obj, grad, err = m.Fun.ObjGrad(x)
+	if m.Loc != nil {
+		m.Loc.AddToHist(x)
+	}
+	if m.Obj != nil {
+		m.Obj.AddToHist(obj)
+	}
+	m.FunEvals.Add(1)
+	return
+}
+
+// LimitedUniObj is LimitedMultiObj for univariate functions. Obj may be
+// nil if there is no objective to keep the history of
+type LimitedUniObj struct {
+	Fun      UniObj
+	Loc      *uni.Location
+	Obj      *uni.Objective
+	FunEvals *common.FunctionEvaluations
+}
+
+func (m *LimitedUniObj) Objective(x float64) (obj float64, err error) {
+	if m.FunEvals.Curr() >= m.FunEvals.Max() {
+		return math.NaN(), ErrMaximumFunctionEvaluations
+	}
+	obj, err = m.Fun.Objective(x)
+	m.addToHist(x, obj)
+	return
+}
+
+// ObjGrad evaluates the derivative as well, for methods which use it. It
+// returns ErrNoDerivative if the user defined function does not provide
+// the derivative
+func (m *LimitedUniObj) ObjGrad(x float64) (obj float64, grad float64, err error) {
+	g, ok := m.Fun.(UniObjGrad)
+	if !ok {
+		return math.NaN(), math.NaN(), ErrNoDerivative
+	}
+	if m.FunEvals.Curr() >= m.FunEvals.Max() {
+		return math.NaN(), math.NaN(), ErrMaximumFunctionEvaluations
+	}
+	obj, grad, err = g.ObjGrad(x)
+	m.addToHist(x, obj)
+	return
+}
+
+func (m *LimitedUniObj) addToHist(x, obj float64) {
+	m.Loc.AddToHist(x)
+	if m.Obj != nil {
+		m.Obj.AddToHist(obj)
+	}
+	m.FunEvals.Add(1)
+}
+
+// RemainingEvaluator is implemented by the function wrappers which count
+// the evaluations, so that optimizers which run inner optimizations can
+// limit them to the rest of the evaluation budget
+type RemainingEvaluator interface {
+	RemainingEvaluations() int
+}
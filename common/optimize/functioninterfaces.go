@@ -12,6 +12,12 @@ type UniObjGrad interface {
 	ObjGrad(x float64) (obj float64, grad float64, err error)
 }
 
+// UniObjGradHess is a univariate function which also provides its second
+// derivative
+type UniObjGradHess interface {
+	ObjGradHess(x float64) (obj float64, grad float64, hess float64, err error)
+}
+
 type MultiObj interface {
 	Objective(x []float64) (obj float64, err error)
 }
@@ -23,3 +29,38 @@ type MultiGrad interface {
 type MultiObjGrad interface {
 	ObjGrad(x []float64) (obj float64, grad []float64, err error)
 }
+
+// MultiResidual is a system of nonlinear equations F(x) = 0 with as many
+// equations as unknowns. Residual returns F(x)
+type MultiResidual interface {
+	Residual(x []float64) (f []float64, err error)
+}
+
+// MultiJacobian is the Jacobian of a system of equations, where jac[i][j]
+// is the derivative of equation i with respect to x_j
+type MultiJacobian interface {
+	Jacobian(x []float64) (jac [][]float64, err error)
+}
+
+// MultiBatchObjGrad is a function defined over a set of samples (for
+// example the loss over a data set). BatchObjGrad returns the objective
+// and gradient evaluated using only the samples whose indices are in batch
+type MultiBatchObjGrad interface {
+	NumSamples() int
+	BatchObjGrad(x []float64, batch []int) (obj float64, grad []float64, err error)
+}
+
+// FiniteSum is a function which is the sum of components
+// f(x) = sum_i f_i(x). ComponentObjGrad returns the objective
+// and gradient of the ith component
+type FiniteSum interface {
+	NumComponents() int
+	ComponentObjGrad(i int, x []float64) (obj float64, grad []float64, err error)
+}
+
+// MultiObjBatch is a function which can evaluate many locations at once
+// (for example by running simulations in parallel). ObjBatch returns the
+// objective value at each of the locations in xs
+type MultiObjBatch interface {
+	ObjBatch(xs [][]float64) (objs []float64, err error)
+}
@@ -0,0 +1,201 @@
+// Package projection defines projections onto simple convex sets for use
+// by constrained optimizers
+package projection
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/btracey/gofunopter/common/linalg"
+	"github.com/gonum/floats"
+)
+
+// A Projector projects a location onto a convex set. Project
+// overwrites x with the closest point (in the Euclidean norm)
+// within the set
+type Projector interface {
+	Project(x []float64) error
+}
+
+// Feasible returns true if x is within the set (to within tol in
+// every coordinate)
+func Feasible(p Projector, x []float64, tol float64) (bool, error) {
+	tmp := make([]float64, len(x))
+	copy(tmp, x)
+	err := p.Project(tmp)
+	if err != nil {
+		return false, err
+	}
+	for i, val := range x {
+		if math.Abs(val-tmp[i]) > tol {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Box is the set lower <= x <= upper. Elements of the bounds may be
+// infinite
+type Box struct {
+	Lower []float64
+	Upper []float64
+}
+
+// NewBox returns a new box with the given bounds
+func NewBox(lower, upper []float64) (*Box, error) {
+	if len(lower) != len(upper) {
+		return nil, errors.New("projection: bound length mismatch")
+	}
+	for i := range lower {
+		if lower[i] > upper[i] {
+			return nil, errors.New("projection: lower bound is greater than upper bound")
+		}
+	}
+	return &Box{Lower: lower, Upper: upper}, nil
+}
+
+// Project clamps each element of x to the bounds
+func (b *Box) Project(x []float64) error {
+	if len(x) != len(b.Lower) || len(x) != len(b.Upper) {
+		return errors.New("projection: box length mismatch")
+	}
+	for i := range x {
+		x[i] = math.Min(math.Max(x[i], b.Lower[i]), b.Upper[i])
+	}
+	return nil
+}
+
+// Simplex is the set {x : x_i >= 0, sum_i x_i = Sum}. Sum = 1 is the
+// probability simplex
+type Simplex struct {
+	Sum float64
+}
+
+// NewSimplex returns a new simplex whose elements add to sum
+func NewSimplex(sum float64) (*Simplex, error) {
+	if sum <= 0 {
+		return nil, errors.New("projection: simplex sum must be positive")
+	}
+	return &Simplex{Sum: sum}, nil
+}
+
+// Project projects x onto the simplex using the sort based method
+// of Duchi et. al. (2008)
+func (s *Simplex) Project(x []float64) error {
+	if len(x) == 0 {
+		return errors.New("projection: zero length location")
+	}
+	u := make([]float64, len(x))
+	copy(u, x)
+	sort.Sort(sort.Reverse(sort.Float64Slice(u)))
+
+	var cumSum, theta float64
+	for i, val := range u {
+		cumSum += val
+		t := (cumSum - s.Sum) / float64(i+1)
+		if val-t > 0 {
+			theta = t
+		}
+	}
+	for i := range x {
+		x[i] = math.Max(x[i]-theta, 0)
+	}
+	return nil
+}
+
+// L2Ball is the set ||x - Center||_2 <= Radius. A nil center is
+// treated as the origin
+type L2Ball struct {
+	Center []float64
+	Radius float64
+}
+
+// NewL2Ball returns a new ball with the given center and radius
+func NewL2Ball(center []float64, radius float64) (*L2Ball, error) {
+	if radius < 0 {
+		return nil, errors.New("projection: negative radius")
+	}
+	return &L2Ball{Center: center, Radius: radius}, nil
+}
+
+// Project scales x - Center onto the surface of the ball if it is outside
+func (b *L2Ball) Project(x []float64) error {
+	if b.Center != nil && len(b.Center) != len(x) {
+		return errors.New("projection: ball center length mismatch")
+	}
+	if b.Center != nil {
+		floats.Sub(x, b.Center)
+	}
+	norm := floats.Norm(x, 2)
+	if norm > b.Radius {
+		floats.Scale(b.Radius/norm, x)
+	}
+	if b.Center != nil {
+		floats.Add(x, b.Center)
+	}
+	return nil
+}
+
+// Affine is the set {x : A x = b}. A must have full row rank. Use
+// NewAffine to construct
+type Affine struct {
+	a    [][]float64
+	b    []float64
+	chol *linalg.Cholesky // factorization of A A^T
+
+	resid []float64
+	tmp   []float64
+}
+
+// NewAffine returns the affine subspace A x = b. A is stored
+// row-major and is not copied, so it should not be modified
+func NewAffine(a [][]float64, b []float64) (*Affine, error) {
+	m := len(a)
+	if m == 0 {
+		return nil, errors.New("projection: empty constraint matrix")
+	}
+	if len(b) != m {
+		return nil, errors.New("projection: constraint length mismatch")
+	}
+	n := len(a[0])
+	aat := make([][]float64, m)
+	for i := range aat {
+		if len(a[i]) != n {
+			return nil, errors.New("projection: constraint matrix is ragged")
+		}
+		aat[i] = make([]float64, m)
+		for j := 0; j <= i; j++ {
+			aat[i][j] = floats.Dot(a[i], a[j])
+			aat[j][i] = aat[i][j]
+		}
+	}
+	chol, err := linalg.NewCholesky(aat)
+	if err != nil {
+		return nil, errors.New("projection: constraint matrix does not have full row rank")
+	}
+	return &Affine{
+		a:     a,
+		b:     b,
+		chol:  chol,
+		resid: make([]float64, m),
+		tmp:   make([]float64, m),
+	}, nil
+}
+
+// Project computes x - A^T (A A^T)^-1 (A x - b)
+func (p *Affine) Project(x []float64) error {
+	if len(x) != len(p.a[0]) {
+		return errors.New("projection: affine length mismatch")
+	}
+	for i, row := range p.a {
+		p.resid[i] = floats.Dot(row, x) - p.b[i]
+	}
+	p.chol.Solve(p.tmp, p.resid)
+	for i, row := range p.a {
+		for j, val := range row {
+			x[j] -= val * p.tmp[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package projection
+
+import (
+	"github.com/gonum/floats"
+	"math"
+	"testing"
+)
+
+const projTol = 1E-12
+
+func TestBox(t *testing.T) {
+	b, err := NewBox([]float64{0, -1, math.Inf(-1)}, []float64{1, 1, 2})
+	if err != nil {
+		t.Fatalf("Error creating box: " + err.Error())
+	}
+	x := []float64{-3, 0.5, 5}
+	err = b.Project(x)
+	if err != nil {
+		t.Fatalf("Error projecting onto box: " + err.Error())
+	}
+	if !floats.Equal(x, []float64{0, 0.5, 2}) {
+		t.Errorf("Box projection incorrect. %v found", x)
+	}
+	_, err = NewBox([]float64{1}, []float64{0})
+	if err == nil {
+		t.Errorf("No error for lower bound greater than upper bound")
+	}
+}
+
+func TestSimplex(t *testing.T) {
+	s, err := NewSimplex(1)
+	if err != nil {
+		t.Fatalf("Error creating simplex: " + err.Error())
+	}
+	for _, test := range []struct {
+		x, ans []float64
+	}{
+		{[]float64{0.2, 0.3, 0.5}, []float64{0.2, 0.3, 0.5}},
+		{[]float64{1, 1, 1}, []float64{1.0 / 3, 1.0 / 3, 1.0 / 3}},
+		{[]float64{2, 0, -1}, []float64{1, 0, 0}},
+		{[]float64{0.5, 0.5, 1}, []float64{1.0 / 6, 1.0 / 6, 2.0 / 3}},
+	} {
+		err = s.Project(test.x)
+		if err != nil {
+			t.Errorf("Error projecting onto simplex: " + err.Error())
+			continue
+		}
+		if !floats.EqualApprox(test.x, test.ans, projTol) {
+			t.Errorf("Simplex projection incorrect. %v found, %v expected", test.x, test.ans)
+		}
+	}
+}
+
+func TestL2Ball(t *testing.T) {
+	b, err := NewL2Ball([]float64{1, 1}, 1)
+	if err != nil {
+		t.Fatalf("Error creating ball: " + err.Error())
+	}
+	x := []float64{4, 5}
+	b.Project(x)
+	if !floats.EqualApprox(x, []float64{1.6, 1.8}, projTol) {
+		t.Errorf("Ball projection incorrect. %v found", x)
+	}
+	x = []float64{1.5, 1}
+	b.Project(x)
+	if !floats.EqualApprox(x, []float64{1.5, 1}, projTol) {
+		t.Errorf("Ball projection moved an interior point. %v found", x)
+	}
+}
+
+func TestAffine(t *testing.T) {
+	// Plane x + y + z = 3
+	a, err := NewAffine([][]float64{{1, 1, 1}}, []float64{3})
+	if err != nil {
+		t.Fatalf("Error creating affine set: " + err.Error())
+	}
+	x := []float64{0, 0, 0}
+	a.Project(x)
+	if !floats.EqualApprox(x, []float64{1, 1, 1}, projTol) {
+		t.Errorf("Affine projection incorrect. %v found", x)
+	}
+	feasible, _ := Feasible(a, x, projTol)
+	if !feasible {
+		t.Errorf("Projected point is not feasible")
+	}
+	_, err = NewAffine([][]float64{{1, 0}, {2, 0}}, []float64{1, 2})
+	if err == nil {
+		t.Errorf("No error for rank deficient constraints")
+	}
+}
@@ -0,0 +1,144 @@
+// Package proximal defines proximal operators of non-smooth functions for
+// use by proximal gradient optimizers
+package proximal
+
+import (
+	"errors"
+	"math"
+
+	"github.com/btracey/gofunopter/common/projection"
+	"github.com/gonum/floats"
+)
+
+// A Proximal is a (possibly non-smooth) convex function g with an
+// inexpensive proximal operator.
+// Prox overwrites x with argmin_u g(u) + 1/(2*step) * ||u - x||^2
+// Value returns g(x)
+type Proximal interface {
+	Prox(x []float64, step float64) error
+	Value(x []float64) float64
+}
+
+// L1 is the function Lambda * ||x||_1
+type L1 struct {
+	Lambda float64
+}
+
+// Prox soft-thresholds each element of x by step * Lambda
+func (l L1) Prox(x []float64, step float64) error {
+	softThreshold(x, step*l.Lambda)
+	return nil
+}
+
+func (l L1) Value(x []float64) float64 {
+	return l.Lambda * floats.Norm(x, 1)
+}
+
+func softThreshold(x []float64, t float64) {
+	for i, val := range x {
+		switch {
+		case val > t:
+			x[i] = val - t
+		case val < -t:
+			x[i] = val + t
+		default:
+			x[i] = 0
+		}
+	}
+}
+
+// ElasticNet is the function L1 * ||x||_1 + L2/2 * ||x||_2^2
+type ElasticNet struct {
+	L1 float64
+	L2 float64
+}
+
+func (e ElasticNet) Prox(x []float64, step float64) error {
+	softThreshold(x, step*e.L1)
+	floats.Scale(1/(1+step*e.L2), x)
+	return nil
+}
+
+func (e ElasticNet) Value(x []float64) float64 {
+	norm := floats.Norm(x, 2)
+	return e.L1*floats.Norm(x, 1) + e.L2/2*norm*norm
+}
+
+// GroupLasso is the function Lambda * sum_g ||x_g||_2 where the
+// groups are given by the indices in Groups. Indices not in any group
+// are not penalized. Groups should not overlap
+type GroupLasso struct {
+	Lambda float64
+	Groups [][]int
+}
+
+// Prox performs block soft-thresholding on each group
+func (gl GroupLasso) Prox(x []float64, step float64) error {
+	t := step * gl.Lambda
+	for _, group := range gl.Groups {
+		norm, err := gl.groupNorm(x, group)
+		if err != nil {
+			return err
+		}
+		scale := 0.0
+		if norm > t {
+			scale = 1 - t/norm
+		}
+		for _, idx := range group {
+			x[idx] *= scale
+		}
+	}
+	return nil
+}
+
+func (gl GroupLasso) Value(x []float64) float64 {
+	var sum float64
+	for _, group := range gl.Groups {
+		norm, err := gl.groupNorm(x, group)
+		if err != nil {
+			return math.NaN()
+		}
+		sum += norm
+	}
+	return gl.Lambda * sum
+}
+
+func (gl GroupLasso) groupNorm(x []float64, group []int) (float64, error) {
+	var sum float64
+	for _, idx := range group {
+		if idx < 0 || idx >= len(x) {
+			return math.NaN(), errors.New("proximal: group index out of range")
+		}
+		sum += x[idx] * x[idx]
+	}
+	return math.Sqrt(sum), nil
+}
+
+// Indicator is the indicator function of a convex set (zero inside
+// the set and infinity outside). Its proximal operator is the projection
+// onto the set
+type Indicator struct {
+	projection.Projector
+	Tol float64 // Feasibility tolerance used by Value
+}
+
+// NewBoxIndicator returns the indicator of the box lower <= x <= upper
+func NewBoxIndicator(lower, upper []float64) (*Indicator, error) {
+	b, err := projection.NewBox(lower, upper)
+	if err != nil {
+		return nil, err
+	}
+	return &Indicator{Projector: b, Tol: 1E-12}, nil
+}
+
+func (ind *Indicator) Prox(x []float64, step float64) error {
+	return ind.Project(x)
+}
+
+func (ind *Indicator) Value(x []float64) float64 {
+	feasible, err := projection.Feasible(ind.Projector, x, ind.Tol)
+	if err != nil || !feasible {
+		return math.Inf(1)
+	}
+	return 0
+}
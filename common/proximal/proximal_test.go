@@ -0,0 +1,54 @@
+package proximal
+
+import (
+	"github.com/gonum/floats"
+	"math"
+	"testing"
+)
+
+const proxTol = 1E-12
+
+func TestProx(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		prox Proximal
+		step float64
+		x    []float64
+		ans  []float64
+	}{
+		{"L1", L1{Lambda: 1}, 0.5, []float64{2, -0.25, -1}, []float64{1.5, 0, -0.5}},
+		{"ElasticNet", ElasticNet{L1: 1, L2: 2}, 0.5, []float64{2, -0.25, -1}, []float64{0.75, 0, -0.25}},
+		{"GroupLasso", GroupLasso{Lambda: 1, Groups: [][]int{{0, 1}, {2}}}, 1, []float64{3, 4, 0.5}, []float64{2.4, 3.2, 0}},
+		{"Box", mustBox([]float64{0, 0, 0}, []float64{1, 1, 1}), 1, []float64{2, -0.25, 0.5}, []float64{1, 0, 0.5}},
+	} {
+		err := test.prox.Prox(test.x, test.step)
+		if err != nil {
+			t.Errorf("%v: error during prox: %v", test.name, err)
+			continue
+		}
+		if !floats.EqualApprox(test.x, test.ans, proxTol) {
+			t.Errorf("%v: prox incorrect. %v found, %v expected", test.name, test.x, test.ans)
+		}
+	}
+}
+
+func TestIndicatorValue(t *testing.T) {
+	ind, err := NewBoxIndicator([]float64{0, 0}, []float64{1, 1})
+	if err != nil {
+		t.Fatalf("Error creating box indicator: " + err.Error())
+	}
+	if v := ind.Value([]float64{0.5, 1}); v != 0 {
+		t.Errorf("Feasible point has indicator value %v", v)
+	}
+	if v := ind.Value([]float64{0.5, 2}); !math.IsInf(v, 1) {
+		t.Errorf("Infeasible point has indicator value %v", v)
+	}
+}
+
+func mustBox(lower, upper []float64) *Indicator {
+	ind, err := NewBoxIndicator(lower, upper)
+	if err != nil {
+		panic(err)
+	}
+	return ind
+}
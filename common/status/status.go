@@ -35,6 +35,10 @@ const (
 	StepAbsTol
 	StepRelTol
 	WolfeConditionsMet
+	ProjGradAbsTol  // Norm of the projected gradient (or gradient mapping) below tolerance
+	ObjRangeTol     // Range of recent objective values below tolerance
+	StagnationTol   // No improvement in the objective over a window of iterations
+	BoundaryOptimum // Optimum is at a bound of the feasible interval
 )
 
 const (
@@ -46,4 +50,7 @@ const (
 	MaximumFunctionEvaluations
 	MaximumRuntime
 	LinesearchFailure
+	MaximumEpochs
+	MaximumComponentEvaluations
+	IllConditioned // Condition number of a model (Hessian, covariance, etc.) too large to continue
 )
@@ -51,10 +51,15 @@ type ObjectiveSettings struct {
 	KeepObjectiveHistory       bool
 }
 
+// NewObjectiveSettings returns the default settings. The absolute tolerance
+// is negative infinity as in NewObjective, so that objectives which go
+// below zero are minimized until another criterion is met, rather than
+// stopping with ObjAbsTol at the first negative value
 func NewObjectiveSettings() *ObjectiveSettings {
 	return &ObjectiveSettings{
-		InitialObjective: math.NaN(),
-		DisplayObjective: true,
+		InitialObjective:           math.NaN(),
+		DisplayObjective:           true,
+		ObjectiveAbsoluteTolerance: math.Inf(-1),
 	}
 }
 
@@ -0,0 +1,159 @@
+package expr
+
+import (
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/multivariate"
+
+	"math"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	x := []float64{2, 3, 0.5}
+	for _, test := range []struct {
+		s    string
+		want float64
+	}{
+		{"1 + 2*3", 7},
+		{"(1 + 2)*3", 9},
+		{"8 / 4 / 2", 1},
+		{"2 - 3 - 4", -5},
+		{"2^3^2", 512},
+		{"-x0^2", -4},
+		{"2^-1", 0.5},
+		{"--x1", 3},
+		{"x0*x1 + x2", 6.5},
+		{"1.5e2 + 2E-1", 150.2},
+		{"sin(pi/2) + cos(0) + exp(0) + log(e)", 4},
+		{"sqrt(x0^2 + 5) + abs(-x1) + sign(-x2)", 5},
+		{"tan(x2)", math.Tan(0.5)},
+	} {
+		n, err := Parse(test.s)
+		if err != nil {
+			t.Errorf("%v: error parsing: %v", test.s, err)
+			continue
+		}
+		if v := n.Eval(x); math.Abs(v-test.want) > 1E-12*math.Max(1, math.Abs(test.want)) {
+			t.Errorf("%v: evaluated to %v, expected %v", test.s, v, test.want)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"1 +",
+		"(x0",
+		"x0)",
+		"sin x0",
+		"foo(x0)",
+		"y",
+		"x",
+		"x-1",
+		"2 $ 3",
+		"1..2",
+		"x10000",
+		"x0 + x100000000",
+	} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("no error parsing %q", s)
+		}
+	}
+}
+
+func TestDeriv(t *testing.T) {
+	x := []float64{0.7, -1.3, 2.1}
+	for _, s := range []string{
+		"(1-x0)^2 + 100*(x1-x0^2)^2",
+		"x0*x1*x2 - x2/x1",
+		"x0^x2 + 2^x1 + x2^-1.5",
+		"sin(x0*x1) + cos(x2)^2 + tan(x0)",
+		"exp(-x0^2 - x1^2) * log(x2)",
+		"sqrt(1 + x0^2) + abs(x1) * sign(x2)",
+		"-(x0 - 3*x1) / (1 + x2^2)",
+	} {
+		f, err := NewFunction(s)
+		if err != nil {
+			t.Errorf("%v: error parsing: %v", s, err)
+			continue
+		}
+		obj, grad, err := f.ObjGrad(x)
+		if err != nil {
+			t.Errorf("%v: error evaluating: %v", s, err)
+			continue
+		}
+		if o, _ := f.Objective(x); o != obj {
+			t.Errorf("%v: objective mismatch between Objective and ObjGrad", s)
+		}
+		if len(grad) != len(x) {
+			t.Errorf("%v: gradient has length %v, expected %v", s, len(grad), len(x))
+			continue
+		}
+		const h = 1E-6
+		xh := make([]float64, len(x))
+		for i := range x {
+			copy(xh, x)
+			xh[i] = x[i] + h
+			fp, _ := f.Objective(xh)
+			xh[i] = x[i] - h
+			fm, _ := f.Objective(xh)
+			fd := (fp - fm) / (2 * h)
+			if math.Abs(grad[i]-fd) > 1E-6*math.Max(1, math.Abs(fd)) {
+				t.Errorf("%v: derivative %v is %v, finite difference is %v", s, i, grad[i], fd)
+			}
+		}
+	}
+}
+
+func TestDerivZeroBase(t *testing.T) {
+	f, err := NewFunction("x0^x1")
+	if err != nil {
+		t.Fatalf("error parsing: %v", err)
+	}
+	for _, test := range []struct {
+		x, grad []float64
+	}{
+		{[]float64{0, 2}, []float64{0, 0}},
+		{[]float64{0, 1}, []float64{1, 0}},
+		{[]float64{2, 3}, []float64{12, 8 * math.Log(2)}},
+	} {
+		_, grad, err := f.ObjGrad(test.x)
+		if err != nil {
+			t.Errorf("%v: error evaluating: %v", test.x, err)
+			continue
+		}
+		for i := range grad {
+			if math.Abs(grad[i]-test.grad[i]) > 1E-12 {
+				t.Errorf("%v: gradient is %v, expected %v", test.x, grad, test.grad)
+				break
+			}
+		}
+	}
+}
+
+func TestFunction(t *testing.T) {
+	f, err := NewFunction("(1-x0)^2 + 100*(x1-x0^2)^2")
+	if err != nil {
+		t.Fatalf("error parsing: %v", err)
+	}
+	if f.Dim() != 2 {
+		t.Errorf("dimension is %v, expected 2", f.Dim())
+	}
+	if _, err := f.Objective([]float64{1}); err == nil {
+		t.Errorf("no error for a short location")
+	}
+
+	settings := multivariate.NewMultiGradSettings()
+	settings.Display = false
+	settings.GradientAbsoluteTolerance = 1E-10
+	obj, loc, result, err := multivariate.OptimizeGrad(f, []float64{-1.2, 1}, settings, nil)
+	if err != nil {
+		t.Fatalf("error optimizing: %v", err)
+	}
+	if result.Status != status.GradAbsTol {
+		t.Errorf("status is %v, expected GradAbsTol", result.Status)
+	}
+	if obj > 1E-12 || math.Abs(loc[0]-1) > 1E-5 || math.Abs(loc[1]-1) > 1E-5 {
+		t.Errorf("optimum %v at %v, expected 0 at [1 1]", obj, loc)
+	}
+}
@@ -0,0 +1,108 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+)
+
+// maxVar returns the largest index of a variable in the expression, and
+// -1 if there are none
+func maxVar(n Node) int {
+	switch n := n.(type) {
+	case Const:
+		return -1
+	case Var:
+		return int(n)
+	case negNode:
+		return maxVar(n.a)
+	case addNode:
+		return maxInt(maxVar(n.a), maxVar(n.b))
+	case subNode:
+		return maxInt(maxVar(n.a), maxVar(n.b))
+	case mulNode:
+		return maxInt(maxVar(n.a), maxVar(n.b))
+	case divNode:
+		return maxInt(maxVar(n.a), maxVar(n.b))
+	case powNode:
+		return maxInt(maxVar(n.a), maxVar(n.b))
+	case callNode:
+		return maxVar(n.a)
+	case xlogyNode:
+		return maxInt(maxVar(n.a), maxVar(n.b))
+	}
+	panic("expr: unknown node")
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Function is an objective function given by an expression. It
+// implements optimize.MultiObj and optimize.MultiObjGrad, with the
+// gradient found by symbolic differentiation
+type Function struct {
+	expr Node
+	grad []Node
+}
+
+// NewFunction parses the expression and differentiates it
+func NewFunction(s string) (*Function, error) {
+	n, err := Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	f := &Function{
+		expr: n,
+		grad: make([]Node, maxVar(n)+1),
+	}
+	for i := range f.grad {
+		f.grad[i] = n.Deriv(i)
+	}
+	return f, nil
+}
+
+// Dim returns the smallest dimension of the locations at which the
+// function can be evaluated, which is one more than the largest index of a
+// variable in the expression
+func (f *Function) Dim() int {
+	return len(f.grad)
+}
+
+// Gradient returns the expressions of the partial derivatives
+func (f *Function) Gradient() []Node {
+	return f.grad
+}
+
+func (f *Function) String() string {
+	return f.expr.String()
+}
+
+func (f *Function) checkDim(x []float64) error {
+	if len(x) < len(f.grad) {
+		return fmt.Errorf("expr: location has length %v, the expression needs %v", len(x), len(f.grad))
+	}
+	return nil
+}
+
+func (f *Function) Objective(x []float64) (obj float64, err error) {
+	if err := f.checkDim(x); err != nil {
+		return math.NaN(), err
+	}
+	return f.expr.Eval(x), nil
+}
+
+// ObjGrad evaluates the function and its gradient. The partial derivatives
+// with respect to variables which are not in the expression are zero
+func (f *Function) ObjGrad(x []float64) (obj float64, grad []float64, err error) {
+	if err := f.checkDim(x); err != nil {
+		return math.NaN(), nil, err
+	}
+	grad = make([]float64, len(x))
+	for i, g := range f.grad {
+		grad[i] = g.Eval(x)
+	}
+	return f.expr.Eval(x), grad, nil
+}
@@ -0,0 +1,323 @@
+package expr
+
+import (
+	"math"
+	"strconv"
+)
+
+// Node is a node of the tree of a parsed expression
+type Node interface {
+	// Eval evaluates the expression at x. The variable xi is x[i]
+	Eval(x []float64) float64
+	// Deriv returns the partial derivative of the expression with respect
+	// to the variable xi
+	Deriv(i int) Node
+	String() string
+}
+
+// Const is a constant
+type Const float64
+
+func (c Const) Eval(x []float64) float64 {
+	return float64(c)
+}
+
+func (c Const) Deriv(i int) Node {
+	return Const(0)
+}
+
+func (c Const) String() string {
+	return strconv.FormatFloat(float64(c), 'g', -1, 64)
+}
+
+// Var is the variable xi, where i is the value of the Var
+type Var int
+
+func (v Var) Eval(x []float64) float64 {
+	return x[v]
+}
+
+func (v Var) Deriv(i int) Node {
+	if int(v) == i {
+		return Const(1)
+	}
+	return Const(0)
+}
+
+func (v Var) String() string {
+	return "x" + strconv.Itoa(int(v))
+}
+
+func isConst(n Node, v float64) bool {
+	c, ok := n.(Const)
+	return ok && float64(c) == v
+}
+
+// The constructors below fold constants and drop the terms which are
+// zero or one, which keeps the trees of derivatives small
+
+func neg(a Node) Node {
+	switch a := a.(type) {
+	case Const:
+		return -a
+	case negNode:
+		return a.a
+	}
+	return negNode{a}
+}
+
+func add(a, b Node) Node {
+	ca, aConst := a.(Const)
+	cb, bConst := b.(Const)
+	switch {
+	case aConst && bConst:
+		return ca + cb
+	case isConst(a, 0):
+		return b
+	case isConst(b, 0):
+		return a
+	}
+	return addNode{a, b}
+}
+
+func sub(a, b Node) Node {
+	ca, aConst := a.(Const)
+	cb, bConst := b.(Const)
+	switch {
+	case aConst && bConst:
+		return ca - cb
+	case isConst(a, 0):
+		return neg(b)
+	case isConst(b, 0):
+		return a
+	}
+	return subNode{a, b}
+}
+
+func mul(a, b Node) Node {
+	ca, aConst := a.(Const)
+	cb, bConst := b.(Const)
+	switch {
+	case aConst && bConst:
+		return ca * cb
+	case isConst(a, 0) || isConst(b, 0):
+		return Const(0)
+	case isConst(a, 1):
+		return b
+	case isConst(b, 1):
+		return a
+	case isConst(a, -1):
+		return neg(b)
+	case isConst(b, -1):
+		return neg(a)
+	}
+	return mulNode{a, b}
+}
+
+func div(a, b Node) Node {
+	ca, aConst := a.(Const)
+	cb, bConst := b.(Const)
+	switch {
+	case aConst && bConst:
+		return ca / cb
+	case isConst(a, 0):
+		return Const(0)
+	case isConst(b, 1):
+		return a
+	}
+	return divNode{a, b}
+}
+
+func pow(a, b Node) Node {
+	ca, aConst := a.(Const)
+	cb, bConst := b.(Const)
+	switch {
+	case aConst && bConst:
+		return Const(math.Pow(float64(ca), float64(cb)))
+	case isConst(b, 0):
+		return Const(1)
+	case isConst(b, 1):
+		return a
+	}
+	return powNode{a, b}
+}
+
+type negNode struct{ a Node }
+
+func (n negNode) Eval(x []float64) float64 {
+	return -n.a.Eval(x)
+}
+
+func (n negNode) Deriv(i int) Node {
+	return neg(n.a.Deriv(i))
+}
+
+func (n negNode) String() string {
+	return "(-" + n.a.String() + ")"
+}
+
+type addNode struct{ a, b Node }
+
+func (n addNode) Eval(x []float64) float64 {
+	return n.a.Eval(x) + n.b.Eval(x)
+}
+
+func (n addNode) Deriv(i int) Node {
+	return add(n.a.Deriv(i), n.b.Deriv(i))
+}
+
+func (n addNode) String() string {
+	return "(" + n.a.String() + " + " + n.b.String() + ")"
+}
+
+type subNode struct{ a, b Node }
+
+func (n subNode) Eval(x []float64) float64 {
+	return n.a.Eval(x) - n.b.Eval(x)
+}
+
+func (n subNode) Deriv(i int) Node {
+	return sub(n.a.Deriv(i), n.b.Deriv(i))
+}
+
+func (n subNode) String() string {
+	return "(" + n.a.String() + " - " + n.b.String() + ")"
+}
+
+type mulNode struct{ a, b Node }
+
+func (n mulNode) Eval(x []float64) float64 {
+	return n.a.Eval(x) * n.b.Eval(x)
+}
+
+func (n mulNode) Deriv(i int) Node {
+	return add(mul(n.a.Deriv(i), n.b), mul(n.a, n.b.Deriv(i)))
+}
+
+func (n mulNode) String() string {
+	return "(" + n.a.String() + " * " + n.b.String() + ")"
+}
+
+type divNode struct{ a, b Node }
+
+func (n divNode) Eval(x []float64) float64 {
+	return n.a.Eval(x) / n.b.Eval(x)
+}
+
+func (n divNode) Deriv(i int) Node {
+	// (a' b - a b') / b^2
+	return div(sub(mul(n.a.Deriv(i), n.b), mul(n.a, n.b.Deriv(i))), pow(n.b, Const(2)))
+}
+
+func (n divNode) String() string {
+	return "(" + n.a.String() + " / " + n.b.String() + ")"
+}
+
+type powNode struct{ a, b Node }
+
+func (n powNode) Eval(x []float64) float64 {
+	return math.Pow(n.a.Eval(x), n.b.Eval(x))
+}
+
+func (n powNode) Deriv(i int) Node {
+	da := n.a.Deriv(i)
+	db := n.b.Deriv(i)
+	if c, ok := n.b.(Const); ok {
+		// c a^(c-1) a'
+		return mul(mul(c, pow(n.a, c-1)), da)
+	}
+	// The log(a) term is written as xlogy(a^b, a) so that it vanishes
+	// where a^b does, keeping the derivative finite at a zero base.
+	if isConst(da, 0) {
+		// a^b log(a) b'
+		return mul(xlogyNode{n, n.a}, db)
+	}
+	// b a^(b-1) a' + a^b log(a) b'
+	return add(mul(mul(n.b, pow(n.a, sub(n.b, Const(1)))), da), mul(xlogyNode{n, n.a}, db))
+}
+
+func (n powNode) String() string {
+	return "(" + n.a.String() + " ^ " + n.b.String() + ")"
+}
+
+// xlogyNode is a * log(b), defined to be zero wherever a is zero
+type xlogyNode struct{ a, b Node }
+
+func (n xlogyNode) Eval(x []float64) float64 {
+	a := n.a.Eval(x)
+	if a == 0 {
+		return 0
+	}
+	return a * math.Log(n.b.Eval(x))
+}
+
+func (n xlogyNode) Deriv(i int) Node {
+	// a' log(b) + a b' / b
+	return add(xlogyNode{n.a.Deriv(i), n.b}, div(mul(n.a, n.b.Deriv(i)), n.b))
+}
+
+func (n xlogyNode) String() string {
+	return "(" + n.a.String() + " * log(" + n.b.String() + "))"
+}
+
+// functions are the functions of one argument which can be called
+var functions = map[string]func(float64) float64{
+	"sin":  math.Sin,
+	"cos":  math.Cos,
+	"tan":  math.Tan,
+	"exp":  math.Exp,
+	"log":  math.Log,
+	"sqrt": math.Sqrt,
+	"abs":  math.Abs,
+	"sign": sign,
+}
+
+func sign(v float64) float64 {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return v
+}
+
+type callNode struct {
+	name string
+	a    Node
+}
+
+func (n callNode) Eval(x []float64) float64 {
+	return functions[n.name](n.a.Eval(x))
+}
+
+func (n callNode) Deriv(i int) Node {
+	da := n.a.Deriv(i)
+	if isConst(da, 0) {
+		return Const(0)
+	}
+	var d Node
+	switch n.name {
+	case "sin":
+		d = callNode{"cos", n.a}
+	case "cos":
+		d = neg(callNode{"sin", n.a})
+	case "tan":
+		d = add(Const(1), pow(n, Const(2)))
+	case "exp":
+		d = n
+	case "log":
+		d = div(Const(1), n.a)
+	case "sqrt":
+		d = div(Const(0.5), n)
+	case "abs":
+		d = callNode{"sign", n.a}
+	case "sign":
+		d = Const(0)
+	}
+	return mul(d, da)
+}
+
+func (n callNode) String() string {
+	return n.name + "(" + n.a.String() + ")"
+}
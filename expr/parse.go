@@ -0,0 +1,256 @@
+// Package expr parses arithmetic expressions of the variables x0, x1, ...
+// and differentiates them symbolically, so that objective functions can be
+// given as strings like "(1-x0)^2 + 100*(x1-x0^2)^2".
+//
+// Expressions are made of numbers, the variables, the constants pi and e,
+// the operators + - * / and ^ (with the usual precedence, ^ binding to the
+// right and before unary minus), parentheses, and the functions sin, cos,
+// tan, exp, log, sqrt, abs and sign of one argument. Variable indices are
+// limited to MaxVar
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"unicode"
+)
+
+// MaxVar is the largest index of a variable accepted by Parse. A Function
+// keeps one gradient expression per index up to the largest one used, so
+// the bound keeps a name like x100000000 from allocating without limit
+const MaxVar = 9999
+
+// Parse parses an expression
+func Parse(s string) (Node, error) {
+	p := &parser{s: s}
+	p.next()
+	n, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok != tokEOF {
+		return nil, p.errorf("unexpected %q", p.text)
+	}
+	return n, nil
+}
+
+type token int
+
+const (
+	tokEOF token = iota
+	tokNumber
+	tokIdent
+	tokOp // one of + - * / ^ ( )
+	tokError
+)
+
+type parser struct {
+	s    string
+	pos  int // position after the current token
+	tok  token
+	text string
+	at   int // position of the current token
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("expr: position %v: %v", p.at+1, fmt.Sprintf(format, args...))
+}
+
+// next reads the next token
+func (p *parser) next() {
+	for p.pos < len(p.s) && unicode.IsSpace(rune(p.s[p.pos])) {
+		p.pos++
+	}
+	p.at = p.pos
+	if p.pos == len(p.s) {
+		p.tok, p.text = tokEOF, ""
+		return
+	}
+	c := p.s[p.pos]
+	switch {
+	case c >= '0' && c <= '9' || c == '.':
+		for p.pos < len(p.s) && (p.s[p.pos] >= '0' && p.s[p.pos] <= '9' || p.s[p.pos] == '.') {
+			p.pos++
+		}
+		// Exponent
+		if p.pos < len(p.s) && (p.s[p.pos] == 'e' || p.s[p.pos] == 'E') {
+			end := p.pos + 1
+			if end < len(p.s) && (p.s[end] == '+' || p.s[end] == '-') {
+				end++
+			}
+			if end < len(p.s) && p.s[end] >= '0' && p.s[end] <= '9' {
+				for end < len(p.s) && p.s[end] >= '0' && p.s[end] <= '9' {
+					end++
+				}
+				p.pos = end
+			}
+		}
+		p.tok = tokNumber
+	case c == '_' || unicode.IsLetter(rune(c)):
+		for p.pos < len(p.s) && (p.s[p.pos] == '_' || unicode.IsLetter(rune(p.s[p.pos])) || unicode.IsDigit(rune(p.s[p.pos]))) {
+			p.pos++
+		}
+		p.tok = tokIdent
+	case c == '+' || c == '-' || c == '*' || c == '/' || c == '^' || c == '(' || c == ')':
+		p.pos++
+		p.tok = tokOp
+	default:
+		p.pos++
+		p.tok = tokError
+	}
+	p.text = p.s[p.at:p.pos]
+}
+
+func (p *parser) isOp(op string) bool {
+	return p.tok == tokOp && p.text == op
+}
+
+// expr parses a sum of terms
+func (p *parser) expr() (Node, error) {
+	n, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+") || p.isOp("-") {
+		op := p.text
+		p.next()
+		m, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		if op == "+" {
+			n = add(n, m)
+		} else {
+			n = sub(n, m)
+		}
+	}
+	return n, nil
+}
+
+// term parses a product of factors
+func (p *parser) term() (Node, error) {
+	n, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("*") || p.isOp("/") {
+		op := p.text
+		p.next()
+		m, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if op == "*" {
+			n = mul(n, m)
+		} else {
+			n = div(n, m)
+		}
+	}
+	return n, nil
+}
+
+// unary parses a signed power
+func (p *parser) unary() (Node, error) {
+	if p.isOp("-") || p.isOp("+") {
+		op := p.text
+		p.next()
+		n, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if op == "-" {
+			return neg(n), nil
+		}
+		return n, nil
+	}
+	return p.power()
+}
+
+// power parses a primary raised to a (right associative) power
+func (p *parser) power() (Node, error) {
+	n, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOp("^") {
+		p.next()
+		m, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		n = pow(n, m)
+	}
+	return n, nil
+}
+
+func (p *parser) primary() (Node, error) {
+	switch {
+	case p.tok == tokNumber:
+		v, err := strconv.ParseFloat(p.text, 64)
+		if err != nil {
+			return nil, p.errorf("bad number %q", p.text)
+		}
+		p.next()
+		return Const(v), nil
+	case p.tok == tokIdent:
+		name, at := p.text, p.at
+		p.next()
+		if _, ok := functions[name]; ok {
+			if !p.isOp("(") {
+				return nil, p.errorf("expected ( after %v", name)
+			}
+			a, err := p.paren()
+			if err != nil {
+				return nil, err
+			}
+			return call(name, a), nil
+		}
+		switch name {
+		case "pi":
+			return Const(math.Pi), nil
+		case "e":
+			return Const(math.E), nil
+		}
+		if len(name) > 1 && name[0] == 'x' {
+			i, err := strconv.Atoi(name[1:])
+			if err == nil && i >= 0 {
+				if i > MaxVar {
+					p.at = at
+					return nil, p.errorf("variable %v has an index above %v", name, MaxVar)
+				}
+				return Var(i), nil
+			}
+		}
+		p.at = at
+		return nil, p.errorf("unknown name %q", name)
+	case p.isOp("("):
+		return p.paren()
+	case p.tok == tokEOF:
+		return nil, p.errorf("unexpected end of expression")
+	}
+	return nil, p.errorf("unexpected %q", p.text)
+}
+
+// paren parses an expression in parentheses
+func (p *parser) paren() (Node, error) {
+	p.next()
+	n, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isOp(")") {
+		return nil, p.errorf("expected )")
+	}
+	p.next()
+	return n, nil
+}
+
+// call returns the node calling the function, evaluated if the argument
+// is constant
+func call(name string, a Node) Node {
+	if c, ok := a.(Const); ok {
+		return Const(functions[name](float64(c)))
+	}
+	return callNode{name, a}
+}
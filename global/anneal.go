@@ -0,0 +1,322 @@
+package global
+
+import (
+	"github.com/btracey/gofunopter/common"
+	"github.com/btracey/gofunopter/common/display"
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/projection"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// SimulatedAnnealing is the method of Kirkpatrick, Gelatt and Vecchi (1983).
+// Each call to Iterate takes Steps Metropolis steps at the current
+// temperature: a neighbour of the current location is proposed and is
+// accepted if it is better, or with probability exp(-delta / T) if it is
+// worse. The temperature is then updated by the Cooling schedule.
+// On continuous problems it is used with OptimizeObj and proposals come
+// from Neighbour (and are moved onto the bounds, if set). Problems with
+// discrete (or any other) states can be annealed with AnnealState.
+// The best objective value after every iteration and the fraction of
+// proposals accepted during every iteration are kept, and can be found from
+// BestTrace and Acceptance (or in the AnnealResult from AnnealState)
+type SimulatedAnnealing struct {
+	// Tunable parameters
+	Cooling   Cooling
+	Neighbour Neighbour // Proposal distribution for continuous problems
+	Steps     int       // Number of proposals per iteration
+	Seed      int64     // Seed for the random number generator
+
+	// Other needed variables
+	rng         *rand.Rand
+	iter        int
+	temperature float64
+	curr        float64 // objective value of the current (not best) location
+	best        float64
+	x           []float64
+	xTrial      []float64
+	bounds      *projection.Box
+	bestTrace   []float64
+	acceptance  []float64
+}
+
+// NewSimulatedAnnealing returns a new simulated annealing optimizer with
+// an exponential cooling schedule and Gaussian proposals
+func NewSimulatedAnnealing() *SimulatedAnnealing {
+	return &SimulatedAnnealing{
+		Cooling:   Exponential{Initial: 1, Rate: 0.95},
+		Neighbour: Gaussian{Sigma: 0.1},
+		Steps:     100,
+		Seed:      1,
+	}
+}
+
+// initialize resets the annealing state at the start of an optimization
+func (sa *SimulatedAnnealing) initialize(f float64) error {
+	if sa.Cooling == nil {
+		return errors.New("anneal: cooling schedule is nil")
+	}
+	if sa.Steps <= 0 {
+		return errors.New("anneal: number of steps must be positive")
+	}
+	sa.rng = rand.New(rand.NewSource(sa.Seed))
+	sa.iter = 0
+	sa.temperature = sa.Cooling.Temperature(0, math.NaN())
+	sa.curr = f
+	sa.best = f
+	sa.bestTrace = nil
+	sa.acceptance = nil
+	return nil
+}
+
+// metropolis returns true if a proposal with objective value f should
+// replace the current location
+func (sa *SimulatedAnnealing) metropolis(f float64) bool {
+	if math.IsNaN(f) {
+		return false
+	}
+	if !optimize.Less(sa.curr, f) {
+		return true
+	}
+	if sa.temperature <= 0 {
+		return false
+	}
+	return sa.rng.Float64() < math.Exp(-(f-sa.curr)/sa.temperature)
+}
+
+// anneal takes the Metropolis steps of one iteration. propose evaluates a
+// new candidate, accept moves to it, and improve records it as the best
+func (sa *SimulatedAnnealing) anneal(propose func() (float64, error), accept, improve func()) (status.Status, error) {
+	var accepted, steps int
+	for k := 0; k < sa.Steps; k++ {
+		f, err := propose()
+		if err != nil {
+			sa.record(accepted, steps)
+			return optimize.EvalStatus("anneal", err)
+		}
+		steps++
+		if !sa.metropolis(f) {
+			continue
+		}
+		sa.curr = f
+		accept()
+		accepted++
+		if optimize.Less(f, sa.best) {
+			sa.best = f
+			improve()
+		}
+	}
+	rate := sa.record(accepted, steps)
+	sa.iter++
+	sa.temperature = sa.Cooling.Temperature(sa.iter, rate)
+	return status.Continue, nil
+}
+
+// record adds the best objective value and acceptance rate to the history
+func (sa *SimulatedAnnealing) record(accepted, steps int) float64 {
+	if steps == 0 {
+		return math.NaN()
+	}
+	rate := float64(accepted) / float64(steps)
+	sa.bestTrace = append(sa.bestTrace, sa.best)
+	sa.acceptance = append(sa.acceptance, rate)
+	return rate
+}
+
+func (sa *SimulatedAnnealing) Initialize(loc *multi.Location, obj *uni.Objective, bounds *projection.Box) error {
+	if sa.Neighbour == nil {
+		return errors.New("anneal: neighbour is nil")
+	}
+	sa.bounds = bounds
+	sa.x = make([]float64, len(loc.Init()))
+	sa.xTrial = make([]float64, len(loc.Init()))
+	copy(sa.x, loc.Curr())
+	return sa.initialize(obj.Curr())
+}
+
+func (sa *SimulatedAnnealing) Iterate(loc *multi.Location, obj *uni.Objective, fun optimize.MultiObj) (status.Status, error) {
+	propose := func() (float64, error) {
+		sa.Neighbour.Neighbour(sa.xTrial, sa.x, sa.temperature, sa.rng)
+		if sa.bounds != nil {
+			sa.bounds.Project(sa.xTrial)
+		}
+		return fun.Objective(sa.xTrial)
+	}
+	accept := func() {
+		copy(sa.x, sa.xTrial)
+	}
+	improve := func() {
+		loc.SetCurr(sa.x)
+		obj.SetCurr(sa.best)
+	}
+	return sa.anneal(propose, accept, improve)
+}
+
+// Temperature returns the current temperature
+func (sa *SimulatedAnnealing) Temperature() float64 {
+	return sa.temperature
+}
+
+// BestTrace returns the best objective value found by the end of each
+// iteration
+func (sa *SimulatedAnnealing) BestTrace() []float64 {
+	return sa.bestTrace
+}
+
+// Acceptance returns the fraction of proposals accepted during each
+// iteration
+func (sa *SimulatedAnnealing) Acceptance() []float64 {
+	return sa.acceptance
+}
+
+func (sa *SimulatedAnnealing) AddToDisplay(d []*display.Struct) []*display.Struct {
+	d = append(d, &display.Struct{Value: sa.temperature, Heading: "Temp"})
+	if n := len(sa.acceptance); n > 0 {
+		d = append(d, &display.Struct{Value: sa.acceptance[n-1], Heading: "Accept"})
+	}
+	return d
+}
+
+// State is a location in a problem which is not over []float64 (for example
+// an ordering of cities or a set of flags) for use with AnnealState.
+// Neighbour returns a new state near the receiver, and must not modify the
+// receiver
+type State interface {
+	Energy() (float64, error)
+	Neighbour(rng *rand.Rand) State
+}
+
+// AnnealState minimizes the energy of a State with simulated annealing.
+// Every call to Energy counts as a function evaluation. The Neighbour field
+// of sa is not used
+func AnnealState(initial State, settings *AnnealSettings, sa *SimulatedAnnealing) (optValue float64, optState State, result *AnnealResult, err error) {
+	if settings == nil {
+		settings = NewAnnealSettings()
+	}
+	if sa == nil {
+		sa = NewSimulatedAnnealing()
+	}
+	m := &annealStruct{
+		OptCommon: common.NewOptCommon(),
+		obj:       uni.NewObjective(),
+		settings:  settings,
+		sa:        sa,
+		initial:   initial,
+	}
+	err = optimize.OptimizeOpter(m, initial)
+	return m.obj.Opt(), m.best, m.Result(), err
+}
+
+type AnnealResult struct {
+	*common.CommonResult
+	*uni.ObjectiveResult
+	BestTrace  []float64 // Best energy at the end of each iteration
+	Acceptance []float64 // Fraction of proposals accepted during each iteration
+}
+
+type AnnealSettings struct {
+	*common.CommonSettings
+	*uni.ObjectiveSettings
+}
+
+func NewAnnealSettings() *AnnealSettings {
+	return &AnnealSettings{
+		CommonSettings:    common.NewCommonSettings(),
+		ObjectiveSettings: uni.NewObjectiveSettings(),
+	}
+}
+
+type annealStruct struct {
+	*common.OptCommon
+
+	obj *uni.Objective
+
+	initial State
+	curr    State
+	best    State
+
+	sa       *SimulatedAnnealing
+	settings *AnnealSettings
+}
+
+func (m *annealStruct) CommonSettings() *common.CommonSettings {
+	return m.settings.CommonSettings
+}
+
+func (m *annealStruct) SetSettings() error {
+	m.obj.SetSettings(m.settings.ObjectiveSettings)
+	return nil
+}
+
+// energy evaluates the state, respecting the function evaluation limit
+func (m *annealStruct) energy(s State) (float64, error) {
+	if m.FunEvals.Curr() >= m.FunEvals.Max() {
+		return math.NaN(), optimize.ErrMaximumFunctionEvaluations
+	}
+	f, err := s.Energy()
+	m.obj.AddToHist(f)
+	m.FunEvals.Add(1)
+	return f, err
+}
+
+func (m *annealStruct) Initialize() error {
+	if m.initial == nil {
+		return errors.New("anneal: initial state is nil")
+	}
+	if math.IsNaN(m.obj.Init()) {
+		f, err := m.energy(m.initial)
+		if err != nil {
+			return errors.New("error calling function during optimization: \n" + err.Error())
+		}
+		m.obj.SetInit(f)
+	}
+	err := m.obj.Initialize()
+	if err != nil {
+		return err
+	}
+	m.curr = m.initial
+	m.best = m.initial
+	return m.sa.initialize(m.obj.Curr())
+}
+
+func (m *annealStruct) Iterate() (status.Status, error) {
+	var trial State
+	propose := func() (float64, error) {
+		trial = m.curr.Neighbour(m.sa.rng)
+		return m.energy(trial)
+	}
+	accept := func() {
+		m.curr = trial
+	}
+	improve := func() {
+		m.best = trial
+		m.obj.SetCurr(m.sa.best)
+	}
+	return m.sa.anneal(propose, accept, improve)
+}
+
+func (m *annealStruct) Status() status.Status {
+	return status.CheckStatus(m.obj)
+}
+
+func (m *annealStruct) AddToDisplay(d []*display.Struct) []*display.Struct {
+	return display.AddToDisplay(d, m.obj, m.sa)
+}
+
+func (m *annealStruct) Result() *AnnealResult {
+	return &AnnealResult{
+		CommonResult:    m.OptCommon.CommonResult(),
+		ObjectiveResult: m.obj.Result(),
+		BestTrace:       m.sa.bestTrace,
+		Acceptance:      m.sa.acceptance,
+	}
+}
+
+func (m *annealStruct) SetResult() {
+	optimize.SetResult(m.obj)
+}
@@ -0,0 +1,227 @@
+package global
+
+import (
+	"github.com/btracey/gofunopter/common"
+	"github.com/btracey/gofunopter/common/display"
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+	"github.com/btracey/gofunopter/multivariate"
+
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// BasinHopping is the method of Wales and Doye (1997). Each iteration
+// perturbs the current local minimum by a uniform random step in every
+// coordinate, runs a local optimization with multivariate.OptimizeGrad from
+// the perturbed location, and accepts the new local minimum with the
+// Metropolis criterion at Temperature. Every AdaptInterval hops the step
+// size is adjusted towards the TargetAcceptance rate.
+// BasinHopping is a multivariate.MultiGradOptimizer and is run with
+// multivariate.OptimizeGrad. The location, objective and gradient are
+// those of the best local minimum found. The gradient tolerance is not used
+// (every local minimum satisfies it). The optimization ends with
+// status.StagnationTol if MaxStagnation hops in a row do not improve on the
+// best local minimum, or when the iteration or evaluation limits are
+// reached. A local optimization which fails (for example in the
+// linesearch, or by running out of function evaluations) provides the best
+// location it evaluated instead of a local minimum. If it evaluated none,
+// the hop is rejected, and for the first local optimization hopping starts
+// from the initial location
+type BasinHopping struct {
+	// Tunable parameters
+	StepSize         float64 // Initial maximum perturbation in each coordinate
+	Temperature      float64 // Temperature for the Metropolis criterion
+	AdaptInterval    int     // Number of hops between step size updates (0 for no adaptation)
+	TargetAcceptance float64 // Desired fraction of accepted hops
+	StepFactor       float64 // Multiplier on the step size when the acceptance is below the target
+	MaxStagnation    int     // Number of hops without improvement before stopping (0 for no limit)
+	Seed             int64   // Seed for the random number generator
+
+	// NewOptimizer returns a new optimizer for each local optimization.
+	// If nil, Lbfgs is used
+	NewOptimizer func() multivariate.MultiGradOptimizer
+
+	// LocalSettings are the settings for each local optimization.
+	// If nil, the defaults are used with the display off
+	LocalSettings *multivariate.MultiGradSettings
+
+	// Other needed variables
+	rng        *rand.Rand
+	first      bool
+	step       float64
+	x          []float64 // current local minimum
+	fx         float64
+	xTrial     []float64
+	hops       int
+	accepted   int // accepted hops since the last step size update
+	sinceAdapt int
+	stagnation int
+	settings   *multivariate.MultiGradSettings
+}
+
+// NewBasinHopping returns a new basin hopping optimizer using Lbfgs for
+// the local optimizations
+func NewBasinHopping() *BasinHopping {
+	return &BasinHopping{
+		StepSize:         0.5,
+		Temperature:      1,
+		AdaptInterval:    50,
+		TargetAcceptance: 0.5,
+		StepFactor:       0.9,
+		Seed:             1,
+	}
+}
+
+func (b *BasinHopping) Initialize(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient) error {
+	if b.StepSize <= 0 {
+		return errors.New("basinhopping: step size must be positive")
+	}
+	if b.Temperature < 0 {
+		return errors.New("basinhopping: temperature must be non-negative")
+	}
+	if b.AdaptInterval > 0 && (b.StepFactor <= 0 || b.StepFactor >= 1) {
+		return errors.New("basinhopping: step factor must be between zero and one")
+	}
+	// The outer gradient is always that of a local minimum
+	grad.SetAbsTol(0)
+
+	b.settings = b.LocalSettings
+	if b.settings == nil {
+		b.settings = multivariate.NewMultiGradSettings()
+		b.settings.Display = false
+	}
+	b.rng = rand.New(rand.NewSource(b.Seed))
+	b.first = true
+	b.step = b.StepSize
+	nDim := len(loc.Init())
+	b.x = make([]float64, nDim)
+	b.xTrial = make([]float64, nDim)
+	copy(b.x, loc.Curr())
+	b.fx = obj.Curr()
+	b.hops = 0
+	b.accepted = 0
+	b.sinceAdapt = 0
+	b.stagnation = 0
+	return nil
+}
+
+// localMinimize runs a local optimization from x. The location of the
+// local minimum (or the best location evaluated if the local optimization
+// fails) is stored in xTrial. The gradient is nil if no location was
+// evaluated. The local optimization is limited to the function evaluations
+// remaining in the outer optimization, including those made during a
+// linesearch
+func (b *BasinHopping) localMinimize(x []float64, fun optimize.MultiObjGrad) (f float64, g []float64) {
+	var optimizer multivariate.MultiGradOptimizer
+	if b.NewOptimizer != nil {
+		optimizer = b.NewOptimizer()
+	} else {
+		optimizer = multivariate.NewLbfgs()
+	}
+	settings := b.settings
+	if r, ok := fun.(optimize.RemainingEvaluator); ok {
+		if remaining := r.RemainingEvaluations(); remaining < settings.MaximumFunctionEvaluations {
+			s := *b.settings
+			commonSettings := *s.CommonSettings
+			commonSettings.MaximumFunctionEvaluations = remaining
+			s.CommonSettings = &commonSettings
+			settings = &s
+		}
+	}
+	limited := &optimize.LimitedMultiObjGrad{
+		Fun:      fun,
+		FunEvals: common.NewFunctionEvaluations(),
+	}
+	limited.FunEvals.SetMax(settings.MaximumFunctionEvaluations)
+	f, optLoc, result, _ := multivariate.OptimizeGrad(limited, x, settings, optimizer)
+	if math.IsNaN(f) || len(optLoc) != len(x) || len(result.Gradient) != len(x) {
+		return math.NaN(), nil
+	}
+	copy(b.xTrial, optLoc)
+	return f, result.Gradient
+}
+
+func (b *BasinHopping) Iterate(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient, fun optimize.MultiObjGrad) (status.Status, error) {
+	if r, ok := fun.(optimize.RemainingEvaluator); ok && r.RemainingEvaluations() <= 0 {
+		return status.MaximumFunctionEvaluations, nil
+	}
+	if b.first {
+		// Start from the local minimum nearest the initial location
+		b.first = false
+		f, g := b.localMinimize(b.x, fun)
+		if g == nil {
+			return status.Continue, nil
+		}
+		copy(b.x, b.xTrial)
+		b.fx = f
+		if !optimize.Less(obj.Curr(), f) {
+			loc.SetCurr(b.x)
+			obj.SetCurr(f)
+			grad.SetCurr(g)
+		}
+		return status.Continue, nil
+	}
+
+	for i := range b.xTrial {
+		b.xTrial[i] = b.x[i] + b.step*(2*b.rng.Float64()-1)
+	}
+	f, g := b.localMinimize(b.xTrial, fun)
+	b.hops++
+	b.sinceAdapt++
+	b.stagnation++
+	if g != nil && b.metropolis(f) {
+		copy(b.x, b.xTrial)
+		b.fx = f
+		b.accepted++
+		if optimize.Less(f, obj.Curr()) {
+			loc.SetCurr(b.x)
+			obj.SetCurr(f)
+			grad.SetCurr(g)
+			b.stagnation = 0
+		}
+	}
+
+	if b.AdaptInterval > 0 && b.sinceAdapt == b.AdaptInterval {
+		if float64(b.accepted)/float64(b.sinceAdapt) > b.TargetAcceptance {
+			b.step /= b.StepFactor
+		} else {
+			b.step *= b.StepFactor
+		}
+		b.accepted = 0
+		b.sinceAdapt = 0
+	}
+	return status.Continue, nil
+}
+
+// metropolis returns true if a new local minimum with value f should
+// replace the current one
+func (b *BasinHopping) metropolis(f float64) bool {
+	if math.IsNaN(f) {
+		return false
+	}
+	if !optimize.Less(b.fx, f) {
+		return true
+	}
+	if b.Temperature == 0 {
+		return false
+	}
+	return b.rng.Float64() < math.Exp(-(f-b.fx)/b.Temperature)
+}
+
+func (b *BasinHopping) Status() status.Status {
+	if b.MaxStagnation > 0 && b.stagnation >= b.MaxStagnation {
+		return status.StagnationTol
+	}
+	return status.Continue
+}
+
+func (b *BasinHopping) AddToDisplay(d []*display.Struct) []*display.Struct {
+	return append(d,
+		&display.Struct{Value: b.step, Heading: "HopStep"},
+		&display.Struct{Value: b.fx, Heading: "CurrMin"},
+	)
+}
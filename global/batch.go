@@ -0,0 +1,118 @@
+package global
+
+import (
+	"github.com/btracey/gofunopter/common/optimize"
+
+	"errors"
+	"sync"
+)
+
+// ObjBatch evaluates every location in xs. If the user defined function
+// implements optimize.MultiObjBatch it is called with all of the locations,
+// otherwise the locations are evaluated by the number of goroutines given by
+// the Concurrency setting. Only as many locations as remain in the function
+// evaluation budget are evaluated. The values of the locations evaluated
+// (in order, up to the first error) are returned
+func (m *moddedFun) ObjBatch(xs [][]float64) (objs []float64, err error) {
+	n := len(xs)
+	if remaining := m.FunEvals.Max() - m.FunEvals.Curr(); remaining < n {
+		n = remaining
+		if n < 0 {
+			n = 0
+		}
+		err = optimize.ErrMaximumFunctionEvaluations
+	}
+	if n == 0 {
+		return nil, err
+	}
+	xs = xs[:n]
+
+	var evalErr error
+	batcher, isBatcher := m.Fun.(optimize.MultiObjBatch)
+	switch {
+	case isBatcher:
+		objs, evalErr = batcher.ObjBatch(xs)
+		if evalErr == nil && len(objs) != n {
+			evalErr = errors.New("global: user defined batch function returned incorrect length")
+		}
+		if evalErr != nil {
+			return nil, evalErr
+		}
+		m.FunEvals.Add(n)
+	case m.workers <= 1:
+		objs = make([]float64, 0, n)
+		for _, x := range xs {
+			f, fErr := m.Fun.Objective(x)
+			m.FunEvals.Add(1)
+			if fErr != nil {
+				evalErr = fErr
+				break
+			}
+			objs = append(objs, f)
+		}
+	default:
+		objs, evalErr = m.concurrent(xs)
+	}
+
+	for i, f := range objs {
+		m.Loc.AddToHist(xs[i])
+		m.Obj.AddToHist(f)
+	}
+	if evalErr != nil {
+		return objs, evalErr
+	}
+	return objs, err
+}
+
+// concurrent evaluates the locations with a pool of goroutines
+func (m *moddedFun) concurrent(xs [][]float64) ([]float64, error) {
+	objs := make([]float64, len(xs))
+	errs := make([]error, len(xs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	workers := m.workers
+	if workers > len(xs) {
+		workers = len(xs)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				objs[i], errs[i] = m.Fun.Objective(xs[i])
+				m.FunEvals.Add(1)
+			}
+		}()
+	}
+	for i := range xs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return objs[:i], err
+		}
+	}
+	return objs, nil
+}
+
+// evaluateBatch evaluates the locations with fun, all at once if fun
+// supports it. The values of the locations evaluated (in order, up to the
+// first error) are returned
+func evaluateBatch(fun optimize.MultiObj, xs [][]float64) ([]float64, error) {
+	batcher, ok := fun.(optimize.MultiObjBatch)
+	if ok {
+		return batcher.ObjBatch(xs)
+	}
+	objs := make([]float64, 0, len(xs))
+	for _, x := range xs {
+		f, err := fun.Objective(x)
+		if err != nil {
+			return objs, err
+		}
+		objs = append(objs, f)
+	}
+	return objs, nil
+}
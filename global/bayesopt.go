@@ -0,0 +1,369 @@
+package global
+
+import (
+	"github.com/btracey/gofunopter/common/display"
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/projection"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+	"github.com/btracey/gofunopter/multivariate"
+
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Acquisition scores a location from the prediction of the surrogate
+// model. Acquire returns the value to be minimized given the predicted mean
+// and standard deviation of the objective and the best objective value
+// observed, and the derivatives of the value with respect to the mean and
+// the standard deviation. The values are standardized by the mean and
+// standard deviation of the observations
+type Acquisition interface {
+	Acquire(mean, std, best float64) (a, dMean, dStd float64)
+}
+
+// ExpectedImprovement is the expected amount by which the objective is
+// less than best - Xi (Jones et al., 1998). A positive Xi favours
+// exploration
+type ExpectedImprovement struct {
+	Xi float64
+}
+
+func (e ExpectedImprovement) Acquire(mean, std, best float64) (a, dMean, dStd float64) {
+	imp := best - mean - e.Xi
+	if std <= 0 {
+		if imp > 0 {
+			return -imp, 1, 0
+		}
+		return 0, 0, 0
+	}
+	z := imp / std
+	cdf := 0.5 * math.Erfc(-z/math.Sqrt2)
+	pdf := math.Exp(-z*z/2) / math.Sqrt(2*math.Pi)
+	return -(imp*cdf + std*pdf), cdf, -pdf
+}
+
+// UpperConfidenceBound is the confidence bound criterion of Srinivas et
+// al. (2010). As the objective is minimized, the lower bound
+// mean - Beta * std is used. Larger Beta favours exploration
+type UpperConfidenceBound struct {
+	Beta float64
+}
+
+func (u UpperConfidenceBound) Acquire(mean, std, best float64) (a, dMean, dStd float64) {
+	return mean - u.Beta*std, 1, -u.Beta
+}
+
+// BayesianOptimization is a surrogate-based method for expensive
+// objective functions (Jones et al., 1998). A Gaussian process model with
+// Kernel is fitted to all of the objective values, and each iteration
+// evaluates the location which minimizes the Acquisition. The acquisition
+// is evaluated at Candidates random locations, and the best LocalStarts of
+// them are refined with Lbfgs. The hyperparameters of the model are refitted
+// every iteration with Lbfgs.
+// The bounds must be finite, and the locations are scaled to the unit cube
+// by them. The first iteration evaluates an initial design of
+// InitialSamples locations (including the initial location) drawn by
+// Sampler. Each later iteration evaluates the objective once, and costs
+// O(n^3) in the number of evaluations, so the method is intended for
+// budgets of tens to a few hundred evaluations set with
+// MaximumFunctionEvaluations. Non-finite objective values are modeled as
+// the largest finite value observed
+type BayesianOptimization struct {
+	// Tunable parameters
+	Kernel         Kernel
+	Acquisition    Acquisition
+	InitialSamples int     // Size of the initial design (if zero, 2 * (n + 1))
+	Sampler        Sampler // Method for generating the initial design
+	Candidates     int     // Number of random locations at which the acquisition is evaluated
+	LocalStarts    int     // Number of the best candidates refined by local optimization
+	Seed           int64   // Seed for the random number generator
+
+	// Other needed variables
+	rng         *rand.Rand
+	bounds      *projection.Box
+	nDim        int
+	first       bool
+	design      [][]float64
+	x           [][]float64 // evaluated locations scaled to the unit cube
+	y           []float64
+	gp          *gaussianProcess
+	fitted      bool
+	acq         float64 // acquisition value of the last location evaluated
+	fitSettings *multivariate.MultiGradSettings
+	acqSettings *multivariate.MultiGradSettings
+}
+
+// NewBayesianOptimization returns a Bayesian optimizer using a Matérn 5/2
+// kernel and expected improvement
+func NewBayesianOptimization() *BayesianOptimization {
+	return &BayesianOptimization{
+		Kernel:      Matern52{},
+		Acquisition: ExpectedImprovement{Xi: 0.01},
+		Sampler:     LatinHypercube{},
+		Candidates:  1000,
+		LocalStarts: 5,
+		Seed:        1,
+	}
+}
+
+func (b *BayesianOptimization) Initialize(loc *multi.Location, obj *uni.Objective, bounds *projection.Box) error {
+	if bounds == nil {
+		return errors.New("bayesopt: bounds must be set")
+	}
+	for i := range bounds.Lower {
+		if math.IsInf(bounds.Lower[i], 0) || math.IsInf(bounds.Upper[i], 0) {
+			return errors.New("bayesopt: bounds must be finite")
+		}
+	}
+	if b.Kernel == nil {
+		return errors.New("bayesopt: kernel is nil")
+	}
+	if b.Acquisition == nil {
+		return errors.New("bayesopt: acquisition is nil")
+	}
+	if b.Sampler == nil {
+		return errors.New("bayesopt: sampler is nil")
+	}
+	if b.Candidates <= 0 {
+		return errors.New("bayesopt: number of candidates must be positive")
+	}
+	if b.LocalStarts < 0 || b.LocalStarts > b.Candidates {
+		return errors.New("bayesopt: number of local starts must be between zero and the number of candidates")
+	}
+	b.bounds = bounds
+	b.nDim = len(loc.Init())
+	b.rng = rand.New(rand.NewSource(b.Seed))
+
+	nInit := b.InitialSamples
+	if nInit == 0 {
+		nInit = 2 * (b.nDim + 1)
+	}
+	if nInit < 1 {
+		return errors.New("bayesopt: initial samples must be positive")
+	}
+	b.design = make([][]float64, nInit-1)
+	for i := range b.design {
+		b.design[i] = make([]float64, b.nDim)
+	}
+	err := b.Sampler.Sample(b.design, bounds, b.rng)
+	if err != nil {
+		return errors.New("bayesopt: " + err.Error())
+	}
+
+	b.x = nil
+	b.y = nil
+	b.observe(loc.Curr(), obj.Curr())
+	b.gp = newGaussianProcess(b.Kernel, b.nDim)
+	b.fitted = false
+	b.acq = math.NaN()
+	b.first = true
+
+	b.fitSettings = multivariate.NewMultiGradSettings()
+	b.fitSettings.Display = false
+	b.fitSettings.MaximumIterations = 50
+	b.acqSettings = multivariate.NewMultiGradSettings()
+	b.acqSettings.Display = false
+	b.acqSettings.MaximumIterations = 50
+	return nil
+}
+
+// observe records the objective value at x
+func (b *BayesianOptimization) observe(x []float64, f float64) {
+	b.x = append(b.x, b.scale(x))
+	b.y = append(b.y, f)
+}
+
+// scale returns the location in the unit cube corresponding to x
+func (b *BayesianOptimization) scale(x []float64) []float64 {
+	u := make([]float64, len(x))
+	for i, v := range x {
+		u[i] = v - b.bounds.Lower[i]
+		if width := b.bounds.Upper[i] - b.bounds.Lower[i]; width > 0 {
+			u[i] /= width
+		}
+	}
+	return u
+}
+
+// unscale stores the location in the bounds corresponding to u in dst
+func (b *BayesianOptimization) unscale(dst, u []float64) {
+	for i, v := range u {
+		lo, hi := b.bounds.Lower[i], b.bounds.Upper[i]
+		dst[i] = lo + v*(hi-lo)
+	}
+}
+
+func (b *BayesianOptimization) Iterate(loc *multi.Location, obj *uni.Objective, fun optimize.MultiObj) (status.Status, error) {
+	if b.first {
+		fs, err := evaluateBatch(fun, b.design)
+		for i, f := range fs {
+			b.observe(b.design[i], f)
+			if optimize.Less(f, obj.Curr()) {
+				loc.SetCurr(b.design[i])
+				obj.SetCurr(f)
+			}
+		}
+		if err != nil {
+			return optimize.EvalStatus("bayesopt", err)
+		}
+		b.first = false
+		return status.Continue, nil
+	}
+
+	u, err := b.propose()
+	if err != nil {
+		return status.OptimizerError, err
+	}
+	x := make([]float64, b.nDim)
+	b.unscale(x, u)
+	f, err := fun.Objective(x)
+	if err != nil {
+		return optimize.EvalStatus("bayesopt", err)
+	}
+	b.observe(x, f)
+	if optimize.Less(f, obj.Curr()) {
+		loc.SetCurr(x)
+		obj.SetCurr(f)
+	}
+	return status.Continue, nil
+}
+
+// propose fits the model and returns the location in the unit cube
+// which minimizes the acquisition
+func (b *BayesianOptimization) propose() ([]float64, error) {
+	var finite int
+	for _, f := range b.y {
+		if !math.IsNaN(f) && !math.IsInf(f, 0) {
+			finite++
+		}
+	}
+	if finite < 2 {
+		// Not enough information for a model
+		u := make([]float64, b.nDim)
+		for i := range u {
+			u[i] = b.rng.Float64()
+		}
+		b.acq = math.NaN()
+		return u, nil
+	}
+
+	b.gp.setData(b.x, b.y)
+	err := b.gp.fit(b.fitSettings)
+	if err != nil {
+		return nil, errors.New("bayesopt: error fitting the surrogate model: " + err.Error())
+	}
+	b.fitted = true
+	best := math.Inf(1)
+	for _, v := range b.gp.y {
+		best = math.Min(best, v)
+	}
+	acq := &acquisitionObj{b: b, best: best}
+
+	// Rank random candidates, then refine the best of them
+	unit := &projection.Box{Lower: make([]float64, b.nDim), Upper: make([]float64, b.nDim)}
+	for i := range unit.Upper {
+		unit.Upper[i] = 1
+	}
+	candidates := make([][]float64, b.Candidates)
+	values := make([]float64, b.Candidates)
+	for i := range candidates {
+		candidates[i] = make([]float64, b.nDim)
+	}
+	Uniform{}.Sample(candidates, unit, b.rng)
+	order := make([]int, b.Candidates)
+	for i, u := range candidates {
+		values[i] = acq.value(u, nil)
+		order[i] = i
+	}
+	sort.Sort(byFitness{order, values})
+
+	bestU := candidates[order[0]]
+	bestA := values[order[0]]
+	for _, i := range order[:b.LocalStarts] {
+		// Search over the unbounded z with u = (1 - cos(pi z)) / 2
+		z := make([]float64, b.nDim)
+		for j, v := range candidates[i] {
+			z[j] = math.Acos(1-2*v) / math.Pi
+		}
+		a, zOpt, result, err := multivariate.OptimizeGrad(acq, z, b.acqSettings, multivariate.NewLbfgs())
+		if err != nil && result.Status == status.UserFunctionError {
+			// The acquisition could not be evaluated at the start, so
+			// nothing from this start can be used
+			continue
+		}
+		// Other errors end the linesearch (including a failed evaluation
+		// along it), and leave the best location evaluated without error
+		if zOpt == nil || !optimize.Less(a, bestA) {
+			continue
+		}
+		bestA = a
+		bestU = make([]float64, b.nDim)
+		for j, v := range zOpt {
+			bestU[j] = (1 - math.Cos(math.Pi*v)) / 2
+		}
+	}
+	b.acq = bestA
+	return bestU, nil
+}
+
+// acquisitionObj is the acquisition as a function of z, where the
+// location in the unit cube is u = (1 - cos(pi z)) / 2
+type acquisitionObj struct {
+	b    *BayesianOptimization
+	best float64
+}
+
+// value returns the acquisition at u. If grad is not nil it is filled
+// with the gradient with respect to u
+func (acq *acquisitionObj) value(u, grad []float64) float64 {
+	gp := acq.b.gp
+	var dMean, dVar []float64
+	if grad != nil {
+		dMean = make([]float64, len(u))
+		dVar = make([]float64, len(u))
+	}
+	mean, variance := gp.predict(u, dMean, dVar)
+	std := math.Sqrt(variance)
+	a, da, ds := acq.b.Acquisition.Acquire(mean, std, acq.best)
+	if grad != nil {
+		for i := range grad {
+			grad[i] = da * dMean[i]
+			if std > 0 {
+				grad[i] += ds * dVar[i] / (2 * std)
+			}
+		}
+	}
+	return a
+}
+
+func (acq *acquisitionObj) ObjGrad(z []float64) (f float64, grad []float64, err error) {
+	u := make([]float64, len(z))
+	for i, v := range z {
+		u[i] = (1 - math.Cos(math.Pi*v)) / 2
+	}
+	grad = make([]float64, len(z))
+	f = acq.value(u, grad)
+	for i, v := range z {
+		grad[i] *= math.Pi / 2 * math.Sin(math.Pi*v)
+	}
+	return f, grad, nil
+}
+
+// Predict returns the mean and standard deviation of the objective at x
+// predicted by the surrogate model of the last iteration. NaN is returned
+// if no model has been fitted
+func (b *BayesianOptimization) Predict(x []float64) (mean, std float64) {
+	if !b.fitted {
+		return math.NaN(), math.NaN()
+	}
+	mean, variance := b.gp.predict(b.scale(x), nil, nil)
+	return b.gp.yMean + b.gp.yStd*mean, b.gp.yStd * math.Sqrt(variance)
+}
+
+func (b *BayesianOptimization) AddToDisplay(d []*display.Struct) []*display.Struct {
+	return append(d, &display.Struct{Value: b.acq, Heading: "Acq"})
+}
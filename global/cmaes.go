@@ -0,0 +1,551 @@
+package global
+
+import (
+	"github.com/btracey/gofunopter/common/display"
+	"github.com/btracey/gofunopter/common/linalg"
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/projection"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// RestartStrategy is the method used by Cmaes to restart once a run has
+// converged
+type RestartStrategy int
+
+const (
+	NoRestart RestartStrategy = iota
+	Ipop                      // Increase the population size at every restart (Auger and Hansen, 2005)
+	Bipop                     // Alternate between large and small populations (Hansen, 2009)
+)
+
+// Cmaes is the covariance matrix adaptation evolution strategy (Hansen, 2016).
+// Each generation a population is sampled from a multivariate normal
+// distribution whose mean, covariance and step size are adapted from the
+// best members. Each call to Iterate is one generation, and the members of
+// a generation are evaluated together (see OptimizeObj).
+// A run ends with status.ObjRangeTol if the range of the recent best
+// objective values and the objective values of the current generation is
+// less than ObjRangeTol, with status.StepAbsTol if the standard deviation
+// in every coordinate is less than StepTol, with status.StagnationTol if
+// the median and best objective values have not improved over a window of
+// generations, and with status.IllConditioned if the condition number of
+// the covariance matrix is more than MaxCondition.
+// If Restart is Ipop or Bipop and fewer than MaxRestarts restarts have
+// occurred (for Bipop, only restarts in the large population regime are
+// counted), a new run is started (from a random location within the bounds,
+// if they are finite). Otherwise the optimization ends with the status of
+// the last run. The location and objective value are the best over all runs.
+// Bounds are optional, and samples outside of them are resampled and then
+// moved onto the bounds
+type Cmaes struct {
+	// Tunable parameters
+	Sigma          float64 // Initial step size (if zero, 0.3 times the mean width of finite bounds or 1 otherwise)
+	PopulationSize int     // Initial population size (if zero, 4 + floor(3 ln n))
+	Restart        RestartStrategy
+	MaxRestarts    int     // Maximum number of restarts
+	IncPopSize     float64 // Population multiplier for Ipop and the large Bipop regime
+	ObjRangeTol    float64
+	StepTol        float64
+	MaxCondition   float64
+	Seed           int64 // Seed for the random number generator
+
+	// Strategy parameters of the current run
+	nDim    int
+	lambda  int
+	mu      int
+	weights []float64
+	mueff   float64
+	cc      float64
+	cs      float64
+	c1      float64
+	cmu     float64
+	damps   float64
+	chiN    float64
+
+	// State of the current run
+	mean      []float64
+	oldMean   []float64
+	sigma     float64
+	sigma0    float64
+	pc        []float64
+	ps        []float64
+	c         [][]float64
+	b         [][]float64
+	d         []float64
+	evals     int
+	eigenEval int
+	gen       int
+	bestHist  []float64
+	medHist   []float64
+
+	// Population of the current generation
+	z     []float64
+	y     [][]float64
+	x     [][]float64
+	fit   []float64
+	order []int
+	tmp   []float64
+
+	// Restarts
+	rng           *rand.Rand
+	bounds        *projection.Box
+	initLoc       []float64
+	restarts      int
+	largeRestarts int
+	defaultLambda int
+	largeLambda   int
+	small         bool // Is the current run in the small Bipop regime
+	largeEvals    int
+	smallEvals    int
+}
+
+// NewCmaes returns a new CMA-ES optimizer with Bipop restarts
+func NewCmaes() *Cmaes {
+	return &Cmaes{
+		Restart:      Bipop,
+		MaxRestarts:  9,
+		IncPopSize:   2,
+		ObjRangeTol:  1E-12,
+		StepTol:      1E-12,
+		MaxCondition: 1E14,
+		Seed:         1,
+	}
+}
+
+func (cma *Cmaes) Initialize(loc *multi.Location, obj *uni.Objective, bounds *projection.Box) error {
+	if cma.Sigma < 0 {
+		return errors.New("cmaes: sigma must be non-negative")
+	}
+	if cma.PopulationSize < 0 {
+		return errors.New("cmaes: population size must be non-negative")
+	}
+	if cma.Restart != NoRestart && cma.IncPopSize < 1 {
+		return errors.New("cmaes: population increase must be at least one")
+	}
+	cma.nDim = len(loc.Init())
+	cma.bounds = bounds
+	cma.rng = rand.New(rand.NewSource(cma.Seed))
+	cma.initLoc = make([]float64, cma.nDim)
+	copy(cma.initLoc, loc.Curr())
+
+	cma.sigma0 = cma.Sigma
+	if cma.sigma0 == 0 {
+		cma.sigma0 = 1
+		if cma.finiteBounds() {
+			var width float64
+			for i := range bounds.Lower {
+				width += bounds.Upper[i] - bounds.Lower[i]
+			}
+			width /= float64(cma.nDim)
+			if width > 0 {
+				cma.sigma0 = 0.3 * width
+			}
+		}
+	}
+
+	cma.defaultLambda = cma.PopulationSize
+	if cma.defaultLambda == 0 {
+		cma.defaultLambda = 4 + int(3*math.Log(float64(cma.nDim)))
+	}
+	if cma.defaultLambda < 2 {
+		return errors.New("cmaes: population size must be at least two")
+	}
+	cma.largeLambda = cma.defaultLambda
+	cma.restarts = 0
+	cma.largeRestarts = 0
+	cma.small = false
+	cma.largeEvals = 0
+	cma.smallEvals = 0
+
+	cma.z = make([]float64, cma.nDim)
+	cma.tmp = make([]float64, cma.nDim)
+	cma.startRun(cma.initLoc, cma.defaultLambda, cma.sigma0)
+	return nil
+}
+
+func (cma *Cmaes) finiteBounds() bool {
+	if cma.bounds == nil {
+		return false
+	}
+	for i := range cma.bounds.Lower {
+		if math.IsInf(cma.bounds.Lower[i], 0) || math.IsInf(cma.bounds.Upper[i], 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// startRun resets the distribution and sets the strategy parameters for
+// a population of size lambda
+func (cma *Cmaes) startRun(mean []float64, lambda int, sigma float64) {
+	n := cma.nDim
+	nf := float64(n)
+	cma.lambda = lambda
+	cma.mu = lambda / 2
+	cma.weights = make([]float64, cma.mu)
+	var sum, sumSq float64
+	for i := range cma.weights {
+		cma.weights[i] = math.Log(float64(lambda)/2+0.5) - math.Log(float64(i+1))
+		sum += cma.weights[i]
+	}
+	for i := range cma.weights {
+		cma.weights[i] /= sum
+		sumSq += cma.weights[i] * cma.weights[i]
+	}
+	cma.mueff = 1 / sumSq
+
+	cma.cc = (4 + cma.mueff/nf) / (nf + 4 + 2*cma.mueff/nf)
+	cma.cs = (cma.mueff + 2) / (nf + cma.mueff + 5)
+	cma.c1 = 2 / ((nf+1.3)*(nf+1.3) + cma.mueff)
+	cma.cmu = math.Min(1-cma.c1, 2*(cma.mueff-2+1/cma.mueff)/((nf+2)*(nf+2)+cma.mueff))
+	cma.damps = 1 + 2*math.Max(0, math.Sqrt((cma.mueff-1)/(nf+1))-1) + cma.cs
+	cma.chiN = math.Sqrt(nf) * (1 - 1/(4*nf) + 1/(21*nf*nf))
+
+	cma.mean = make([]float64, n)
+	copy(cma.mean, mean)
+	cma.oldMean = make([]float64, n)
+	cma.sigma = sigma
+	cma.pc = make([]float64, n)
+	cma.ps = make([]float64, n)
+	cma.c = make([][]float64, n)
+	cma.b = make([][]float64, n)
+	cma.d = make([]float64, n)
+	for i := 0; i < n; i++ {
+		cma.c[i] = make([]float64, n)
+		cma.b[i] = make([]float64, n)
+		cma.c[i][i] = 1
+		cma.b[i][i] = 1
+		cma.d[i] = 1
+	}
+	cma.evals = 0
+	cma.eigenEval = 0
+	cma.gen = 0
+	cma.bestHist = cma.bestHist[:0]
+	cma.medHist = cma.medHist[:0]
+
+	cma.y = make([][]float64, lambda)
+	cma.x = make([][]float64, lambda)
+	for i := range cma.x {
+		cma.y[i] = make([]float64, n)
+		cma.x[i] = make([]float64, n)
+	}
+	cma.fit = make([]float64, lambda)
+	cma.order = make([]int, lambda)
+}
+
+// sample draws member k of the population. Samples outside of the bounds
+// are redrawn a few times and then moved onto the bounds
+func (cma *Cmaes) sample(k int) {
+	x := cma.x[k]
+	y := cma.y[k]
+	for try := 0; ; try++ {
+		for i := range cma.z {
+			cma.z[i] = cma.rng.NormFloat64() * cma.d[i]
+		}
+		for i := range y {
+			var s float64
+			for j, zj := range cma.z {
+				s += cma.b[i][j] * zj
+			}
+			y[i] = s
+			x[i] = cma.mean[i] + cma.sigma*s
+		}
+		if cma.bounds == nil {
+			return
+		}
+		feasible := true
+		for i, v := range x {
+			if v < cma.bounds.Lower[i] || v > cma.bounds.Upper[i] {
+				feasible = false
+				break
+			}
+		}
+		if feasible {
+			return
+		}
+		if try == 10 {
+			cma.bounds.Project(x)
+			for i := range y {
+				y[i] = (x[i] - cma.mean[i]) / cma.sigma
+			}
+			return
+		}
+	}
+}
+
+func (cma *Cmaes) Iterate(loc *multi.Location, obj *uni.Objective, fun optimize.MultiObj) (status.Status, error) {
+	for k := 0; k < cma.lambda; k++ {
+		cma.sample(k)
+	}
+	fs, err := evaluateBatch(fun, cma.x[:cma.lambda])
+	for k, f := range fs {
+		cma.evals++
+		if cma.small {
+			cma.smallEvals++
+		} else {
+			cma.largeEvals++
+		}
+		if math.IsNaN(f) {
+			f = math.Inf(1)
+		}
+		cma.fit[k] = f
+		if f < obj.Curr() {
+			loc.SetCurr(cma.x[k])
+			obj.SetCurr(f)
+		}
+	}
+	if err != nil {
+		return optimize.EvalStatus("cmaes", err)
+	}
+	cma.gen++
+
+	for i := range cma.order {
+		cma.order[i] = i
+	}
+	sort.Sort(byFitness{cma.order, cma.fit})
+
+	err = cma.update()
+	if err != nil {
+		return status.OptimizerError, err
+	}
+
+	stat := cma.runStatus()
+	if stat == status.Continue {
+		return status.Continue, nil
+	}
+	if cma.Restart == NoRestart || cma.largeRestarts >= cma.MaxRestarts {
+		return stat, nil
+	}
+	cma.restart()
+	return status.Continue, nil
+}
+
+type byFitness struct {
+	order []int
+	fit   []float64
+}
+
+func (b byFitness) Len() int           { return len(b.order) }
+func (b byFitness) Less(i, j int) bool { return b.fit[b.order[i]] < b.fit[b.order[j]] }
+func (b byFitness) Swap(i, j int)      { b.order[i], b.order[j] = b.order[j], b.order[i] }
+
+// update adapts the mean, evolution paths, covariance and step size from
+// the sorted population
+func (cma *Cmaes) update() error {
+	n := cma.nDim
+	copy(cma.oldMean, cma.mean)
+	// yw is the weighted mean step, stored in tmp
+	for i := range cma.tmp {
+		cma.tmp[i] = 0
+	}
+	for r := 0; r < cma.mu; r++ {
+		y := cma.y[cma.order[r]]
+		for i := range cma.tmp {
+			cma.tmp[i] += cma.weights[r] * y[i]
+		}
+	}
+	for i := range cma.mean {
+		cma.mean[i] = cma.oldMean[i] + cma.sigma*cma.tmp[i]
+	}
+
+	// Step size path uses C^(-1/2) * yw = B * D^-1 * B^T * yw
+	csFactor := math.Sqrt(cma.cs * (2 - cma.cs) * cma.mueff)
+	for j := range cma.z {
+		var s float64
+		for i := 0; i < n; i++ {
+			s += cma.b[i][j] * cma.tmp[i]
+		}
+		cma.z[j] = s / cma.d[j]
+	}
+	var psNorm float64
+	for i := range cma.ps {
+		var s float64
+		for j, zj := range cma.z {
+			s += cma.b[i][j] * zj
+		}
+		cma.ps[i] = (1-cma.cs)*cma.ps[i] + csFactor*s
+		psNorm += cma.ps[i] * cma.ps[i]
+	}
+	psNorm = math.Sqrt(psNorm)
+
+	hsig := 0.0
+	denom := math.Sqrt(1 - math.Pow(1-cma.cs, 2*float64(cma.evals)/float64(cma.lambda)))
+	if psNorm/denom/cma.chiN < 1.4+2/(float64(n)+1) {
+		hsig = 1
+	}
+	ccFactor := math.Sqrt(cma.cc * (2 - cma.cc) * cma.mueff)
+	for i := range cma.pc {
+		cma.pc[i] = (1-cma.cc)*cma.pc[i] + hsig*ccFactor*cma.tmp[i]
+	}
+
+	// Rank-one and rank-mu update of the covariance
+	oldFactor := 1 - cma.c1 - cma.cmu + (1-hsig)*cma.c1*cma.cc*(2-cma.cc)
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			v := oldFactor*cma.c[i][j] + cma.c1*cma.pc[i]*cma.pc[j]
+			for r := 0; r < cma.mu; r++ {
+				y := cma.y[cma.order[r]]
+				v += cma.cmu * cma.weights[r] * y[i] * y[j]
+			}
+			cma.c[i][j] = v
+			cma.c[j][i] = v
+		}
+	}
+
+	cma.sigma *= math.Exp((cma.cs / cma.damps) * (psNorm/cma.chiN - 1))
+
+	// The decomposition is only updated periodically so the cost per
+	// evaluation is O(n^2)
+	if float64(cma.evals-cma.eigenEval) > float64(cma.lambda)/(cma.c1+cma.cmu)/float64(n)/10 {
+		cma.eigenEval = cma.evals
+		eig, err := linalg.NewEigenSym(cma.c)
+		if err != nil {
+			return errors.New("cmaes: error decomposing covariance: " + err.Error())
+		}
+		vecs := eig.Vectors()
+		for i, val := range eig.Values() {
+			cma.d[i] = math.Sqrt(math.Max(val, 0))
+			for j := 0; j < n; j++ {
+				cma.b[j][i] = vecs[j][i]
+			}
+		}
+	}
+
+	best := cma.fit[cma.order[0]]
+	median := cma.fit[cma.order[cma.lambda/2]]
+	cma.bestHist = append(cma.bestHist, best)
+	cma.medHist = append(cma.medHist, median)
+	if window := cma.stagnationWindow(); len(cma.bestHist) > window {
+		cma.bestHist = cma.bestHist[1:]
+		cma.medHist = cma.medHist[1:]
+	}
+	return nil
+}
+
+// stagnationWindow is the number of generations over which stagnation is
+// tested
+func (cma *Cmaes) stagnationWindow() int {
+	w := 120 + int(math.Ceil(30*float64(cma.nDim)/float64(cma.lambda)))
+	if w > 20000 {
+		w = 20000
+	}
+	return w
+}
+
+// runStatus checks if the current run has converged
+func (cma *Cmaes) runStatus() status.Status {
+	n := cma.nDim
+
+	minD, maxD := math.Inf(1), 0.0
+	for _, v := range cma.d {
+		minD = math.Min(minD, v)
+		maxD = math.Max(maxD, v)
+	}
+	if minD == 0 || maxD*maxD/(minD*minD) > cma.MaxCondition {
+		return status.IllConditioned
+	}
+
+	// Range of the recent best values and the current generation
+	histLen := 10 + int(math.Ceil(30*float64(n)/float64(cma.lambda)))
+	if len(cma.bestHist) >= histLen {
+		lo := cma.fit[cma.order[0]]
+		hi := cma.fit[cma.order[cma.lambda-1]]
+		for _, v := range cma.bestHist[len(cma.bestHist)-histLen:] {
+			lo = math.Min(lo, v)
+			hi = math.Max(hi, v)
+		}
+		if hi-lo < cma.ObjRangeTol {
+			return status.ObjRangeTol
+		}
+	}
+
+	small := true
+	for i := 0; i < n; i++ {
+		if cma.sigma*math.Max(math.Abs(cma.pc[i]), math.Sqrt(cma.c[i][i])) >= cma.StepTol {
+			small = false
+			break
+		}
+	}
+	if small {
+		return status.StepAbsTol
+	}
+
+	// Stagnation if the median of the most recent 20% of the best and
+	// median values is no better than the median of the first 30%
+	if len(cma.bestHist) == cma.stagnationWindow() {
+		w := len(cma.bestHist)
+		nOld := int(0.3 * float64(w))
+		nNew := int(0.2 * float64(w))
+		if medianOf(cma.bestHist[w-nNew:]) >= medianOf(cma.bestHist[:nOld]) &&
+			medianOf(cma.medHist[w-nNew:]) >= medianOf(cma.medHist[:nOld]) {
+			return status.StagnationTol
+		}
+	}
+	return status.Continue
+}
+
+func medianOf(s []float64) float64 {
+	c := make([]float64, len(s))
+	copy(c, s)
+	sort.Float64s(c)
+	return c[len(c)/2]
+}
+
+// restart starts a new run with the population size and step size given
+// by the restart strategy
+func (cma *Cmaes) restart() {
+	cma.restarts++
+	mean := cma.initLoc
+	if cma.finiteBounds() {
+		mean = make([]float64, cma.nDim)
+		for i := range mean {
+			lo, hi := cma.bounds.Lower[i], cma.bounds.Upper[i]
+			mean[i] = lo + cma.rng.Float64()*(hi-lo)
+		}
+	}
+
+	lambda := cma.lambda
+	sigma := cma.sigma0
+	switch cma.Restart {
+	case Ipop:
+		cma.largeRestarts++
+		lambda = int(float64(cma.lambda) * cma.IncPopSize)
+	case Bipop:
+		// Run the regime which has used fewer evaluations. The first
+		// restart is always in the large regime
+		cma.small = cma.restarts > 1 && cma.smallEvals < cma.largeEvals
+		if cma.small {
+			u := cma.rng.Float64()
+			lambda = int(float64(cma.defaultLambda) * math.Pow(0.5*float64(cma.largeLambda)/float64(cma.defaultLambda), u*u))
+			if lambda < 2 {
+				lambda = 2
+			}
+			sigma = cma.sigma0 * math.Pow(10, -2*cma.rng.Float64())
+		} else {
+			cma.largeRestarts++
+			cma.largeLambda = int(float64(cma.largeLambda) * cma.IncPopSize)
+			lambda = cma.largeLambda
+		}
+	}
+	cma.startRun(mean, lambda, sigma)
+}
+
+// Restarts returns the number of restarts which have occurred
+func (cma *Cmaes) Restarts() int {
+	return cma.restarts
+}
+
+func (cma *Cmaes) AddToDisplay(d []*display.Struct) []*display.Struct {
+	return append(d,
+		&display.Struct{Value: cma.sigma, Heading: "Sigma"},
+		&display.Struct{Value: cma.lambda, Heading: "PopSize"},
+		&display.Struct{Value: cma.restarts, Heading: "Restarts"},
+	)
+}
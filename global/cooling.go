@@ -0,0 +1,101 @@
+package global
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Cooling is a temperature schedule for simulated annealing. Temperature
+// returns the temperature for the given iteration (starting from zero)
+// given the fraction of proposals accepted during the previous iteration
+// (NaN at iteration zero)
+type Cooling interface {
+	Temperature(iter int, acceptance float64) float64
+}
+
+// Exponential is the schedule T = Initial * Rate^iter
+type Exponential struct {
+	Initial float64
+	Rate    float64
+}
+
+func (e Exponential) Temperature(iter int, acceptance float64) float64 {
+	return e.Initial * math.Pow(e.Rate, float64(iter))
+}
+
+// Logarithmic is the schedule T = Initial * ln(2) / ln(iter + 2) of Geman
+// and Geman (1984). It cools slowly enough to guarantee convergence in
+// probability to the global minimum, but is usually too slow in practice
+type Logarithmic struct {
+	Initial float64
+}
+
+func (l Logarithmic) Temperature(iter int, acceptance float64) float64 {
+	return l.Initial * math.Ln2 / math.Log(float64(iter)+2)
+}
+
+// Adaptive is a schedule which adapts to the acceptance rate. The
+// temperature is multiplied by Fast when the acceptance rate is above
+// Target, and by Slow otherwise, so the search cools quickly while most
+// proposals are accepted and slowly once they start being rejected
+type Adaptive struct {
+	Initial float64
+	Target  float64
+	Fast    float64
+	Slow    float64
+
+	temp float64
+}
+
+// NewAdaptive returns an adaptive schedule with the default target of 0.44
+func NewAdaptive(initial float64) *Adaptive {
+	return &Adaptive{
+		Initial: initial,
+		Target:  0.44,
+		Fast:    0.8,
+		Slow:    0.99,
+	}
+}
+
+func (a *Adaptive) Temperature(iter int, acceptance float64) float64 {
+	if iter == 0 {
+		a.temp = a.Initial
+		return a.temp
+	}
+	if acceptance > a.Target {
+		a.temp *= a.Fast
+	} else {
+		a.temp *= a.Slow
+	}
+	return a.temp
+}
+
+// Neighbour proposes a new location near x for simulated annealing on
+// continuous problems. The proposal is stored in dst
+type Neighbour interface {
+	Neighbour(dst, x []float64, temperature float64, rng *rand.Rand)
+}
+
+// Gaussian proposes x + Sigma * N(0, I), independent of the temperature
+type Gaussian struct {
+	Sigma float64
+}
+
+func (g Gaussian) Neighbour(dst, x []float64, temperature float64, rng *rand.Rand) {
+	for i := range dst {
+		dst[i] = x[i] + g.Sigma*rng.NormFloat64()
+	}
+}
+
+// Cauchy proposes a step in every coordinate from a Cauchy distribution
+// with width Scale * temperature (the fast annealing of Szu and Hartley,
+// 1987). The heavy tails allow occasional long jumps at any temperature
+type Cauchy struct {
+	Scale float64
+}
+
+func (c Cauchy) Neighbour(dst, x []float64, temperature float64, rng *rand.Rand) {
+	for i := range dst {
+		dst[i] = x[i] + c.Scale*temperature*math.Tan(math.Pi*(rng.Float64()-0.5))
+	}
+}
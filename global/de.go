@@ -0,0 +1,282 @@
+package global
+
+import (
+	"github.com/btracey/gofunopter/common/display"
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/projection"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// DEStrategy is the mutation strategy used by differential evolution
+type DEStrategy int
+
+const (
+	RandOneBin          DEStrategy = iota // v = x_r1 + F * (x_r2 - x_r3)
+	BestOneBin                            // v = x_best + F * (x_r1 - x_r2)
+	CurrentToBestOneBin                   // v = x_i + F * (x_best - x_i) + F * (x_r1 - x_r2)
+)
+
+// DifferentialEvolution is the method of Storn and Price (1997). A
+// population of candidate locations is kept within the bounds, and each
+// generation every member is compared against a trial location formed by
+// mutation and binomial crossover with the rest of the population.
+// The initial location is a member of the starting population, and the
+// rest are drawn uniformly within the bounds (which must be finite).
+// Each call to Iterate is one generation, and the trial locations of a
+// generation are evaluated together (see OptimizeObj). The population mean and
+// standard deviation of the objective values are displayed during the
+// optimization
+type DifferentialEvolution struct {
+	// Tunable parameters
+	Strategy       DEStrategy
+	PopulationSize int     // Number of members (if zero, 10 times the dimension, and at least 5)
+	Weight         float64 // Differential weight F
+	Crossover      float64 // Crossover probability CR
+	Seed           int64   // Seed for the random number generator
+
+	// Other needed variables
+	rng     *rand.Rand
+	bounds  *projection.Box
+	nDim    int
+	first   bool
+	pop     [][]float64
+	popObj  []float64
+	best    int
+	trials  [][]float64
+	popMean float64
+	popStd  float64
+}
+
+// NewDifferentialEvolution returns a rand/1/bin differential evolution
+// optimizer with the default settings
+func NewDifferentialEvolution() *DifferentialEvolution {
+	return &DifferentialEvolution{
+		Strategy:  RandOneBin,
+		Weight:    0.8,
+		Crossover: 0.9,
+		Seed:      1,
+	}
+}
+
+func (de *DifferentialEvolution) Initialize(loc *multi.Location, obj *uni.Objective, bounds *projection.Box) error {
+	if bounds == nil {
+		return errors.New("de: bounds must be set")
+	}
+	for i := range bounds.Lower {
+		if math.IsInf(bounds.Lower[i], 0) || math.IsInf(bounds.Upper[i], 0) {
+			return errors.New("de: bounds must be finite")
+		}
+	}
+	if de.Weight <= 0 || de.Weight > 2 {
+		return errors.New("de: weight must be in (0, 2]")
+	}
+	if de.Crossover < 0 || de.Crossover > 1 {
+		return errors.New("de: crossover probability must be in [0, 1]")
+	}
+	switch de.Strategy {
+	case RandOneBin, BestOneBin, CurrentToBestOneBin:
+	default:
+		return errors.New("de: unknown strategy")
+	}
+	de.bounds = bounds
+	de.nDim = len(loc.Init())
+	de.rng = rand.New(rand.NewSource(de.Seed))
+
+	nPop := de.PopulationSize
+	if nPop == 0 {
+		nPop = 10 * de.nDim
+		if nPop < 5 {
+			nPop = 5
+		}
+	}
+	// rand/1 needs the target and three other distinct members
+	if nPop < 4 {
+		return errors.New("de: population size must be at least four")
+	}
+
+	de.pop = make([][]float64, nPop)
+	de.popObj = make([]float64, nPop)
+	for i := range de.pop {
+		de.pop[i] = make([]float64, de.nDim)
+		if i == 0 {
+			copy(de.pop[i], loc.Curr())
+			de.popObj[i] = obj.Curr()
+			continue
+		}
+		for j := range de.pop[i] {
+			lo, hi := bounds.Lower[j], bounds.Upper[j]
+			de.pop[i][j] = lo + de.rng.Float64()*(hi-lo)
+		}
+		de.popObj[i] = math.NaN()
+	}
+	de.trials = make([][]float64, nPop)
+	for i := range de.trials {
+		de.trials[i] = make([]float64, de.nDim)
+	}
+	de.best = 0
+	de.first = true
+	de.setStats()
+	return nil
+}
+
+func (de *DifferentialEvolution) Iterate(loc *multi.Location, obj *uni.Objective, fun optimize.MultiObj) (status.Status, error) {
+	if de.first {
+		// Evaluate the random members of the initial population
+		fs, err := evaluateBatch(fun, de.pop[1:])
+		for i, f := range fs {
+			de.popObj[i+1] = f
+			de.update(i+1, loc, obj)
+		}
+		de.setStats()
+		if err != nil {
+			return optimize.EvalStatus("de", err)
+		}
+		de.first = false
+		return status.Continue, nil
+	}
+
+	for i := range de.pop {
+		de.mutate(i, de.trials[i])
+	}
+	fs, err := evaluateBatch(fun, de.trials)
+	for i, f := range fs {
+		// Ties are accepted so the population can move across plateaus
+		if !optimize.Less(de.popObj[i], f) && !math.IsNaN(f) {
+			copy(de.pop[i], de.trials[i])
+			de.popObj[i] = f
+			de.update(i, loc, obj)
+		}
+	}
+	de.setStats()
+	if err != nil {
+		return optimize.EvalStatus("de", err)
+	}
+	return status.Continue, nil
+}
+
+// update records member i as the best if it improves on the best so far
+func (de *DifferentialEvolution) update(i int, loc *multi.Location, obj *uni.Objective) {
+	if optimize.Less(de.popObj[i], de.popObj[de.best]) {
+		de.best = i
+	}
+	if optimize.Less(de.popObj[de.best], obj.Curr()) {
+		loc.SetCurr(de.pop[de.best])
+		obj.SetCurr(de.popObj[de.best])
+	}
+}
+
+// distinct returns a random member index different from all of the
+// excluded indices
+func (de *DifferentialEvolution) distinct(exclude ...int) int {
+	for {
+		r := de.rng.Intn(len(de.pop))
+		ok := true
+		for _, e := range exclude {
+			if r == e {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return r
+		}
+	}
+}
+
+// mutate sets the trial location for member i
+func (de *DifferentialEvolution) mutate(i int, trial []float64) {
+	F := de.Weight
+	x := de.pop[i]
+	best := de.pop[de.best]
+	var base []float64
+	switch de.Strategy {
+	case RandOneBin:
+		r1 := de.distinct(i)
+		r2 := de.distinct(i, r1)
+		r3 := de.distinct(i, r1, r2)
+		base = de.pop[r1]
+		for j := range trial {
+			trial[j] = base[j] + F*(de.pop[r2][j]-de.pop[r3][j])
+		}
+	case BestOneBin:
+		r1 := de.distinct(i, de.best)
+		r2 := de.distinct(i, de.best, r1)
+		base = best
+		for j := range trial {
+			trial[j] = base[j] + F*(de.pop[r1][j]-de.pop[r2][j])
+		}
+	case CurrentToBestOneBin:
+		r1 := de.distinct(i, de.best)
+		r2 := de.distinct(i, de.best, r1)
+		base = x
+		for j := range trial {
+			trial[j] = x[j] + F*(best[j]-x[j]) + F*(de.pop[r1][j]-de.pop[r2][j])
+		}
+	}
+
+	// Binomial crossover. At least one element comes from the mutant
+	jRand := de.rng.Intn(de.nDim)
+	for j := range trial {
+		if j != jRand && de.rng.Float64() >= de.Crossover {
+			trial[j] = x[j]
+			continue
+		}
+		// Elements outside the bounds are moved halfway between the
+		// base and the violated bound
+		lo, hi := de.bounds.Lower[j], de.bounds.Upper[j]
+		if trial[j] < lo {
+			trial[j] = (base[j] + lo) / 2
+		} else if trial[j] > hi {
+			trial[j] = (base[j] + hi) / 2
+		}
+	}
+}
+
+// setStats computes the statistics of the population objective values
+func (de *DifferentialEvolution) setStats() {
+	de.popMean, de.popStd = popStats(de.popObj)
+}
+
+// popStats returns the mean and standard deviation of the finite values in
+// obj, or NaN if there are none
+func popStats(obj []float64) (mean, std float64) {
+	var sum, sumSq float64
+	var n int
+	for _, f := range obj {
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			continue
+		}
+		sum += f
+		n++
+	}
+	if n == 0 {
+		return math.NaN(), math.NaN()
+	}
+	mean = sum / float64(n)
+	for _, f := range obj {
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			continue
+		}
+		sumSq += (f - mean) * (f - mean)
+	}
+	return mean, math.Sqrt(sumSq / float64(n))
+}
+
+// Population returns the current members of the population and their
+// objective values. The slices are not copies
+func (de *DifferentialEvolution) Population() (pop [][]float64, obj []float64) {
+	return de.pop, de.popObj
+}
+
+func (de *DifferentialEvolution) AddToDisplay(d []*display.Struct) []*display.Struct {
+	return append(d,
+		&display.Struct{Value: de.popMean, Heading: "PopMean"},
+		&display.Struct{Value: de.popStd, Heading: "PopStd"},
+	)
+}
@@ -0,0 +1,652 @@
+package global
+
+import (
+	"github.com/btracey/gofunopter/common/projection"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/multivariate"
+
+	"errors"
+	"github.com/gonum/floats"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+var GLOBAL_TOLERANCE float64 = 1E-4
+
+// rastrigin has a local minimum near every integer location and the
+// global minimum of zero at the origin
+type rastrigin struct{}
+
+func (rastrigin) Objective(x []float64) (float64, error) {
+	f := 10 * float64(len(x))
+	for _, v := range x {
+		f += v*v - 10*math.Cos(2*math.Pi*v)
+	}
+	return f, nil
+}
+
+func (r rastrigin) ObjGrad(x []float64) (float64, []float64, error) {
+	f, _ := r.Objective(x)
+	g := make([]float64, len(x))
+	for i, v := range x {
+		g[i] = 2*v + 20*math.Pi*math.Sin(2*math.Pi*v)
+	}
+	return f, g, nil
+}
+
+func rastriginBounds(nDim int) *projection.Box {
+	lower := make([]float64, nDim)
+	upper := make([]float64, nDim)
+	for i := range lower {
+		lower[i] = -5.12
+		upper[i] = 5.12
+	}
+	return &projection.Box{Lower: lower, Upper: upper}
+}
+
+func newTestSettings() *GlobalSettings {
+	settings := NewGlobalSettings()
+	settings.Display = false
+	return settings
+}
+
+func TestDifferentialEvolution(t *testing.T) {
+	nDim := 3
+	for _, strategy := range []DEStrategy{RandOneBin, BestOneBin, CurrentToBestOneBin} {
+		de := NewDifferentialEvolution()
+		de.Strategy = strategy
+		de.PopulationSize = 30
+		// Rastrigin is separable, which favors a low crossover probability
+		de.Crossover = 0.2
+		settings := newTestSettings()
+		settings.MaximumFunctionEvaluations = 20000
+		// Start at a local minimum
+		init := []float64{2, -3, 1}
+		val, loc, result, err := OptimizeObj(rastrigin{}, init, rastriginBounds(nDim), settings, de)
+		if err != nil {
+			t.Errorf("strategy %v: error during optimization: %v", strategy, err)
+			continue
+		}
+		if result.FunctionEvaluations > settings.MaximumFunctionEvaluations {
+			t.Errorf("strategy %v: %v function evaluations is over the maximum", strategy, result.FunctionEvaluations)
+		}
+		if !floats.EqualApprox(loc, make([]float64, nDim), GLOBAL_TOLERANCE) {
+			t.Errorf("strategy %v: global minimum not found. %v found", strategy, loc)
+		}
+		if math.Abs(val) > GLOBAL_TOLERANCE {
+			t.Errorf("strategy %v: optimum value %v, expected 0", strategy, val)
+		}
+	}
+}
+
+// batchRastrigin evaluates the Rastrigin function a batch at a time
+type batchRastrigin struct {
+	rastrigin
+	batches *int
+}
+
+func (b batchRastrigin) ObjBatch(xs [][]float64) ([]float64, error) {
+	*b.batches++
+	objs := make([]float64, len(xs))
+	for i, x := range xs {
+		objs[i], _ = b.Objective(x)
+	}
+	return objs, nil
+}
+
+func TestFunctionEvaluationBudget(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		optimizer   GlobalOptimizer
+		concurrency int
+	}{
+		{"de", NewDifferentialEvolution(), 1},
+		{"de concurrent", NewDifferentialEvolution(), 4},
+		{"pso concurrent", NewPso(), 4},
+		{"cmaes concurrent", NewCmaes(), 4},
+	} {
+		settings := newTestSettings()
+		settings.Concurrency = test.concurrency
+		// Stop part way through a generation
+		settings.MaximumFunctionEvaluations = 77
+		_, _, result, err := OptimizeObj(rastrigin{}, nil, rastriginBounds(4), settings, test.optimizer)
+		if err != nil {
+			t.Errorf("%v: error during optimization: %v", test.name, err)
+			continue
+		}
+		if result.Status != status.MaximumFunctionEvaluations {
+			t.Errorf("%v: status is not MaximumFunctionEvaluations. %v found", test.name, result.Status)
+		}
+		if result.FunctionEvaluations != settings.MaximumFunctionEvaluations {
+			t.Errorf("%v: %v function evaluations, %v expected", test.name, result.FunctionEvaluations, settings.MaximumFunctionEvaluations)
+		}
+	}
+}
+
+func TestGlobalSettings(t *testing.T) {
+	// The objective and location settings are applied, not only the
+	// initial objective and the absolute tolerance
+	settings := newTestSettings()
+	settings.MaximumFunctionEvaluations = 200
+	settings.KeepObjectiveHistory = true
+	settings.KeepLocationHistory = true
+	_, _, result, err := OptimizeObj(rastrigin{}, nil, rastriginBounds(2), settings, NewDifferentialEvolution())
+	if err != nil {
+		t.Fatalf("error during optimization: %v", err)
+	}
+	if len(result.ObjectiveHistory) == 0 || len(result.LocationHistory) == 0 {
+		t.Errorf("history not kept. %v objectives and %v locations found", len(result.ObjectiveHistory), len(result.LocationHistory))
+	}
+}
+
+func TestBatchEvaluation(t *testing.T) {
+	// The generations are synchronous, so the result does not depend on
+	// how the population is evaluated
+	settings := newTestSettings()
+	settings.MaximumFunctionEvaluations = 2000
+	serialObj, serialLoc, _, err := OptimizeObj(rastrigin{}, nil, rastriginBounds(3), settings, NewDifferentialEvolution())
+	if err != nil {
+		t.Fatalf("error during serial optimization: %v", err)
+	}
+
+	settings = newTestSettings()
+	settings.MaximumFunctionEvaluations = 2000
+	settings.Concurrency = 8
+	obj, loc, result, err := OptimizeObj(rastrigin{}, nil, rastriginBounds(3), settings, NewDifferentialEvolution())
+	if err != nil {
+		t.Fatalf("error during concurrent optimization: %v", err)
+	}
+	if obj != serialObj || !floats.Equal(loc, serialLoc) {
+		t.Errorf("concurrent optimum %v at %v does not match serial optimum %v at %v", obj, loc, serialObj, serialLoc)
+	}
+	if result.FunctionEvaluations != settings.MaximumFunctionEvaluations {
+		t.Errorf("%v function evaluations, %v expected", result.FunctionEvaluations, settings.MaximumFunctionEvaluations)
+	}
+
+	var batches int
+	settings = newTestSettings()
+	settings.MaximumFunctionEvaluations = 2000
+	obj, loc, result, err = OptimizeObj(batchRastrigin{batches: &batches}, nil, rastriginBounds(3), settings, NewDifferentialEvolution())
+	if err != nil {
+		t.Fatalf("error during batch optimization: %v", err)
+	}
+	if obj != serialObj || !floats.Equal(loc, serialLoc) {
+		t.Errorf("batch optimum %v at %v does not match serial optimum %v at %v", obj, loc, serialObj, serialLoc)
+	}
+	if result.FunctionEvaluations != settings.MaximumFunctionEvaluations {
+		t.Errorf("%v function evaluations, %v expected", result.FunctionEvaluations, settings.MaximumFunctionEvaluations)
+	}
+	// One batch per generation of 30 (the first is 29 after the initial
+	// location, and the last is truncated)
+	if want := (settings.MaximumFunctionEvaluations - 1 + 29) / 30; batches != want {
+		t.Errorf("%v batches evaluated, %v expected", batches, want)
+	}
+}
+
+func TestDifferentialEvolutionBounds(t *testing.T) {
+	de := NewDifferentialEvolution()
+	_, _, _, err := OptimizeObj(rastrigin{}, []float64{0, 0}, nil, newTestSettings(), de)
+	if err == nil {
+		t.Errorf("no error with nil bounds")
+	}
+}
+
+// rosenbrock is a narrow curved valley with the minimum of zero at all ones
+type rosenbrock struct{}
+
+func (rosenbrock) Objective(x []float64) (float64, error) {
+	var f float64
+	for i := 0; i < len(x)-1; i++ {
+		a := 1 - x[i]
+		b := x[i+1] - x[i]*x[i]
+		f += a*a + 100*b*b
+	}
+	return f, nil
+}
+
+func TestCmaes(t *testing.T) {
+	nDim := 6
+	cma := NewCmaes()
+	cma.Restart = NoRestart
+	cma.Sigma = 0.5
+	settings := newTestSettings()
+	settings.MaximumFunctionEvaluations = 50000
+	val, loc, result, err := OptimizeObj(rosenbrock{}, make([]float64, nDim), nil, settings, cma)
+	if err != nil {
+		t.Fatalf("error during optimization: %v", err)
+	}
+	if result.Status != status.ObjRangeTol && result.Status != status.StepAbsTol {
+		t.Errorf("run did not converge. Status %v", result.Status)
+	}
+	ones := make([]float64, nDim)
+	floats.AddConst(1, ones)
+	if !floats.EqualApprox(loc, ones, GLOBAL_TOLERANCE) {
+		t.Errorf("minimum not found. %v found", loc)
+	}
+	if val > GLOBAL_TOLERANCE {
+		t.Errorf("optimum value %v, expected 0", val)
+	}
+
+	// Restarts with larger populations are needed for rastrigin
+	nDim = 5
+	for _, restart := range []RestartStrategy{Ipop, Bipop} {
+		cma = NewCmaes()
+		cma.Restart = restart
+		settings = newTestSettings()
+		settings.MaximumFunctionEvaluations = 200000
+		settings.ObjectiveAbsoluteTolerance = 1E-8
+		_, loc, result, err = OptimizeObj(rastrigin{}, []float64{4, -4, 3, -3, 2}, rastriginBounds(nDim), settings, cma)
+		if err != nil {
+			t.Errorf("restart %v: error during optimization: %v", restart, err)
+			continue
+		}
+		if result.Status != status.ObjAbsTol {
+			t.Errorf("restart %v: global minimum not found. Status %v after %v restarts", restart, result.Status, cma.Restarts())
+		}
+		if cma.Restarts() == 0 {
+			t.Errorf("restart %v: no restarts", restart)
+		}
+		if !floats.EqualApprox(loc, make([]float64, nDim), GLOBAL_TOLERANCE) {
+			t.Errorf("restart %v: global minimum not found. %v found", restart, loc)
+		}
+	}
+}
+
+func TestPso(t *testing.T) {
+	nDim := 2
+	for _, test := range []struct {
+		name string
+		pso  *Pso
+	}{
+		{"InertiaGlobal", NewPso()},
+		{"ConstrictionRing", NewConstrictionPso()},
+	} {
+		test.pso.SwarmSize = 30
+		settings := newTestSettings()
+		settings.MaximumFunctionEvaluations = 20000
+		val, loc, result, err := OptimizeObj(rastrigin{}, []float64{2, -3}, rastriginBounds(nDim), settings, test.pso)
+		if err != nil {
+			t.Errorf("%v: error during optimization: %v", test.name, err)
+			continue
+		}
+		if result.FunctionEvaluations > settings.MaximumFunctionEvaluations {
+			t.Errorf("%v: %v function evaluations is over the maximum", test.name, result.FunctionEvaluations)
+		}
+		if !floats.EqualApprox(loc, make([]float64, nDim), GLOBAL_TOLERANCE) {
+			t.Errorf("%v: global minimum not found. %v found", test.name, loc)
+		}
+		if math.Abs(val) > GLOBAL_TOLERANCE {
+			t.Errorf("%v: optimum value %v, expected 0", test.name, val)
+		}
+	}
+}
+
+// permutation is a discrete state whose energy is the total displacement
+// of the elements from sorted order
+type permutation []int
+
+func (p permutation) Energy() (float64, error) {
+	var e float64
+	for i, v := range p {
+		e += math.Abs(float64(v - i))
+	}
+	return e, nil
+}
+
+func (p permutation) Neighbour(rng *rand.Rand) State {
+	q := make(permutation, len(p))
+	copy(q, p)
+	i := rng.Intn(len(q))
+	j := rng.Intn(len(q))
+	q[i], q[j] = q[j], q[i]
+	return q
+}
+
+func TestSimulatedAnnealing(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		sa   *SimulatedAnnealing
+	}{
+		{"Exponential", &SimulatedAnnealing{Cooling: Exponential{Initial: 10, Rate: 0.9}, Neighbour: Gaussian{Sigma: 0.5}, Steps: 200, Seed: 1}},
+		{"Logarithmic", &SimulatedAnnealing{Cooling: Logarithmic{Initial: 1}, Neighbour: Cauchy{Scale: 0.5}, Steps: 200, Seed: 1}},
+		{"Adaptive", &SimulatedAnnealing{Cooling: NewAdaptive(10), Neighbour: Gaussian{Sigma: 0.5}, Steps: 200, Seed: 1}},
+	} {
+		settings := newTestSettings()
+		settings.MaximumIterations = 150
+		_, loc, result, err := OptimizeObj(rastrigin{}, []float64{2, -3}, rastriginBounds(2), settings, test.sa)
+		if err != nil {
+			t.Errorf("%v: error during optimization: %v", test.name, err)
+			continue
+		}
+		if !floats.EqualApprox(loc, []float64{0, 0}, 0.05) {
+			t.Errorf("%v: global minimum not found. %v found", test.name, loc)
+		}
+		trace := test.sa.BestTrace()
+		if len(trace) != result.Iterations || len(test.sa.Acceptance()) != result.Iterations {
+			t.Errorf("%v: history length does not match the %v iterations", test.name, result.Iterations)
+		}
+		for i := 1; i < len(trace); i++ {
+			if trace[i] > trace[i-1] {
+				t.Errorf("%v: best trace increases at iteration %v", test.name, i)
+				break
+			}
+		}
+	}
+
+	sa := NewSimulatedAnnealing()
+	sa.Cooling = Exponential{Initial: 5, Rate: 0.9}
+	settings := NewAnnealSettings()
+	settings.Display = false
+	settings.MaximumIterations = 200
+	settings.KeepObjectiveHistory = true
+	initial := permutation{7, 3, 9, 0, 5, 1, 8, 2, 6, 4}
+	energy, best, result, err := AnnealState(initial, settings, sa)
+	if err != nil {
+		t.Fatalf("discrete: error during optimization: %v", err)
+	}
+	if energy != 0 {
+		t.Errorf("discrete: minimum energy not found. %v found with state %v", energy, best)
+	}
+	if len(result.BestTrace) != result.Iterations || len(result.Acceptance) != result.Iterations {
+		t.Errorf("discrete: history length does not match the %v iterations", result.Iterations)
+	}
+	if result.Acceptance[0] <= result.Acceptance[len(result.Acceptance)-1] {
+		t.Errorf("discrete: acceptance rate does not decrease as the temperature cools")
+	}
+	if len(result.ObjectiveHistory) == 0 {
+		t.Errorf("discrete: objective history not kept")
+	}
+}
+
+// fourWells has local minima near (+-1, +-1), tilted so the minima on the
+// negative side of x_0 are lowest
+type fourWells struct{}
+
+func (fourWells) ObjGrad(x []float64) (f float64, g []float64, err error) {
+	g = make([]float64, len(x))
+	for i, v := range x {
+		f += (v*v - 1) * (v*v - 1)
+		g[i] = 4 * v * (v*v - 1)
+	}
+	f += 0.1*x[0] + 0.05*x[1]
+	g[0] += 0.1
+	g[1] += 0.05
+	return f, g, nil
+}
+
+func TestSamplers(t *testing.T) {
+	bounds := &projection.Box{Lower: []float64{-1, 0, 2}, Upper: []float64{1, 4, 3}}
+	n := 16
+	for _, test := range []struct {
+		name    string
+		sampler Sampler
+	}{
+		{"Uniform", Uniform{}},
+		{"LatinHypercube", LatinHypercube{}},
+		{"Sobol", Sobol{}},
+	} {
+		dst := make([][]float64, n)
+		for i := range dst {
+			dst[i] = make([]float64, 3)
+		}
+		err := test.sampler.Sample(dst, bounds, rand.New(rand.NewSource(1)))
+		if err != nil {
+			t.Errorf("%v: error sampling: %v", test.name, err)
+			continue
+		}
+		for j := range bounds.Lower {
+			counts := make([]int, n)
+			for _, x := range dst {
+				if x[j] < bounds.Lower[j] || x[j] > bounds.Upper[j] {
+					t.Errorf("%v: sample %v outside the bounds", test.name, x)
+				}
+				u := (x[j] - bounds.Lower[j]) / (bounds.Upper[j] - bounds.Lower[j])
+				k := int(u * float64(n))
+				if k == n {
+					k--
+				}
+				counts[k]++
+			}
+			if test.name == "Uniform" {
+				continue
+			}
+			// Exactly one sample in every interval of every coordinate
+			for k, c := range counts {
+				if c != 1 {
+					t.Errorf("%v: %v samples in interval %v of coordinate %v", test.name, c, k, j)
+				}
+			}
+		}
+	}
+}
+
+func TestMultistart(t *testing.T) {
+	bounds := &projection.Box{Lower: []float64{-2, -2}, Upper: []float64{2, 2}}
+	for _, sampler := range []Sampler{Uniform{}, LatinHypercube{}, Sobol{}} {
+		settings := NewMultistartSettings()
+		settings.Sampler = sampler
+		settings.Concurrency = 4
+		minima, result, err := Multistart(fourWells{}, bounds, settings)
+		if err != nil {
+			t.Errorf("%T: error during optimization: %v", sampler, err)
+			continue
+		}
+		if len(minima) != 4 {
+			t.Errorf("%T: %v minima found, 4 expected", sampler, len(minima))
+			continue
+		}
+		var starts, evals int
+		for i, m := range minima {
+			starts += len(m.Starts)
+			if i > 0 && m.Objective < minima[i-1].Objective {
+				t.Errorf("%T: minima are not sorted", sampler)
+			}
+			for _, v := range m.Location {
+				if math.Abs(math.Abs(v)-1) > 0.05 {
+					t.Errorf("%T: minimum %v is not near a well", sampler, m.Location)
+				}
+			}
+		}
+		for _, r := range result.Results {
+			evals += r.FunctionEvaluations
+		}
+		if starts != settings.NumStarts {
+			t.Errorf("%T: %v starts assigned to minima, %v expected", sampler, starts, settings.NumStarts)
+		}
+		if evals != result.FunctionEvaluations {
+			t.Errorf("%T: total function evaluations %v does not match the sum %v", sampler, result.FunctionEvaluations, evals)
+		}
+		if minima[0].Location[0] > 0 || minima[0].Location[1] > 0 {
+			t.Errorf("%T: best minimum %v is not the global minimum", sampler, minima[0].Location)
+		}
+	}
+	if _, _, err := Multistart(fourWells{}, nil, nil); err == nil {
+		t.Errorf("no error for nil bounds")
+	}
+}
+
+func TestBasinHopping(t *testing.T) {
+	bh := NewBasinHopping()
+	bh.StepSize = 1
+	bh.MaxStagnation = 50
+	settings := multivariate.NewMultiGradSettings()
+	settings.Display = false
+	settings.MaximumIterations = 500
+	val, loc, result, err := multivariate.OptimizeGrad(rastrigin{}, []float64{3.1, -2.2, 1.9}, settings, bh)
+	if err != nil {
+		t.Fatalf("error during optimization: %v", err)
+	}
+	if result.Status != status.StagnationTol {
+		t.Errorf("status is not StagnationTol. %v found", result.Status)
+	}
+	if !floats.EqualApprox(loc, make([]float64, 3), GLOBAL_TOLERANCE) {
+		t.Errorf("global minimum not found. %v found", loc)
+	}
+	if math.Abs(val) > GLOBAL_TOLERANCE {
+		t.Errorf("optimum value %v, expected 0", val)
+	}
+}
+
+func TestBasinHoppingEvaluations(t *testing.T) {
+	for maxEvals := 5; maxEvals <= 250; maxEvals += 5 {
+		settings := multivariate.NewMultiGradSettings()
+		settings.Display = false
+		settings.MaximumFunctionEvaluations = maxEvals
+		_, _, result, err := multivariate.OptimizeGrad(rastrigin{}, []float64{3.1, -2.2, 1.9}, settings, NewBasinHopping())
+		if err != nil {
+			t.Fatalf("error during optimization: %v", err)
+		}
+		if result.Status != status.MaximumFunctionEvaluations {
+			t.Errorf("status is not MaximumFunctionEvaluations. %v found", result.Status)
+		}
+		if result.FunctionEvaluations > maxEvals {
+			t.Errorf("%v function evaluations with a maximum of %v", result.FunctionEvaluations, maxEvals)
+		}
+	}
+}
+
+// walledQuad is a quadratic whose minimum at 3 lies beyond a region where
+// the function can't be evaluated
+type walledQuad struct{}
+
+func (walledQuad) ObjGrad(x []float64) (f float64, g []float64, err error) {
+	if x[0] > 1.5 {
+		return math.NaN(), nil, errors.New("outside the domain")
+	}
+	g = make([]float64, len(x))
+	for i, v := range x {
+		f += (v - 3) * (v - 3)
+		g[i] = 2 * (v - 3)
+	}
+	return f, g, nil
+}
+
+func TestBasinHoppingLocalFailure(t *testing.T) {
+	// The local optimizations fail in the linesearch, but the best
+	// locations they evaluated are still used
+	settings := multivariate.NewMultiGradSettings()
+	settings.Display = false
+	settings.MaximumIterations = 20
+	start := []float64{0, 0}
+	val, loc, result, err := multivariate.OptimizeGrad(walledQuad{}, start, settings, NewBasinHopping())
+	if err != nil {
+		t.Fatalf("error during optimization: %v", err)
+	}
+	if result.Status != status.MaximumIterations {
+		t.Errorf("status is not MaximumIterations. %v found", result.Status)
+	}
+	f0, _, _ := walledQuad{}.ObjGrad(start)
+	if val >= f0 || loc[0] > 1.5 {
+		t.Errorf("no improvement on the start. %v found at %v", val, loc)
+	}
+}
+
+// branin has three global minima of 0.397887 in [-5, 10] x [0, 15]
+type branin struct{}
+
+func (branin) Objective(x []float64) (float64, error) {
+	a := x[1] - 5.1/(4*math.Pi*math.Pi)*x[0]*x[0] + 5/math.Pi*x[0] - 6
+	return a*a + 10*(1-1/(8*math.Pi))*math.Cos(x[0]) + 10, nil
+}
+
+func TestGaussianProcessGradient(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	x := make([][]float64, 8)
+	y := make([]float64, len(x))
+	for i := range x {
+		x[i] = []float64{rng.Float64(), rng.Float64()}
+		y[i] = math.Sin(5*x[i][0]) + x[i][1]*x[i][1]
+	}
+	for _, kernel := range []Kernel{RBF{}, Matern32{}, Matern52{}} {
+		gp := newGaussianProcess(kernel, 2)
+		gp.setData(x, y)
+		theta := []float64{-1, -0.5, 0.3, -3}
+		grad := make([]float64, len(theta))
+		_, err := gp.negLogPosterior(theta, grad)
+		if err != nil {
+			t.Fatalf("%T: error computing likelihood: %v", kernel, err)
+		}
+		const h = 1e-6
+		for i := range theta {
+			theta[i] += h
+			fPlus, _ := gp.negLogPosterior(theta, nil)
+			theta[i] -= 2 * h
+			fMinus, _ := gp.negLogPosterior(theta, nil)
+			theta[i] += h
+			fd := (fPlus - fMinus) / (2 * h)
+			if math.Abs(fd-grad[i]) > 1e-5*math.Max(1, math.Abs(fd)) {
+				t.Errorf("%T: hyperparameter %v gradient %v, finite difference %v", kernel, i, grad[i], fd)
+			}
+		}
+
+		// The acquisition gradient is checked at a fitted model
+		b := NewBayesianOptimization()
+		b.gp = gp
+		fitSettings := multivariate.NewMultiGradSettings()
+		fitSettings.Display = false
+		err = gp.fit(fitSettings)
+		if err != nil {
+			t.Fatalf("%T: error fitting model: %v", kernel, err)
+		}
+		acq := &acquisitionObj{b: b, best: -1}
+		z := []float64{0.3, 0.6}
+		_, g, _ := acq.ObjGrad(z)
+		for i := range z {
+			z[i] += h
+			fPlus, _, _ := acq.ObjGrad(z)
+			z[i] -= 2 * h
+			fMinus, _, _ := acq.ObjGrad(z)
+			z[i] += h
+			fd := (fPlus - fMinus) / (2 * h)
+			if math.Abs(fd-g[i]) > 1e-5*math.Max(1, math.Abs(fd)) {
+				t.Errorf("%T: acquisition gradient %v, finite difference %v", kernel, g[i], fd)
+			}
+		}
+	}
+}
+
+func TestBayesianOptimization(t *testing.T) {
+	bounds := &projection.Box{Lower: []float64{-5, 0}, Upper: []float64{10, 15}}
+	for _, test := range []struct {
+		name string
+		opt  func() *BayesianOptimization
+		tol  float64
+	}{
+		{"ei matern52", NewBayesianOptimization, 0.02},
+		{"ei matern32", func() *BayesianOptimization {
+			b := NewBayesianOptimization()
+			b.Kernel = Matern32{}
+			return b
+		}, 0.05},
+		{"ei rbf", func() *BayesianOptimization {
+			b := NewBayesianOptimization()
+			b.Kernel = RBF{}
+			return b
+		}, 0.05},
+		{"ucb", func() *BayesianOptimization {
+			b := NewBayesianOptimization()
+			b.Acquisition = UpperConfidenceBound{Beta: 2}
+			return b
+		}, 0.1},
+	} {
+		settings := newTestSettings()
+		settings.MaximumFunctionEvaluations = 50
+		b := test.opt()
+		obj, loc, result, err := OptimizeObj(branin{}, nil, bounds, settings, b)
+		if err != nil {
+			t.Errorf("%v: error during optimization: %v", test.name, err)
+			continue
+		}
+		if result.FunctionEvaluations != 50 {
+			t.Errorf("%v: %v function evaluations, 50 expected", test.name, result.FunctionEvaluations)
+		}
+		if obj > 0.397887+test.tol {
+			t.Errorf("%v: minimum %v at %v not found", test.name, obj, loc)
+		}
+		mean, std := b.Predict(loc)
+		if math.Abs(mean-obj) > 0.5 || std > 0.5 {
+			t.Errorf("%v: prediction %v +/- %v at the minimum does not match %v", test.name, mean, std, obj)
+		}
+	}
+}
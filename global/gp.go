@@ -0,0 +1,349 @@
+package global
+
+import (
+	"github.com/btracey/gofunopter/common/linalg"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/multivariate"
+
+	"math"
+)
+
+// Kernel is a stationary correlation function of the squared distance
+// between two locations after each coordinate has been divided by its
+// length scale. Corr returns the correlation (one at zero distance) and its
+// derivative with respect to the scaled squared distance
+type Kernel interface {
+	Corr(r2 float64) (k, dk float64)
+}
+
+// RBF is the squared exponential kernel exp(-r^2 / 2). Its samples are
+// infinitely differentiable, which is often too smooth for real objectives
+type RBF struct{}
+
+func (RBF) Corr(r2 float64) (k, dk float64) {
+	k = math.Exp(-r2 / 2)
+	return k, -k / 2
+}
+
+// Matern32 is the Matérn kernel with smoothness 3/2, whose samples are once
+// differentiable
+type Matern32 struct{}
+
+func (Matern32) Corr(r2 float64) (k, dk float64) {
+	sr := math.Sqrt(3 * r2)
+	e := math.Exp(-sr)
+	return (1 + sr) * e, -1.5 * e
+}
+
+// Matern52 is the Matérn kernel with smoothness 5/2, whose samples are twice
+// differentiable
+type Matern52 struct{}
+
+func (Matern52) Corr(r2 float64) (k, dk float64) {
+	sr := math.Sqrt(5 * r2)
+	e := math.Exp(-sr)
+	return (1 + sr + 5*r2/3) * e, -5.0 / 6 * (1 + sr) * e
+}
+
+// minNoise is added to the noise variance so the covariance matrix stays
+// well conditioned when the objective is noiseless
+const minNoise = 1E-6
+
+// gaussianProcess is a Gaussian process regression model with a constant
+// mean. The locations are scaled to the unit cube by the optimizer, and
+// the observations are standardized by the model. The hyperparameters
+// theta are the log length scale of each coordinate, the log signal
+// variance and the log noise variance (in addition to minNoise). They are
+// fitted by maximizing the marginal likelihood times a weak log-normal
+// prior, which keeps them reasonable when there are few observations
+type gaussianProcess struct {
+	kernel Kernel
+	x      [][]float64
+	y      []float64 // standardized observations
+	yMean  float64
+	yStd   float64
+	theta  []float64
+	chol   *linalg.Cholesky
+	alpha  []float64 // K^-1 y
+}
+
+func newGaussianProcess(kernel Kernel, nDim int) *gaussianProcess {
+	gp := &gaussianProcess{
+		kernel: kernel,
+		theta:  make([]float64, nDim+2),
+	}
+	for i := range gp.theta {
+		gp.theta[i], _, _, _ = gp.prior(i)
+	}
+	return gp
+}
+
+// prior returns the mean and standard deviation of the prior on
+// hyperparameter i, and the range to which the fitted value is limited
+func (gp *gaussianProcess) prior(i int) (mean, std, lo, hi float64) {
+	nDim := len(gp.theta) - 2
+	switch {
+	case i < nDim:
+		return math.Log(0.3), 1.5, math.Log(1E-3), math.Log(1E3)
+	case i == nDim:
+		return 0, 1.5, math.Log(1E-4), math.Log(1E4)
+	default:
+		return math.Log(1E-4), 3, math.Log(1E-12), 0
+	}
+}
+
+// setData sets the observations of the model. Non-finite observations are
+// replaced by the largest finite one
+func (gp *gaussianProcess) setData(x [][]float64, y []float64) {
+	gp.x = x
+	gp.y = make([]float64, len(y))
+	worst := math.Inf(-1)
+	var n int
+	gp.yMean = 0
+	for _, v := range y {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			worst = math.Max(worst, v)
+			gp.yMean += v
+			n++
+		}
+	}
+	gp.yMean /= float64(n)
+	var ss float64
+	for i, v := range y {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			v = worst
+		}
+		gp.y[i] = v
+		ss += (v - gp.yMean) * (v - gp.yMean)
+	}
+	gp.yStd = math.Sqrt(ss / float64(len(y)))
+	if gp.yStd == 0 {
+		gp.yStd = 1
+	}
+	for i := range gp.y {
+		gp.y[i] = (gp.y[i] - gp.yMean) / gp.yStd
+	}
+}
+
+// scaledDist2 returns the squared distance between x and y after dividing
+// each coordinate by its length scale
+func scaledDist2(x, y, length []float64) float64 {
+	var r2 float64
+	for d, l := range length {
+		diff := (x[d] - y[d]) / l
+		r2 += diff * diff
+	}
+	return r2
+}
+
+// hyper returns the length scales, signal variance and noise variance
+// of the hyperparameters
+func hyper(theta []float64) (length []float64, signal, noise float64) {
+	nDim := len(theta) - 2
+	length = make([]float64, nDim)
+	for d := range length {
+		length[d] = math.Exp(theta[d])
+	}
+	return length, math.Exp(theta[nDim]), minNoise + math.Exp(theta[nDim+1])
+}
+
+// covariance factors the covariance matrix of the observations at theta.
+// If corr and corrDeriv are not nil, they are filled with the signal
+// variance times the correlation and its derivative for each pair of
+// observations
+func (gp *gaussianProcess) covariance(theta []float64, corr, corrDeriv [][]float64) (*linalg.Cholesky, error) {
+	length, signal, noise := hyper(theta)
+	n := len(gp.x)
+	k := make([][]float64, n)
+	for i := range k {
+		k[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			c, dc := gp.kernel.Corr(scaledDist2(gp.x[i], gp.x[j], length))
+			k[i][j] = signal * c
+			k[j][i] = k[i][j]
+			if corr != nil {
+				corr[i][j] = signal * c
+				corr[j][i] = corr[i][j]
+				corrDeriv[i][j] = signal * dc
+				corrDeriv[j][i] = corrDeriv[i][j]
+			}
+		}
+		k[i][i] += noise
+	}
+	return linalg.NewCholesky(k)
+}
+
+// negLogPosterior returns the negative log marginal likelihood of the
+// observations plus the negative log prior of theta. If grad is not nil,
+// it is filled with the gradient with respect to theta
+func (gp *gaussianProcess) negLogPosterior(theta, grad []float64) (float64, error) {
+	n := len(gp.x)
+	nDim := len(theta) - 2
+	var corr, corrDeriv [][]float64
+	if grad != nil {
+		corr = make([][]float64, n)
+		corrDeriv = make([][]float64, n)
+		for i := range corrDeriv {
+			corr[i] = make([]float64, n)
+			corrDeriv[i] = make([]float64, n)
+		}
+	}
+	chol, err := gp.covariance(theta, corr, corrDeriv)
+	if err != nil {
+		return math.NaN(), err
+	}
+	alpha := make([]float64, n)
+	chol.Solve(alpha, gp.y)
+	var yAlpha float64
+	for i, v := range gp.y {
+		yAlpha += v * alpha[i]
+	}
+	f := 0.5*yAlpha + 0.5*chol.LogDet() + 0.5*float64(n)*math.Log(2*math.Pi)
+	for i, v := range theta {
+		mean, std, _, _ := gp.prior(i)
+		f += 0.5 * (v - mean) * (v - mean) / (std * std)
+	}
+	if grad == nil {
+		return f, nil
+	}
+
+	// dNLL/dtheta = -1/2 tr((alpha alpha^T - K^-1) dK/dtheta)
+	w := make([][]float64, n)
+	e := make([]float64, n)
+	for j := range w {
+		w[j] = make([]float64, n)
+		e[j] = 1
+		chol.Solve(w[j], e)
+		e[j] = 0
+		for i := range w[j] {
+			w[j][i] = alpha[i]*alpha[j] - w[j][i]
+		}
+	}
+	length, _, noise := hyper(theta)
+	for i := range grad {
+		grad[i] = 0
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			grad[nDim] -= 0.5 * w[i][j] * corr[i][j]
+			if i != j {
+				for d, l := range length {
+					diff := (gp.x[i][d] - gp.x[j][d]) / l
+					grad[d] += w[i][j] * corrDeriv[i][j] * diff * diff
+				}
+				continue
+			}
+			grad[nDim+1] -= 0.5 * w[i][i] * (noise - minNoise)
+		}
+	}
+	for i, v := range theta {
+		mean, std, _, _ := gp.prior(i)
+		grad[i] += (v - mean) / (std * std)
+	}
+	return f, nil
+}
+
+// gpPosterior is the function minimized to fit the hyperparameters
+type gpPosterior struct {
+	gp *gaussianProcess
+}
+
+func (p gpPosterior) ObjGrad(theta []float64) (f float64, grad []float64, err error) {
+	grad = make([]float64, len(theta))
+	f, err = p.gp.negLogPosterior(theta, grad)
+	return f, grad, err
+}
+
+// fit fits the hyperparameters to the observations starting from the
+// current hyperparameters, and factors the covariance matrix. The first
+// fit also starts from the prior mean. Starts at which the posterior can't
+// be evaluated are skipped, and local optimizations which end with another
+// error (such as a linesearch failure) still provide their best location
+func (gp *gaussianProcess) fit(settings *multivariate.MultiGradSettings) error {
+	best := make([]float64, len(gp.theta))
+	copy(best, gp.theta)
+	bestF, err := gp.negLogPosterior(best, nil)
+	if err != nil {
+		bestF = math.Inf(1)
+	}
+	priorMean := make([]float64, len(gp.theta))
+	for i := range priorMean {
+		priorMean[i], _, _, _ = gp.prior(i)
+	}
+	starts := [][]float64{gp.theta}
+	if gp.chol == nil {
+		starts = append(starts, priorMean)
+	}
+	for _, start := range starts {
+		if _, err := gp.negLogPosterior(start, nil); err != nil {
+			continue
+		}
+		_, theta, result, err := multivariate.OptimizeGrad(gpPosterior{gp}, start, settings, multivariate.NewLbfgs())
+		if err != nil && result.Status == status.UserFunctionError {
+			// The posterior could not be evaluated at the start (the
+			// covariance was not positive definite), so skip this start
+			continue
+		}
+		if theta == nil {
+			continue
+		}
+		for i := range theta {
+			_, _, lo, hi := gp.prior(i)
+			theta[i] = math.Max(lo, math.Min(hi, theta[i]))
+		}
+		f, err := gp.negLogPosterior(theta, nil)
+		if err == nil && optimize.Less(f, bestF) {
+			copy(best, theta)
+			bestF = f
+		}
+	}
+	gp.theta = best
+
+	gp.chol, err = gp.covariance(gp.theta, nil, nil)
+	if err != nil {
+		return err
+	}
+	gp.alpha = make([]float64, len(gp.y))
+	gp.chol.Solve(gp.alpha, gp.y)
+	return nil
+}
+
+// predict returns the mean and variance of the standardized objective at
+// x. If dMean and dVar are not nil they are filled with the derivatives of
+// the mean and variance with respect to x
+func (gp *gaussianProcess) predict(x, dMean, dVar []float64) (mean, variance float64) {
+	length, signal, _ := hyper(gp.theta)
+	n := len(gp.x)
+	ks := make([]float64, n)
+	dks := make([]float64, n)
+	for i, xi := range gp.x {
+		c, dc := gp.kernel.Corr(scaledDist2(x, xi, length))
+		ks[i] = signal * c
+		dks[i] = signal * dc
+		mean += ks[i] * gp.alpha[i]
+	}
+	w := make([]float64, n)
+	gp.chol.Solve(w, ks)
+	variance = signal
+	for i, v := range ks {
+		variance -= v * w[i]
+	}
+	variance = math.Max(variance, 0)
+	if dMean == nil {
+		return mean, variance
+	}
+	for d, l := range length {
+		dMean[d] = 0
+		dVar[d] = 0
+		for i, xi := range gp.x {
+			// Derivative of the covariance with observation i
+			dk := dks[i] * 2 * (x[d] - xi[d]) / (l * l)
+			dMean[d] += gp.alpha[i] * dk
+			dVar[d] -= 2 * w[i] * dk
+		}
+	}
+	return mean, variance
+}
@@ -0,0 +1,203 @@
+package global
+
+import (
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/projection"
+	"github.com/btracey/gofunopter/multivariate"
+
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MultistartSettings are the settings for Multistart. See NewMultistartSettings
+// for the default values
+type MultistartSettings struct {
+	NumStarts int     // Number of starting locations
+	Sampler   Sampler // Method for generating the starting locations
+	Seed      int64   // Seed for the random number generator passed to the sampler
+
+	// NewOptimizer returns a new optimizer for each local optimization.
+	// If nil, Lbfgs is used
+	NewOptimizer func() multivariate.MultiGradOptimizer
+
+	// LocalSettings are the settings for each local optimization.
+	// If nil, the defaults are used with the display off
+	LocalSettings *multivariate.MultiGradSettings
+
+	// Two local minima are considered the same if the distance between them,
+	// after scaling every coordinate by the width of the bounds, is less
+	// than ClusterTol
+	ClusterTol float64
+
+	// Concurrency is the number of local optimizations run at once. If it
+	// is more than one, the function must be safe for concurrent use
+	Concurrency int
+}
+
+func NewMultistartSettings() *MultistartSettings {
+	return &MultistartSettings{
+		NumStarts:   20,
+		Sampler:     LatinHypercube{},
+		Seed:        1,
+		ClusterTol:  1E-3,
+		Concurrency: 1,
+	}
+}
+
+// LocalMinimum is a distinct local minimum found by Multistart
+type LocalMinimum struct {
+	Location  []float64
+	Objective float64
+	Starts    []int                         // Indices of the starting locations which converged to this minimum
+	Result    *multivariate.MultiGradResult // Result of the run which found the best location
+}
+
+// MultistartResult contains the details of every local optimization
+type MultistartResult struct {
+	StartLocations      [][]float64
+	Results             []*multivariate.MultiGradResult // nil for runs which returned an error
+	Errors              []error
+	FunctionEvaluations int           // Total over all runs
+	Runtime             time.Duration // Total elapsed time
+}
+
+// Multistart runs a local optimization with multivariate.OptimizeGrad from
+// each of the starting locations generated within the bounds, which must
+// be set. The bounds are only used for generating the starting locations,
+// and the local optimizations are not constrained by them.
+// The local minima found by the runs without an error are grouped, and
+// returned sorted from the lowest objective value to the highest. An error
+// is returned only if every run returns an error
+func Multistart(function optimize.MultiObjGrad, bounds *projection.Box, settings *MultistartSettings) (minima []*LocalMinimum, result *MultistartResult, err error) {
+	if settings == nil {
+		settings = NewMultistartSettings()
+	}
+	if bounds == nil {
+		return nil, nil, errors.New("multistart: bounds must be set")
+	}
+	if settings.NumStarts <= 0 {
+		return nil, nil, errors.New("multistart: number of starts must be positive")
+	}
+	if settings.Sampler == nil {
+		return nil, nil, errors.New("multistart: sampler is nil")
+	}
+	localSettings := settings.LocalSettings
+	if localSettings == nil {
+		localSettings = multivariate.NewMultiGradSettings()
+		localSettings.Display = false
+	}
+	newOptimizer := settings.NewOptimizer
+	if newOptimizer == nil {
+		newOptimizer = func() multivariate.MultiGradOptimizer {
+			return multivariate.NewLbfgs()
+		}
+	}
+
+	start := time.Now()
+	result = &MultistartResult{
+		StartLocations: make([][]float64, settings.NumStarts),
+		Results:        make([]*multivariate.MultiGradResult, settings.NumStarts),
+		Errors:         make([]error, settings.NumStarts),
+	}
+	for i := range result.StartLocations {
+		result.StartLocations[i] = make([]float64, len(bounds.Lower))
+	}
+	rng := rand.New(rand.NewSource(settings.Seed))
+	err = settings.Sampler.Sample(result.StartLocations, bounds, rng)
+	if err != nil {
+		return nil, nil, errors.New("multistart: " + err.Error())
+	}
+
+	// Every run writes only its own entries of the result
+	runs := make([]*multivariate.MultiGradResult, settings.NumStarts)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	workers := settings.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				_, _, runs[i], result.Errors[i] = multivariate.OptimizeGrad(function, result.StartLocations[i], localSettings, newOptimizer())
+			}
+		}()
+	}
+	for i := range result.StartLocations {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, r := range runs {
+		if r != nil {
+			result.FunctionEvaluations += r.FunctionEvaluations
+		}
+		if result.Errors[i] == nil {
+			result.Results[i] = r
+		}
+	}
+	result.Runtime = time.Since(start)
+
+	minima = clusterMinima(result.Results, bounds, settings.ClusterTol)
+	if len(minima) == 0 {
+		return nil, result, errors.New("multistart: every local optimization failed. Last error: " + result.Errors[len(result.Errors)-1].Error())
+	}
+	return minima, result, nil
+}
+
+// clusterMinima groups the final locations of the runs. Runs are considered
+// from best to worst, and each joins the first existing minimum within tol
+func clusterMinima(results []*multivariate.MultiGradResult, bounds *projection.Box, tol float64) []*LocalMinimum {
+	var order []int
+	for i, r := range results {
+		if r != nil {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return optimize.Less(results[order[a]].Objective, results[order[b]].Objective)
+	})
+
+	var minima []*LocalMinimum
+	for _, i := range order {
+		r := results[i]
+		var found *LocalMinimum
+		for _, m := range minima {
+			if scaledDistance(m.Location, r.Location, bounds) < tol {
+				found = m
+				break
+			}
+		}
+		if found == nil {
+			found = &LocalMinimum{
+				Location:  r.Location,
+				Objective: r.Objective,
+				Result:    r,
+			}
+			minima = append(minima, found)
+		}
+		found.Starts = append(found.Starts, i)
+	}
+	return minima
+}
+
+// scaledDistance is the Euclidean distance between x and y after scaling
+// each coordinate by the width of the bounds
+func scaledDistance(x, y []float64, bounds *projection.Box) float64 {
+	var d float64
+	for i := range x {
+		diff := x[i] - y[i]
+		if width := bounds.Upper[i] - bounds.Lower[i]; width > 0 {
+			diff /= width
+		}
+		d += diff * diff
+	}
+	return math.Sqrt(d)
+}
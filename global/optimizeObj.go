@@ -0,0 +1,227 @@
+// Package global contains derivative-free optimizers which search for the
+// global minimum of a (possibly multimodal) function, typically within box
+// bounds
+package global
+
+import (
+	"github.com/btracey/gofunopter/common"
+	"github.com/btracey/gofunopter/common/display"
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/projection"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+	"math"
+)
+
+// moddedFun is the user defined function with the evaluation budget, and
+// the number of goroutines for evaluating batches of locations
+type moddedFun struct {
+	*optimize.LimitedMultiObj
+	workers int
+}
+
+// GlobalOptimizer is a derivative-free method which uses only function
+// values. The location and objective value set by the optimizer should be
+// the best found so far. Bounds may be nil if the optimizer does not
+// require them
+type GlobalOptimizer interface {
+	Initialize(loc *multi.Location, obj *uni.Objective, bounds *projection.Box) error
+	Iterate(loc *multi.Location, obj *uni.Objective, fun optimize.MultiObj) (status.Status, error)
+}
+
+// OptimizeObj minimizes the function within bounds. If initialLocation is
+// nil, the center of the bounds is used. The function evaluations never
+// exceed settings.MaximumFunctionEvaluations, even part way through an
+// iteration. Optimizers which evaluate many locations per iteration
+// (DifferentialEvolution, Pso, Cmaes) evaluate them together, either with
+// the user's ObjBatch if function implements optimize.MultiObjBatch, or
+// with settings.Concurrency goroutines. In the latter case the function
+// must be safe for concurrent use
+func OptimizeObj(function optimize.MultiObj, initialLocation []float64, bounds *projection.Box, settings *GlobalSettings, optimizer GlobalOptimizer) (optValue float64, optLocation []float64, result *GlobalResult, err error) {
+
+	if settings == nil {
+		settings = NewGlobalSettings()
+	}
+
+	if optimizer == nil {
+		optimizer = NewDifferentialEvolution()
+	}
+
+	if initialLocation == nil {
+		if bounds == nil {
+			return math.NaN(), nil, nil, errors.New("global: initial location and bounds are both nil")
+		}
+		initialLocation = make([]float64, len(bounds.Lower))
+		for i := range initialLocation {
+			initialLocation[i] = center(bounds.Lower[i], bounds.Upper[i])
+		}
+	}
+
+	m := newGlobalStruct()
+	m.fun = &moddedFun{
+		LimitedMultiObj: &optimize.LimitedMultiObj{
+			Fun:      function,
+			Loc:      m.loc,
+			Obj:      m.obj,
+			FunEvals: m.FunEvals,
+		},
+		workers: settings.Concurrency,
+	}
+	m.bounds = bounds
+	m.settings = settings
+	m.optimizer = optimizer
+
+	m.loc.SetInit(initialLocation)
+	err = optimize.OptimizeOpter(m, function)
+
+	return m.obj.Opt(), m.loc.Opt(), m.Result(), err
+}
+
+// center returns the midpoint of the interval, treating infinite bounds
+// as unbounded
+func center(lo, hi float64) float64 {
+	switch {
+	case math.IsInf(lo, -1) && math.IsInf(hi, 1):
+		return 0
+	case math.IsInf(lo, -1):
+		return math.Min(hi, 0)
+	case math.IsInf(hi, 1):
+		return math.Max(lo, 0)
+	}
+	return (lo + hi) / 2
+}
+
+type GlobalResult struct {
+	*common.CommonResult
+	*uni.ObjectiveResult
+	*multi.LocationResult
+}
+
+type GlobalSettings struct {
+	*common.CommonSettings
+	*uni.ObjectiveSettings
+	*multi.LocationSettings
+	Concurrency int // Number of goroutines evaluating the objective function (1 for serial evaluation)
+}
+
+func NewGlobalSettings() *GlobalSettings {
+	return &GlobalSettings{
+		CommonSettings:    common.NewCommonSettings(),
+		ObjectiveSettings: uni.NewObjectiveSettings(),
+		LocationSettings:  multi.NewLocationSettings(),
+		Concurrency:       1,
+	}
+}
+
+type globalStruct struct {
+	*common.OptCommon
+
+	loc *multi.Location
+	obj *uni.Objective
+
+	bounds *projection.Box
+
+	// User defined function
+	fun *moddedFun
+
+	// Optimization model
+	optimizer GlobalOptimizer
+
+	// Settings
+	settings *GlobalSettings
+}
+
+func newGlobalStruct() *globalStruct {
+	return &globalStruct{
+		OptCommon: common.NewOptCommon(),
+		loc:       multi.NewLocation(),
+		obj:       uni.NewObjective(),
+	}
+}
+
+func (m *globalStruct) CommonSettings() *common.CommonSettings {
+	return m.settings.CommonSettings
+}
+
+func (m *globalStruct) SetSettings() error {
+	m.obj.SetSettings(m.settings.ObjectiveSettings)
+	m.loc.SetSettings(m.settings.LocationSettings)
+	return nil
+}
+
+func (m *globalStruct) Status() status.Status {
+	c := status.CheckStatus(m.obj)
+	if c != status.Continue {
+		return c
+	}
+	statuser, ok := m.optimizer.(status.Statuser)
+	if ok {
+		return statuser.Status()
+	}
+	return c
+}
+
+// AddToDisplay displays the best location and objective value, as well as
+// any values (population statistics, etc.) from the optimizer
+func (m *globalStruct) AddToDisplay(d []*display.Struct) []*display.Struct {
+	d = display.AddToDisplay(d, m.loc, m.obj)
+	displayer, ok := m.optimizer.(display.Displayer)
+	if ok {
+		d = displayer.AddToDisplay(d)
+	}
+	return d
+}
+
+func (m *globalStruct) Result() *GlobalResult {
+	return &GlobalResult{
+		CommonResult:    m.OptCommon.CommonResult(),
+		ObjectiveResult: m.obj.Result(),
+		LocationResult:  m.loc.Result(),
+	}
+}
+
+func (m *globalStruct) SetResult() {
+	optimize.SetResult(m.loc, m.obj)
+
+	setResulter, ok := m.optimizer.(optimize.SetResulter)
+	if ok {
+		setResulter.SetResult()
+	}
+}
+
+func (m *globalStruct) Initialize() error {
+	initLoc := m.loc.Init()
+	if m.bounds != nil {
+		if len(m.bounds.Lower) != len(initLoc) || len(m.bounds.Upper) != len(initLoc) {
+			return errors.New("global: bounds and initial location have different lengths")
+		}
+		feasible, err := projection.Feasible(m.bounds, initLoc, 0)
+		if err != nil {
+			return err
+		}
+		if !feasible {
+			return errors.New("global: initial location is outside the bounds")
+		}
+	}
+
+	if math.IsNaN(m.obj.Init()) {
+		initObj, err := m.fun.Objective(initLoc)
+		if err != nil {
+			return errors.New("error calling function during optimization: \n" + err.Error())
+		}
+		m.obj.SetInit(initObj)
+	}
+
+	err := optimize.Initialize(m.loc, m.obj)
+	if err != nil {
+		return err
+	}
+	return m.optimizer.Initialize(m.loc, m.obj, m.bounds)
+}
+
+func (m *globalStruct) Iterate() (status.Status, error) {
+	return m.optimizer.Iterate(m.loc, m.obj, m.fun)
+}
@@ -0,0 +1,282 @@
+package global
+
+import (
+	"github.com/btracey/gofunopter/common/display"
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/projection"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// PsoVariant is the velocity update used by particle swarm optimization
+type PsoVariant int
+
+const (
+	// v = w * v + c1 * r1 * (p - x) + c2 * r2 * (g - x)
+	InertiaWeight PsoVariant = iota
+	// v = chi * (v + c1 * r1 * (p - x) + c2 * r2 * (g - x)) where chi is
+	// found from c1 + c2 > 4 (Clerc and Kennedy, 2002)
+	Constriction
+)
+
+// Topology is the neighbourhood from which each particle takes the best
+// location in the velocity update
+type Topology int
+
+const (
+	GlobalTopology Topology = iota // Every particle is informed by the whole swarm
+	RingTopology                   // Every particle is informed by itself and its two neighbours
+)
+
+// Pso is particle swarm optimization (Kennedy and Eberhart, 1995). Each
+// particle moves with a velocity which is attracted to its own best location
+// and the best location in its neighbourhood. The bounds must be finite.
+// Particles which leave the bounds are moved onto them and the velocity
+// component which was out of bounds is set to zero.
+// The initial location is the location of the first particle, and the rest
+// are drawn uniformly within the bounds. Each call to Iterate moves every
+// particle once, and the new locations are evaluated together (see
+// OptimizeObj). The mean and standard deviation of the objective values of
+// the particle best locations are displayed during the optimization
+type Pso struct {
+	// Tunable parameters
+	Variant     PsoVariant
+	Topology    Topology
+	SwarmSize   int     // Number of particles (if zero, 10 + 2 * sqrt(n))
+	Inertia     float64 // Inertia weight w (InertiaWeight only)
+	Cognitive   float64 // Attraction c1 to the particle best location
+	Social      float64 // Attraction c2 to the neighbourhood best location
+	MaxVelocity float64 // Maximum velocity as a fraction of the bound width in each coordinate
+	Seed        int64   // Seed for the random number generator
+
+	// Other needed variables
+	rng       *rand.Rand
+	bounds    *projection.Box
+	nDim      int
+	chi       float64
+	first     bool
+	x         [][]float64
+	v         [][]float64
+	fx        []float64
+	p         [][]float64 // best location of each particle
+	fp        []float64
+	best      int // particle with the best location over the whole swarm
+	vMax      []float64
+	bestMean  float64
+	bestStd   float64
+	neighbour []int
+}
+
+// NewPso returns a new inertia weight particle swarm optimizer with the
+// global topology. The default coefficients are those equivalent to the
+// constriction variant with c1 = c2 = 2.05
+func NewPso() *Pso {
+	return &Pso{
+		Variant:     InertiaWeight,
+		Topology:    GlobalTopology,
+		Inertia:     0.7298,
+		Cognitive:   1.49618,
+		Social:      1.49618,
+		MaxVelocity: 0.5,
+		Seed:        1,
+	}
+}
+
+// NewConstrictionPso returns a new constriction particle swarm optimizer
+// with the ring topology
+func NewConstrictionPso() *Pso {
+	p := NewPso()
+	p.Variant = Constriction
+	p.Topology = RingTopology
+	p.Cognitive = 2.05
+	p.Social = 2.05
+	return p
+}
+
+func (pso *Pso) Initialize(loc *multi.Location, obj *uni.Objective, bounds *projection.Box) error {
+	if bounds == nil {
+		return errors.New("pso: bounds must be set")
+	}
+	for i := range bounds.Lower {
+		if math.IsInf(bounds.Lower[i], 0) || math.IsInf(bounds.Upper[i], 0) {
+			return errors.New("pso: bounds must be finite")
+		}
+	}
+	if pso.Cognitive < 0 || pso.Social < 0 {
+		return errors.New("pso: attraction coefficients must be non-negative")
+	}
+	if pso.MaxVelocity <= 0 {
+		return errors.New("pso: maximum velocity must be positive")
+	}
+	switch pso.Variant {
+	case InertiaWeight:
+		pso.chi = 1
+	case Constriction:
+		phi := pso.Cognitive + pso.Social
+		if phi <= 4 {
+			return errors.New("pso: constriction requires the sum of the attraction coefficients to be more than four")
+		}
+		pso.chi = 2 / (phi - 2 + math.Sqrt(phi*phi-4*phi))
+	default:
+		return errors.New("pso: unknown variant")
+	}
+	switch pso.Topology {
+	case GlobalTopology, RingTopology:
+	default:
+		return errors.New("pso: unknown topology")
+	}
+	pso.bounds = bounds
+	pso.nDim = len(loc.Init())
+	pso.rng = rand.New(rand.NewSource(pso.Seed))
+
+	nSwarm := pso.SwarmSize
+	if nSwarm == 0 {
+		nSwarm = 10 + int(2*math.Sqrt(float64(pso.nDim)))
+	}
+	if nSwarm < 2 {
+		return errors.New("pso: swarm size must be at least two")
+	}
+
+	pso.vMax = make([]float64, pso.nDim)
+	for j := range pso.vMax {
+		pso.vMax[j] = pso.MaxVelocity * (bounds.Upper[j] - bounds.Lower[j])
+	}
+
+	pso.x = make([][]float64, nSwarm)
+	pso.v = make([][]float64, nSwarm)
+	pso.p = make([][]float64, nSwarm)
+	pso.fx = make([]float64, nSwarm)
+	pso.fp = make([]float64, nSwarm)
+	pso.neighbour = make([]int, nSwarm)
+	for i := range pso.x {
+		pso.x[i] = make([]float64, pso.nDim)
+		pso.v[i] = make([]float64, pso.nDim)
+		pso.p[i] = make([]float64, pso.nDim)
+		for j := range pso.x[i] {
+			lo, hi := bounds.Lower[j], bounds.Upper[j]
+			if i == 0 {
+				pso.x[i][j] = loc.Curr()[j]
+			} else {
+				pso.x[i][j] = lo + pso.rng.Float64()*(hi-lo)
+			}
+			// Half-difference initialization of the velocity
+			pso.v[i][j] = (lo + pso.rng.Float64()*(hi-lo) - pso.x[i][j]) / 2
+		}
+		copy(pso.p[i], pso.x[i])
+		pso.fx[i] = math.NaN()
+		pso.fp[i] = math.NaN()
+	}
+	pso.fx[0] = obj.Curr()
+	pso.fp[0] = obj.Curr()
+	pso.best = 0
+	pso.first = true
+	pso.bestMean, pso.bestStd = popStats(pso.fp)
+	return nil
+}
+
+func (pso *Pso) Iterate(loc *multi.Location, obj *uni.Objective, fun optimize.MultiObj) (status.Status, error) {
+	if pso.first {
+		// Evaluate the random initial particles
+		fs, err := evaluateBatch(fun, pso.x[1:])
+		for i, f := range fs {
+			pso.fx[i+1] = f
+			pso.update(i+1, loc, obj)
+		}
+		pso.bestMean, pso.bestStd = popStats(pso.fp)
+		if err != nil {
+			return optimize.EvalStatus("pso", err)
+		}
+		pso.first = false
+		return status.Continue, nil
+	}
+
+	// The neighbourhood bests are found before moving so every particle
+	// in the iteration sees the same information
+	for i := range pso.x {
+		pso.neighbour[i] = pso.neighbourhoodBest(i)
+	}
+	for i := range pso.x {
+		pso.move(i, pso.p[pso.neighbour[i]])
+	}
+	fs, err := evaluateBatch(fun, pso.x)
+	for i, f := range fs {
+		pso.fx[i] = f
+		pso.update(i, loc, obj)
+	}
+	pso.bestMean, pso.bestStd = popStats(pso.fp)
+	if err != nil {
+		return optimize.EvalStatus("pso", err)
+	}
+	return status.Continue, nil
+}
+
+// neighbourhoodBest returns the particle whose best location is the best
+// in the neighbourhood of particle i
+func (pso *Pso) neighbourhoodBest(i int) int {
+	if pso.Topology == GlobalTopology {
+		return pso.best
+	}
+	n := len(pso.x)
+	best := i
+	for _, j := range []int{(i + n - 1) % n, (i + 1) % n} {
+		if optimize.Less(pso.fp[j], pso.fp[best]) {
+			best = j
+		}
+	}
+	return best
+}
+
+// move updates the velocity and location of particle i
+func (pso *Pso) move(i int, g []float64) {
+	x := pso.x[i]
+	v := pso.v[i]
+	p := pso.p[i]
+	w := pso.Inertia
+	if pso.Variant == Constriction {
+		w = 1
+	}
+	for j := range x {
+		r1 := pso.rng.Float64()
+		r2 := pso.rng.Float64()
+		vj := pso.chi * (w*v[j] + pso.Cognitive*r1*(p[j]-x[j]) + pso.Social*r2*(g[j]-x[j]))
+		vj = math.Max(math.Min(vj, pso.vMax[j]), -pso.vMax[j])
+		xj := x[j] + vj
+		if xj < pso.bounds.Lower[j] {
+			xj = pso.bounds.Lower[j]
+			vj = 0
+		} else if xj > pso.bounds.Upper[j] {
+			xj = pso.bounds.Upper[j]
+			vj = 0
+		}
+		x[j] = xj
+		v[j] = vj
+	}
+}
+
+// update records the new location of particle i as its best and the best
+// overall if it is an improvement
+func (pso *Pso) update(i int, loc *multi.Location, obj *uni.Objective) {
+	if optimize.Less(pso.fx[i], pso.fp[i]) {
+		copy(pso.p[i], pso.x[i])
+		pso.fp[i] = pso.fx[i]
+	}
+	if optimize.Less(pso.fp[i], pso.fp[pso.best]) {
+		pso.best = i
+	}
+	if optimize.Less(pso.fp[pso.best], obj.Curr()) {
+		loc.SetCurr(pso.p[pso.best])
+		obj.SetCurr(pso.fp[pso.best])
+	}
+}
+
+func (pso *Pso) AddToDisplay(d []*display.Struct) []*display.Struct {
+	return append(d,
+		&display.Struct{Value: pso.bestMean, Heading: "BestMean"},
+		&display.Struct{Value: pso.bestStd, Heading: "BestStd"},
+	)
+}
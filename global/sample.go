@@ -0,0 +1,163 @@
+package global
+
+import (
+	"github.com/btracey/gofunopter/common/projection"
+
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// Sampler generates locations within finite bounds. Sample fills every row
+// of dst (which must have the length of the bounds)
+type Sampler interface {
+	Sample(dst [][]float64, bounds *projection.Box, rng *rand.Rand) error
+}
+
+// checkSampleBounds verifies that the bounds are finite and match dst
+func checkSampleBounds(dst [][]float64, bounds *projection.Box) error {
+	if bounds == nil {
+		return errors.New("sample: bounds must be set")
+	}
+	for i := range bounds.Lower {
+		if math.IsInf(bounds.Lower[i], 0) || math.IsInf(bounds.Upper[i], 0) {
+			return errors.New("sample: bounds must be finite")
+		}
+	}
+	for _, x := range dst {
+		if len(x) != len(bounds.Lower) {
+			return errors.New("sample: location length does not match the bounds")
+		}
+	}
+	return nil
+}
+
+// Uniform samples every location independently and uniformly
+type Uniform struct{}
+
+func (Uniform) Sample(dst [][]float64, bounds *projection.Box, rng *rand.Rand) error {
+	err := checkSampleBounds(dst, bounds)
+	if err != nil {
+		return err
+	}
+	for _, x := range dst {
+		for j := range x {
+			lo, hi := bounds.Lower[j], bounds.Upper[j]
+			x[j] = lo + rng.Float64()*(hi-lo)
+		}
+	}
+	return nil
+}
+
+// LatinHypercube splits every coordinate into len(dst) equal intervals and
+// places exactly one location in each interval of each coordinate
+type LatinHypercube struct{}
+
+func (LatinHypercube) Sample(dst [][]float64, bounds *projection.Box, rng *rand.Rand) error {
+	err := checkSampleBounds(dst, bounds)
+	if err != nil {
+		return err
+	}
+	n := len(dst)
+	for j := range bounds.Lower {
+		lo, hi := bounds.Lower[j], bounds.Upper[j]
+		perm := rng.Perm(n)
+		for i, x := range dst {
+			u := (float64(perm[i]) + rng.Float64()) / float64(n)
+			x[j] = lo + u*(hi-lo)
+		}
+	}
+	return nil
+}
+
+// sobolDirections are the primitive polynomials and initial direction
+// numbers of Joe and Kuo (2008) for dimensions 2 and above. Each entry is
+// the degree s, the polynomial coefficients a, and the s values of m
+var sobolDirections = []struct {
+	s int
+	a uint32
+	m []uint32
+}{
+	{1, 0, []uint32{1}},
+	{2, 1, []uint32{1, 3}},
+	{3, 1, []uint32{1, 3, 1}},
+	{3, 2, []uint32{1, 1, 1}},
+	{4, 1, []uint32{1, 1, 3, 3}},
+	{4, 4, []uint32{1, 3, 5, 13}},
+	{5, 2, []uint32{1, 1, 5, 5, 17}},
+	{5, 4, []uint32{1, 1, 5, 5, 5}},
+	{5, 7, []uint32{1, 1, 7, 11, 19}},
+	{5, 11, []uint32{1, 1, 5, 1, 1}},
+	{5, 13, []uint32{1, 1, 1, 3, 11}},
+	{5, 14, []uint32{1, 3, 5, 5, 31}},
+	{6, 1, []uint32{1, 3, 3, 9, 7, 49}},
+	{6, 13, []uint32{1, 1, 1, 15, 21, 21}},
+	{6, 16, []uint32{1, 3, 1, 13, 27, 49}},
+	{6, 19, []uint32{1, 1, 1, 15, 7, 5}},
+	{6, 22, []uint32{1, 3, 1, 15, 13, 25}},
+	{6, 25, []uint32{1, 1, 5, 5, 19, 61}},
+	{7, 1, []uint32{1, 3, 7, 11, 23, 15, 103}},
+	{7, 4, []uint32{1, 3, 7, 13, 13, 15, 69}},
+}
+
+// MaxSobolDim is the largest dimension supported by Sobol
+const MaxSobolDim = 21
+
+const sobolBits = 32
+
+// Sobol is the low-discrepancy sequence of Sobol (1967) with the direction
+// numbers of Joe and Kuo (2008), generated in Gray code order. The sequence
+// starts from the lower corner of the bounds so that every power of two
+// locations is evenly stratified. The sequence is deterministic, so the
+// random number generator is not used
+type Sobol struct{}
+
+func (Sobol) Sample(dst [][]float64, bounds *projection.Box, rng *rand.Rand) error {
+	err := checkSampleBounds(dst, bounds)
+	if err != nil {
+		return err
+	}
+	nDim := len(bounds.Lower)
+	if nDim > MaxSobolDim {
+		return errors.New("sample: dimension too large for the Sobol sequence")
+	}
+
+	// Direction numbers scaled to 32 bit integers
+	v := make([][sobolBits + 1]uint32, nDim)
+	for k := 1; k <= sobolBits; k++ {
+		v[0][k] = 1 << uint(sobolBits-k)
+	}
+	for j := 1; j < nDim; j++ {
+		dir := sobolDirections[j-1]
+		s := dir.s
+		for k := 1; k <= s && k <= sobolBits; k++ {
+			v[j][k] = dir.m[k-1] << uint(sobolBits-k)
+		}
+		for k := s + 1; k <= sobolBits; k++ {
+			v[j][k] = v[j][k-s] ^ (v[j][k-s] >> uint(s))
+			for i := 1; i < s; i++ {
+				v[j][k] ^= ((dir.a >> uint(s-1-i)) & 1) * v[j][k-i]
+			}
+		}
+	}
+
+	x := make([]uint32, nDim)
+	scale := math.Pow(2, -sobolBits)
+	for n := range dst {
+		if n > 0 {
+			// Rightmost zero bit of the previous index
+			c := 1
+			for idx := n - 1; idx&1 == 1; idx >>= 1 {
+				c++
+			}
+			for j := range x {
+				x[j] ^= v[j][c]
+			}
+		}
+		for j := range x {
+			lo, hi := bounds.Lower[j], bounds.Upper[j]
+			dst[n][j] = lo + float64(x[j])*scale*(hi-lo)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,642 @@
+package multivariate
+
+import (
+	"github.com/btracey/gofunopter/common/display"
+	"github.com/btracey/gofunopter/common/linalg"
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/projection"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+	"github.com/gonum/floats"
+	"math"
+)
+
+// Bobyqa is a model-based derivative-free trust region method in the
+// family of NEWUOA and BOBYQA (Powell, 2006 and 2009). A quadratic model is
+// fitted to the objective values at NumInterp interpolation points, with
+// the freedom in the Hessian taken up by making the least Frobenius norm
+// change to the previous Hessian. Each iteration minimizes the model in the
+// trust region (and within Bounds, if set) by truncated conjugate gradient,
+// evaluates the objective at the new location, and replaces one of the
+// interpolation points. Points far from the best location are moved to
+// improve the geometry of the set when the model is not accurate.
+// The resolution rho starts at RhoBegin and is decreased until it reaches
+// RhoEnd, at which point the optimization ends with status.StepAbsTol.
+// The bounds must contain the initial location, and RhoBegin is reduced if
+// needed so that every width of the bounds is at least 4 * RhoBegin. Every
+// location evaluated is within the bounds
+type Bobyqa struct {
+	// Tunable parameters
+	Bounds    *projection.Box // Bounds on the location (nil for unconstrained)
+	NumInterp int             // Number of interpolation points, between n + 2 and (n + 1)(n + 2) / 2 (if zero, 2n + 1)
+	RhoBegin  float64         // Initial trust region radius
+	RhoEnd    float64         // Final trust region radius
+
+	// Other needed variables
+	nDim     int
+	first    bool
+	lo       []float64
+	hi       []float64
+	y        [][]float64 // interpolation points
+	f        []float64   // objective values at the interpolation points
+	kopt     int         // index of the best interpolation point
+	g        []float64   // model gradient at y[kopt]
+	h        [][]float64 // model Hessian
+	lu       *linalg.LU  // factorization of the interpolation system
+	u        [][]float64 // interpolation points relative to y[kopt], divided by sigma
+	sigma    float64
+	rho      float64
+	delta    float64
+	geometry int // interpolation point to move at the next iteration (-1 for none)
+	done     bool
+	ratio    float64
+	s        []float64
+	xNew     []float64
+}
+
+// NewBobyqa returns a model-based derivative-free optimizer with the
+// default settings. bounds may be nil
+func NewBobyqa(bounds *projection.Box) *Bobyqa {
+	return &Bobyqa{
+		Bounds:   bounds,
+		RhoBegin: 0.5,
+		RhoEnd:   1E-6,
+	}
+}
+
+func (b *Bobyqa) Initialize(loc *multi.Location, obj *uni.Objective) error {
+	x := loc.Curr()
+	n := len(x)
+	if n == 0 {
+		return errors.New("bobyqa: zero dimensional problem")
+	}
+	m := b.NumInterp
+	if m == 0 {
+		m = 2*n + 1
+	}
+	if m < n+2 || m > (n+1)*(n+2)/2 {
+		return errors.New("bobyqa: number of interpolation points must be between n + 2 and (n + 1)(n + 2) / 2")
+	}
+	if b.RhoBegin <= 0 || b.RhoEnd <= 0 || b.RhoEnd > b.RhoBegin {
+		return errors.New("bobyqa: must have 0 < RhoEnd <= RhoBegin")
+	}
+	b.nDim = n
+	b.rho = b.RhoBegin
+	b.lo = make([]float64, n)
+	b.hi = make([]float64, n)
+	for i := range b.lo {
+		b.lo[i] = math.Inf(-1)
+		b.hi[i] = math.Inf(1)
+	}
+	if b.Bounds != nil {
+		if len(b.Bounds.Lower) != n || len(b.Bounds.Upper) != n {
+			return errors.New("bobyqa: bounds and initial location have different lengths")
+		}
+		feasible, err := projection.Feasible(b.Bounds, x, 0)
+		if err != nil {
+			return err
+		}
+		if !feasible {
+			return errors.New("bobyqa: initial location is outside the bounds")
+		}
+		copy(b.lo, b.Bounds.Lower)
+		copy(b.hi, b.Bounds.Upper)
+		for i := range b.lo {
+			width := b.hi[i] - b.lo[i]
+			if width <= 0 {
+				return errors.New("bobyqa: bounds must have positive width")
+			}
+			b.rho = math.Min(b.rho, width/4)
+		}
+	}
+	b.delta = b.rho
+
+	// The initial points are steps of rho along each coordinate in both
+	// directions, on one side only if a bound is near, followed by pairs
+	// of steps along two coordinates
+	b.y = make([][]float64, m)
+	b.f = make([]float64, m)
+	b.y[0] = make([]float64, n)
+	copy(b.y[0], x)
+	b.f[0] = obj.Curr()
+	first := make([]float64, n)
+	second := make([]float64, n)
+	for i := range first {
+		switch {
+		case x[i]+b.rho > b.hi[i]:
+			first[i], second[i] = -b.rho, -2*b.rho
+		case x[i]-b.rho < b.lo[i]:
+			first[i], second[i] = b.rho, 2*b.rho
+		default:
+			first[i], second[i] = b.rho, -b.rho
+		}
+	}
+	k := 1
+	for _, step := range [][]float64{first, second} {
+		for i := 0; i < n && k < m; i++ {
+			b.y[k] = make([]float64, n)
+			copy(b.y[k], x)
+			b.y[k][i] += step[i]
+			k++
+		}
+	}
+	for i := 0; i < n && k < m; i++ {
+		for j := i + 1; j < n && k < m; j++ {
+			b.y[k] = make([]float64, n)
+			copy(b.y[k], x)
+			b.y[k][i] += first[i]
+			b.y[k][j] += first[j]
+			k++
+		}
+	}
+
+	b.kopt = 0
+	b.g = make([]float64, n)
+	b.h = make([][]float64, n)
+	for i := range b.h {
+		b.h[i] = make([]float64, n)
+	}
+	b.s = make([]float64, n)
+	b.xNew = make([]float64, n)
+	b.geometry = -1
+	b.done = false
+	b.ratio = math.NaN()
+	b.first = true
+	return nil
+}
+
+func (b *Bobyqa) Iterate(loc *multi.Location, obj *uni.Objective, fun optimize.MultiObj) (status.Status, error) {
+	if b.first {
+		// Evaluate the rest of the initial interpolation points
+		for k := 1; k < len(b.y); k++ {
+			f, err := fun.Objective(b.y[k])
+			if err != nil {
+				// Keep the best of the points evaluated so far
+				b.setBest(loc, obj)
+				return optimize.EvalStatus("bobyqa", err)
+			}
+			b.f[k] = f
+			if optimize.Less(f, b.f[b.kopt]) {
+				b.kopt = k
+			}
+		}
+		b.first = false
+		b.setBest(loc, obj)
+		err := b.buildModel()
+		if err != nil {
+			return status.OptimizerError, err
+		}
+		return status.Continue, nil
+	}
+
+	if b.geometry >= 0 {
+		t := b.geometry
+		b.geometry = -1
+		return b.improveGeometry(t, loc, obj, fun)
+	}
+
+	xOpt := b.y[b.kopt]
+	lo := make([]float64, b.nDim)
+	hi := make([]float64, b.nDim)
+	for i := range lo {
+		lo[i] = b.lo[i] - xOpt[i]
+		hi[i] = b.hi[i] - xOpt[i]
+	}
+	trustRegionStep(b.s, b.g, b.h, b.delta, lo, hi)
+	sNorm := floats.Norm(b.s, 2)
+	if sNorm < 0.5*b.rho {
+		// The model predicts little progress at this resolution. Fix the
+		// geometry if it is poor, otherwise reduce the resolution
+		t, dist := b.farthest()
+		if dist > 2*b.delta {
+			return b.improveGeometry(t, loc, obj, fun)
+		}
+		b.reduceRho()
+		return status.Continue, nil
+	}
+
+	pred := -b.modelChange(b.s)
+	for i, v := range b.s {
+		b.xNew[i] = math.Max(b.lo[i], math.Min(b.hi[i], xOpt[i]+v))
+	}
+	fNew, err := fun.Objective(b.xNew)
+	if err != nil {
+		return optimize.EvalStatus("bobyqa", err)
+	}
+	b.ratio = -1
+	if pred > 0 {
+		b.ratio = (b.f[b.kopt] - fNew) / pred
+	}
+	switch {
+	case b.ratio <= 0.1:
+		b.delta = 0.5 * sNorm
+	case b.ratio <= 0.7:
+		b.delta = math.Max(0.5*b.delta, sNorm)
+	default:
+		b.delta = math.Max(0.5*b.delta, 2*sNorm)
+	}
+	if b.delta <= 1.5*b.rho {
+		b.delta = b.rho
+	}
+
+	improved := optimize.Less(fNew, b.f[b.kopt])
+	t := b.replacement(b.s, improved)
+	copy(b.y[t], b.xNew)
+	b.f[t] = fNew
+	if improved {
+		b.kopt = t
+		b.setBest(loc, obj)
+	}
+	err = b.buildModel()
+	if err != nil {
+		return status.OptimizerError, err
+	}
+
+	if b.ratio < 0.1 {
+		far, dist := b.farthest()
+		if dist > 2*b.delta {
+			b.geometry = far
+		} else if b.ratio <= 0 && b.delta <= b.rho {
+			b.reduceRho()
+		}
+	}
+	return status.Continue, nil
+}
+
+// setBest sets the location and objective to those of the best
+// interpolation point
+func (b *Bobyqa) setBest(loc *multi.Location, obj *uni.Objective) {
+	if optimize.Less(b.f[b.kopt], obj.Curr()) {
+		loc.SetCurr(b.y[b.kopt])
+		obj.SetCurr(b.f[b.kopt])
+	}
+}
+
+// reduceRho decreases the resolution as in BOBYQA, or ends the
+// optimization if it is already RhoEnd
+func (b *Bobyqa) reduceRho() {
+	if b.rho <= b.RhoEnd {
+		b.done = true
+		return
+	}
+	oldRho := b.rho
+	ratio := b.rho / b.RhoEnd
+	switch {
+	case ratio <= 16:
+		b.rho = b.RhoEnd
+	case ratio <= 250:
+		b.rho = math.Sqrt(ratio) * b.RhoEnd
+	default:
+		b.rho *= 0.1
+	}
+	b.delta = math.Max(0.5*oldRho, b.rho)
+}
+
+// farthest returns the interpolation point farthest from the best one,
+// and its distance
+func (b *Bobyqa) farthest() (t int, dist float64) {
+	xOpt := b.y[b.kopt]
+	for k, y := range b.y {
+		var d float64
+		for i, v := range y {
+			d += (v - xOpt[i]) * (v - xOpt[i])
+		}
+		if d > dist {
+			t, dist = k, d
+		}
+	}
+	return t, math.Sqrt(dist)
+}
+
+// modelChange returns the change in the model from y[kopt] to y[kopt] + s
+func (b *Bobyqa) modelChange(s []float64) float64 {
+	var q float64
+	for i, v := range s {
+		var hs float64
+		for j, w := range s {
+			hs += b.h[i][j] * w
+		}
+		q += v * (b.g[i] + 0.5*hs)
+	}
+	return q
+}
+
+// buildModel fits the quadratic model to the interpolation points by
+// solving the least Frobenius norm interpolation system
+// [A E^T; E 0] [lambda; c; g] = [r; 0], where A_ij = (u_i^T u_j)^2 / 2, E has columns [1; u_j], and r is the
+// residual of the objective values after the previous quadratic model is
+// removed. The points are scaled by their largest distance from the best
+// point so the system is well conditioned at every resolution
+func (b *Bobyqa) buildModel() error {
+	n := b.nDim
+	m := len(b.y)
+	xOpt := b.y[b.kopt]
+	b.u = make([][]float64, m)
+	b.sigma = 0
+	for k, y := range b.y {
+		b.u[k] = make([]float64, n)
+		for i, v := range y {
+			b.u[k][i] = v - xOpt[i]
+		}
+		b.sigma = math.Max(b.sigma, floats.Norm(b.u[k], 2))
+	}
+	for _, u := range b.u {
+		for i := range u {
+			u[i] /= b.sigma
+		}
+	}
+
+	size := m + n + 1
+	kkt := make([][]float64, size)
+	for i := range kkt {
+		kkt[i] = make([]float64, size)
+	}
+	for i := 0; i < m; i++ {
+		for j := 0; j < m; j++ {
+			d := floats.Dot(b.u[i], b.u[j])
+			kkt[i][j] = 0.5 * d * d
+		}
+		kkt[i][m] = 1
+		kkt[m][i] = 1
+		for d, v := range b.u[i] {
+			kkt[i][m+1+d] = v
+			kkt[m+1+d][i] = v
+		}
+	}
+	var err error
+	b.lu, err = linalg.NewLU(kkt)
+	if err != nil {
+		return errors.New("bobyqa: interpolation system is singular")
+	}
+
+	// Hessian of the previous model in the scaled coordinates
+	hu := make([][]float64, n)
+	for i := range hu {
+		hu[i] = make([]float64, n)
+		for j := range hu[i] {
+			hu[i][j] = b.h[i][j] * b.sigma * b.sigma
+		}
+	}
+	rhs := make([]float64, size)
+	for k, u := range b.u {
+		var uhu float64
+		for i := range u {
+			for j := range u {
+				uhu += u[i] * hu[i][j] * u[j]
+			}
+		}
+		rhs[k] = b.f[k] - b.f[b.kopt] - 0.5*uhu
+	}
+	sol := make([]float64, size)
+	b.lu.Solve(sol, rhs)
+	for i := range hu {
+		for j := range hu[i] {
+			for k, u := range b.u {
+				hu[i][j] += sol[k] * u[i] * u[j]
+			}
+			b.h[i][j] = hu[i][j] / (b.sigma * b.sigma)
+		}
+		b.g[i] = sol[m+1+i] / b.sigma
+	}
+	return nil
+}
+
+// lagrange returns the value at y[kopt] + s of the Lagrange function of
+// interpolation point t, which is one at point t and zero at the others.
+// If grad is not nil it is filled with the gradient at y[kopt]
+func (b *Bobyqa) lagrange(t int, s, grad []float64) float64 {
+	m := len(b.y)
+	rhs := make([]float64, m+b.nDim+1)
+	rhs[t] = 1
+	sol := make([]float64, len(rhs))
+	b.lu.Solve(sol, rhs)
+	u := make([]float64, b.nDim)
+	for i, v := range s {
+		u[i] = v / b.sigma
+	}
+	l := sol[m]
+	for i, v := range u {
+		l += sol[m+1+i] * v
+	}
+	for k, uk := range b.u {
+		d := floats.Dot(uk, u)
+		l += 0.5 * sol[k] * d * d
+	}
+	if grad != nil {
+		for i := range grad {
+			grad[i] = sol[m+1+i] / b.sigma
+		}
+	}
+	return l
+}
+
+// replacement returns the interpolation point to be replaced by
+// y[kopt] + s. Points with a large Lagrange function at the new point keep
+// the interpolation system well conditioned, and points far from the best
+// point are preferred. The best point is kept unless the new point improves
+// on it
+func (b *Bobyqa) replacement(s []float64, improved bool) int {
+	xOpt := b.y[b.kopt]
+	t := -1
+	var best float64
+	for k, y := range b.y {
+		if k == b.kopt && !improved {
+			continue
+		}
+		var d float64
+		for i, v := range y {
+			d += (v - xOpt[i]) * (v - xOpt[i])
+		}
+		w := math.Abs(b.lagrange(k, s, nil)) * math.Max(1, d/(b.delta*b.delta))
+		if t == -1 || w > best {
+			t, best = k, w
+		}
+	}
+	return t
+}
+
+// improveGeometry replaces interpolation point t by a point near the best
+// point at which the Lagrange function of t is large
+func (b *Bobyqa) improveGeometry(t int, loc *multi.Location, obj *uni.Objective, fun optimize.MultiObj) (status.Status, error) {
+	xOpt := b.y[b.kopt]
+	_, dist := b.farthest()
+	radius := math.Max(0.1*math.Min(2*b.delta, dist), b.rho)
+
+	// Candidate steps along the coordinates and the gradient of the
+	// Lagrange function, moved onto the bounds
+	grad := make([]float64, b.nDim)
+	b.lagrange(t, make([]float64, b.nDim), grad)
+	var dirs [][]float64
+	for i := 0; i < b.nDim; i++ {
+		d := make([]float64, b.nDim)
+		d[i] = 1
+		dirs = append(dirs, d)
+	}
+	if gNorm := floats.Norm(grad, 2); gNorm > 0 {
+		for i := range grad {
+			grad[i] /= gNorm
+		}
+		dirs = append(dirs, grad)
+	}
+	step := make([]float64, b.nDim)
+	var bestL float64
+	for _, d := range dirs {
+		for _, sign := range []float64{1, -1} {
+			for i, v := range d {
+				x := math.Max(b.lo[i], math.Min(b.hi[i], xOpt[i]+sign*radius*v))
+				step[i] = x - xOpt[i]
+			}
+			if l := math.Abs(b.lagrange(t, step, nil)); l > bestL {
+				bestL = l
+				copy(b.s, step)
+			}
+		}
+	}
+
+	for i, v := range b.s {
+		b.xNew[i] = xOpt[i] + v
+	}
+	fNew, err := fun.Objective(b.xNew)
+	if err != nil {
+		return optimize.EvalStatus("bobyqa", err)
+	}
+	copy(b.y[t], b.xNew)
+	b.f[t] = fNew
+	if optimize.Less(fNew, b.f[b.kopt]) {
+		b.kopt = t
+		b.setBest(loc, obj)
+	}
+	err = b.buildModel()
+	if err != nil {
+		return status.OptimizerError, err
+	}
+	return status.Continue, nil
+}
+
+// trustRegionStep approximately minimizes g^T s + s^T H s / 2 subject to
+// |s| <= delta and lo <= s <= hi by the truncated conjugate gradient method
+// of Steihaug (1983). When a step reaches a bound the variable is fixed and
+// the conjugate gradient iterations are restarted with the rest
+func trustRegionStep(s, g []float64, h [][]float64, delta float64, lo, hi []float64) {
+	n := len(g)
+	for i := range s {
+		s[i] = 0
+	}
+	fixed := make([]bool, n)
+	gs := make([]float64, n) // model gradient at s
+	copy(gs, g)
+	d := make([]float64, n)
+	hd := make([]float64, n)
+	tol := 1e-10 * floats.Norm(g, 2)
+
+	for restart := 0; restart <= n; restart++ {
+		var rr float64
+		for i := range d {
+			if (s[i] <= lo[i] && gs[i] > 0) || (s[i] >= hi[i] && gs[i] < 0) {
+				fixed[i] = true
+			}
+			d[i] = 0
+			if !fixed[i] {
+				d[i] = -gs[i]
+				rr += d[i] * d[i]
+			}
+		}
+		if math.Sqrt(rr) <= tol {
+			return
+		}
+
+		hitBound := false
+		for iter := 0; iter < n; iter++ {
+			var dhd, ss, sd, dd float64
+			for i := range d {
+				hd[i] = 0
+				for j, v := range d {
+					hd[i] += h[i][j] * v
+				}
+				dhd += d[i] * hd[i]
+				ss += s[i] * s[i]
+				sd += s[i] * d[i]
+				dd += d[i] * d[i]
+			}
+			// Step to the trust region boundary
+			alpha := (-sd + math.Sqrt(sd*sd+dd*(delta*delta-ss))) / dd
+			toBoundary := true
+			if dhd > 0 && rr/dhd < alpha {
+				alpha = rr / dhd
+				toBoundary = false
+			}
+			bound := -1
+			for i, v := range d {
+				var a float64
+				switch {
+				case v > 0:
+					a = (hi[i] - s[i]) / v
+				case v < 0:
+					a = (lo[i] - s[i]) / v
+				default:
+					continue
+				}
+				if a < alpha {
+					alpha = a
+					bound = i
+				}
+			}
+
+			for i := range s {
+				s[i] += alpha * d[i]
+				gs[i] += alpha * hd[i]
+			}
+			if bound >= 0 {
+				if d[bound] > 0 {
+					s[bound] = hi[bound]
+				} else {
+					s[bound] = lo[bound]
+				}
+				fixed[bound] = true
+				hitBound = true
+				break
+			}
+			if toBoundary {
+				return
+			}
+			var rrNew float64
+			for i := range gs {
+				if !fixed[i] {
+					rrNew += gs[i] * gs[i]
+				}
+			}
+			if math.Sqrt(rrNew) <= tol {
+				return
+			}
+			beta := rrNew / rr
+			for i := range d {
+				if !fixed[i] {
+					d[i] = -gs[i] + beta*d[i]
+				}
+			}
+			rr = rrNew
+		}
+		if !hitBound {
+			return
+		}
+	}
+}
+
+// Status returns status.StepAbsTol once the resolution has reached RhoEnd
+// and no further progress is predicted
+func (b *Bobyqa) Status() status.Status {
+	if b.done {
+		return status.StepAbsTol
+	}
+	return status.Continue
+}
+
+func (b *Bobyqa) AddToDisplay(d []*display.Struct) []*display.Struct {
+	return append(d,
+		&display.Struct{Value: b.rho, Heading: "Rho"},
+		&display.Struct{Value: b.delta, Heading: "Radius"},
+	)
+}
@@ -0,0 +1,318 @@
+package multivariate
+
+import (
+	"github.com/btracey/gofunopter/common/display"
+	"github.com/btracey/gofunopter/common/linesearch"
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+	"github.com/btracey/gofunopter/univariate"
+
+	"errors"
+	"github.com/gonum/floats"
+	"math"
+	"math/rand"
+)
+
+// CoordinateOrder is the order in which CoordinateDescent visits the
+// coordinates
+type CoordinateOrder int
+
+const (
+	CyclicOrder CoordinateOrder = iota // 0, 1, ..., n-1 in every sweep
+	RandomOrder                        // A new random permutation in every sweep
+	GreedyOrder                        // The coordinate with the largest (pseudo-)gradient magnitude, n times per sweep
+)
+
+// CoordinateDescent minimizes f(x) + L1Weight * ||x||_1 by successive
+// one-dimensional minimizations along the coordinates. Each minimization
+// is run with univariate.OptimizeGrad and UnivariateMethod from the current
+// value of the coordinate, and ends when the derivative is less than
+// Exactness times its initial magnitude (Exactness near zero gives exact
+// minimizations). The initial step of each minimization is the distance
+// the coordinate moved the last time it was minimized.
+// If UnivariateObjMethod is set, the minimizations are run with
+// univariate.OptimizeObj and UnivariateObjSettings instead, comparing only
+// objective values, which is more robust when the function is not smooth
+// along the coordinates. The location of these minimizations is scaled so
+// that a unit step downhill is the distance the coordinate last moved.
+// Exactness is not used, and each minimization ends by the criteria of the
+// method or of the settings, so that for GoldenSection the width tolerance
+// is relative to the last move and plays the role of Exactness.
+// With L1Weight positive, each minimization is of f plus the linear L1
+// term of the orthant of the current location (as in Owlqn), and the
+// coordinate is set to zero if the minimum is in another orthant. As with
+// Owlqn, the objective value reported is the composite function value and
+// the gradient reported is the pseudo-gradient.
+// Each call to Iterate is one sweep of n minimizations. Coordinates whose
+// (pseudo-)derivative is zero are skipped
+type CoordinateDescent struct {
+	// Tunable parameters
+	Order     CoordinateOrder
+	L1Weight  float64
+	Exactness float64 // Relative decrease in the derivative which ends a minimization
+	Seed      int64   // Seed for the random number generator (RandomOrder only)
+
+	UnivariateMethod   linesearch.LinesearchMethod
+	UnivariateSettings *univariate.UniGradSettings
+
+	UnivariateObjMethod   univariate.UniObjOptimizer // Used instead of UnivariateMethod if not nil
+	UnivariateObjSettings *univariate.UniObjSettings
+
+	// Other needed variables
+	rng        *rand.Rand
+	nDim       int
+	x          []float64
+	f          float64 // smooth function value at x
+	smoothGrad []float64
+	pseudo     []float64
+	steps      []float64 // distance each coordinate moved when last minimized
+	order      []int
+	coord      *coordinateFun
+	failures   int // minimizations without progress in the last sweep
+}
+
+// NewCoordinateDescent returns a cyclic coordinate descent optimizer
+// using Cubic for the one-dimensional minimizations
+func NewCoordinateDescent() *CoordinateDescent {
+	c := &CoordinateDescent{
+		Order:                 CyclicOrder,
+		Exactness:             1E-3,
+		Seed:                  1,
+		UnivariateMethod:      univariate.NewCubic(),
+		UnivariateSettings:    univariate.NewUniGradSettings(),
+		UnivariateObjSettings: univariate.NewUniObjSettings(),
+	}
+	c.UnivariateSettings.MaximumFunctionEvaluations = 100
+	c.UnivariateSettings.Display = false
+	c.UnivariateObjSettings.MaximumFunctionEvaluations = 100
+	c.UnivariateObjSettings.Display = false
+	return c
+}
+
+func (c *CoordinateDescent) Initialize(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient) error {
+	if c.L1Weight < 0 {
+		return errors.New("coordinate: l1 weight must be non-negative")
+	}
+	if c.Exactness < 0 || c.Exactness >= 1 {
+		return errors.New("coordinate: exactness must be in [0, 1)")
+	}
+	switch c.Order {
+	case CyclicOrder, RandomOrder, GreedyOrder:
+	default:
+		return errors.New("coordinate: unknown order")
+	}
+	if c.UnivariateObjMethod != nil {
+		if c.UnivariateObjSettings == nil {
+			return errors.New("coordinate: univariate objective settings must be set")
+		}
+	} else if c.UnivariateMethod == nil || c.UnivariateSettings == nil {
+		return errors.New("coordinate: univariate method and settings must be set")
+	}
+	c.nDim = len(loc.Init())
+	c.rng = rand.New(rand.NewSource(c.Seed))
+	c.x = make([]float64, c.nDim)
+	c.smoothGrad = make([]float64, c.nDim)
+	c.pseudo = make([]float64, c.nDim)
+	c.steps = make([]float64, c.nDim)
+	c.order = make([]int, c.nDim)
+	for i := range c.steps {
+		c.steps[i] = 1
+		c.order[i] = i
+	}
+	c.coord = &coordinateFun{
+		xTrial: make([]float64, c.nDim),
+		grad:   make([]float64, c.nDim),
+	}
+
+	copy(c.x, loc.Curr())
+	c.f = obj.Curr()
+	copy(c.smoothGrad, grad.Curr())
+	pseudoGradient(c.pseudo, c.x, c.smoothGrad, c.L1Weight)
+	obj.SetCurr(c.f + c.L1Weight*floats.Norm(c.x, 1))
+	grad.SetCurr(c.pseudo)
+	return nil
+}
+
+func (c *CoordinateDescent) Iterate(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient, fun optimize.MultiObjGrad) (status.Status, error) {
+	if c.Order == RandomOrder {
+		for i, j := range c.rng.Perm(c.nDim) {
+			c.order[i] = j
+		}
+	}
+	c.failures = 0
+	var progress bool
+	var lastErr error
+	for k := 0; k < c.nDim; k++ {
+		i := c.order[k]
+		if c.Order == GreedyOrder {
+			i = floats.MaxIdx(absSlice(c.pseudo))
+		}
+		improved, err := c.minimize(i, fun)
+		if err != nil {
+			lastErr = err
+		}
+		if improved {
+			progress = true
+		} else if c.pseudo[i] != 0 {
+			c.failures++
+			if c.Order == GreedyOrder {
+				// The same coordinate would be chosen again
+				break
+			}
+		}
+	}
+
+	loc.SetCurr(c.x)
+	obj.SetCurr(c.f + c.L1Weight*floats.Norm(c.x, 1))
+	grad.SetCurr(c.pseudo)
+	if !progress && lastErr != nil {
+		return status.LinesearchFailure, errors.New("coordinate: no progress in any coordinate: " + lastErr.Error())
+	}
+	return status.Continue, nil
+}
+
+func absSlice(x []float64) []float64 {
+	a := make([]float64, len(x))
+	for i, v := range x {
+		a[i] = math.Abs(v)
+	}
+	return a
+}
+
+// minimize runs the one-dimensional minimization along coordinate i and
+// returns true if the location was improved
+func (c *CoordinateDescent) minimize(i int, fun optimize.MultiObjGrad) (bool, error) {
+	d := c.pseudo[i]
+	if d == 0 {
+		return false, nil
+	}
+	x0 := c.x[i]
+	// The orthant of the minimization is that of x0, or if x0 is zero,
+	// the direction of descent
+	sign := 1.0
+	if x0 < 0 || (x0 == 0 && d > 0) {
+		sign = -1
+	}
+	coef := c.L1Weight * sign
+
+	cf := c.coord
+	cf.fun = fun
+	cf.x = c.x
+	cf.i = i
+	cf.coef = coef
+	cf.bestT = 0
+	cf.bestF = c.f + coef*x0
+	cf.improved = false
+
+	// Copy the settings, so that those of the user are kept for the
+	// next coordinate
+	var err error
+	if c.UnivariateObjMethod != nil {
+		settings := *c.UnivariateObjSettings
+		objSettings := *settings.ObjectiveSettings
+		settings.ObjectiveSettings = &objSettings
+		settings.InitialObjective = cf.bestF
+		scale := c.steps[i]
+		if d > 0 {
+			scale = -scale
+		}
+		_, _, _, err = univariate.OptimizeObj(coordinateObj{cf, scale}, 0, &settings, c.UnivariateObjMethod)
+	} else {
+		settings := *c.UnivariateSettings
+		objSettings := *settings.ObjectiveSettings
+		settings.ObjectiveSettings = &objSettings
+		gradSettings := *settings.GradientSettings
+		settings.GradientSettings = &gradSettings
+		settings.InitialObjective = cf.bestF
+		settings.InitialGradient = c.smoothGrad[i] + coef
+		settings.GradientAbsoluteTolerance = c.Exactness * math.Abs(settings.InitialGradient)
+		c.UnivariateMethod.Step().SetInit(c.steps[i])
+		_, _, _, err = univariate.OptimizeGrad(cf, 0, &settings, c.UnivariateMethod)
+	}
+	if !cf.improved {
+		return false, err
+	}
+
+	xNew := x0 + cf.bestT
+	f := cf.bestF - coef*xNew
+	g := cf.grad
+	if coef != 0 && xNew*sign < 0 {
+		// The minimum is in another orthant, so stop at zero
+		xNew = 0
+		copy(cf.xTrial, c.x)
+		cf.xTrial[i] = 0
+		var gNew []float64
+		f, gNew, err = fun.ObjGrad(cf.xTrial)
+		if err != nil {
+			return false, err
+		}
+		if len(gNew) != c.nDim {
+			return false, errors.New("coordinate: user defined function returned incorrect gradient length")
+		}
+		if !(f+c.L1Weight*math.Abs(xNew) < c.f+c.L1Weight*math.Abs(x0)) {
+			return false, nil
+		}
+		g = gNew
+	}
+	c.steps[i] = math.Abs(xNew - x0)
+	c.x[i] = xNew
+	c.f = f
+	copy(c.smoothGrad, g)
+	pseudoGradient(c.pseudo, c.x, c.smoothGrad, c.L1Weight)
+	return true, nil
+}
+
+// coordinateFun is f plus the linear L1 term along one coordinate, as a
+// function of the change in the coordinate. The best location evaluated
+// and its full gradient are kept
+type coordinateFun struct {
+	fun      optimize.MultiObjGrad
+	x        []float64
+	xTrial   []float64
+	i        int
+	coef     float64
+	bestT    float64
+	bestF    float64
+	grad     []float64 // smooth gradient at the best location
+	improved bool
+}
+
+func (cf *coordinateFun) ObjGrad(t float64) (f float64, g float64, err error) {
+	copy(cf.xTrial, cf.x)
+	cf.xTrial[cf.i] += t
+	xi := cf.xTrial[cf.i]
+	f, grad, err := cf.fun.ObjGrad(cf.xTrial)
+	if err != nil {
+		return f, g, err
+	}
+	if len(grad) != len(cf.x) {
+		return f, g, errors.New("coordinate: user defined function returned incorrect gradient length")
+	}
+	f += cf.coef * xi
+	g = grad[cf.i] + cf.coef
+	if f < cf.bestF {
+		cf.bestF = f
+		cf.bestT = t
+		copy(cf.grad, grad)
+		cf.improved = true
+	}
+	return f, g, nil
+}
+
+// coordinateObj is coordinateFun as a function of the change in the
+// coordinate divided by scale, for the derivative-free minimizations
+type coordinateObj struct {
+	*coordinateFun
+	scale float64
+}
+
+func (co coordinateObj) Objective(s float64) (float64, error) {
+	f, _, err := co.ObjGrad(co.scale * s)
+	return f, err
+}
+
+func (c *CoordinateDescent) AddToDisplay(d []*display.Struct) []*display.Struct {
+	return append(d, &display.Struct{Value: float64(c.failures), Heading: "CoordFail"})
+}
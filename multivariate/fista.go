@@ -0,0 +1,199 @@
+package multivariate
+
+import (
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/proximal"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+	"github.com/gonum/floats"
+	"math"
+)
+
+// Fista is a proximal gradient method for minimizing f(x) + g(x) where
+// f is the smooth user defined function and g is the non-smooth function
+// given by Prox. With Accelerate false it is ISTA, otherwise it is the
+// accelerated method of Beck and Teboulle (2009).
+// The step size 1/L is found by backtracking on the Lipschitz estimate L.
+// If Restart is true, the momentum is reset whenever it points uphill
+// (O'Donoghue and Candes, 2012).
+// The objective value reported is the composite f(x) + g(x), while the
+// gradient is that of f alone. Fista converges with status.ProjGradAbsTol
+// when the norm of the gradient mapping L * (y - prox(y - grad f(y) / L))
+// is less than the gradient absolute tolerance
+type Fista struct {
+	// Tunable parameters
+	Prox              proximal.Proximal
+	Accelerate        bool    // Use Nesterov momentum (FISTA)
+	Restart           bool    // Use adaptive restart of the momentum
+	InitialLipschitz  float64 // Initial estimate of the Lipschitz constant of grad f
+	BacktrackFactor   float64 // Multiplier on the Lipschitz estimate when the quadratic upper bound fails
+	LipschitzDecrease float64 // Multiplier on the Lipschitz estimate at the start of each iteration (1 for none)
+	MaxBacktracks     int     // Maximum number of function evaluations per iteration
+
+	// Other needed variables
+	tol         float64
+	gradMapNorm float64
+	lipschitz   float64
+	t           float64
+	nDim        int
+	yIsX        bool // Is the extrapolated point the same as the current location
+	y           []float64
+	fy          float64
+	gy          []float64
+	z           []float64
+	zPrev       []float64
+	diff        []float64
+}
+
+// NewFista returns an accelerated proximal gradient optimizer with
+// adaptive restart for the non-smooth function p
+func NewFista(p proximal.Proximal) *Fista {
+	return &Fista{
+		Prox:              p,
+		Accelerate:        true,
+		Restart:           true,
+		InitialLipschitz:  1,
+		BacktrackFactor:   2,
+		LipschitzDecrease: 1,
+		MaxBacktracks:     100,
+	}
+}
+
+// NewIsta returns an unaccelerated proximal gradient optimizer
+// for the non-smooth function p
+func NewIsta(p proximal.Proximal) *Fista {
+	f := NewFista(p)
+	f.Accelerate = false
+	f.Restart = false
+	return f
+}
+
+func (fista *Fista) Initialize(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient) error {
+	if fista.Prox == nil {
+		return errors.New("fista: proximal function is nil")
+	}
+	if fista.InitialLipschitz <= 0 {
+		return errors.New("fista: initial lipschitz estimate must be positive")
+	}
+	if fista.BacktrackFactor <= 1 {
+		return errors.New("fista: backtrack factor must be greater than one")
+	}
+	fista.nDim = len(loc.Init())
+
+	// The gradient of f does not go to zero at the minimum of f + g.
+	// Use the tolerance on the gradient mapping instead
+	fista.tol = grad.AbsTol()
+	grad.SetAbsTol(0)
+
+	fista.lipschitz = fista.InitialLipschitz
+	fista.t = 1
+	fista.gradMapNorm = math.Inf(1)
+
+	fista.y = make([]float64, fista.nDim)
+	fista.gy = make([]float64, fista.nDim)
+	fista.z = make([]float64, fista.nDim)
+	fista.zPrev = make([]float64, fista.nDim)
+	fista.diff = make([]float64, fista.nDim)
+
+	copy(fista.y, loc.Curr())
+	copy(fista.gy, grad.Curr())
+	fista.fy = obj.Curr()
+	fista.yIsX = true
+
+	obj.SetCurr(fista.fy + fista.Prox.Value(loc.Curr()))
+	return nil
+}
+
+func (fista *Fista) Status() status.Status {
+	if fista.gradMapNorm < fista.tol {
+		return status.ProjGradAbsTol
+	}
+	return status.Continue
+}
+
+func (fista *Fista) Iterate(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient, fun optimize.MultiObjGrad) (status.Status, error) {
+	// Evaluate the smooth function at the extrapolated point if necessary
+	if !fista.yIsX {
+		fy, gy, err := fun.ObjGrad(fista.y)
+		if err != nil {
+			return status.UserFunctionError, errors.New("fista: user defined function error: " + err.Error())
+		}
+		if len(gy) != fista.nDim {
+			return status.UserFunctionError, errors.New("fista: user defined function returned incorrect gradient length")
+		}
+		fista.fy = fy
+		copy(fista.gy, gy)
+	}
+
+	// Backtrack until the quadratic upper bound at the prox step holds
+	fista.lipschitz *= fista.LipschitzDecrease
+	var fz float64
+	var gz []float64
+	for i := 0; ; i++ {
+		if i == fista.MaxBacktracks {
+			return status.LinesearchFailure, errors.New("fista: maximum number of backtracking steps reached")
+		}
+		step := 1 / fista.lipschitz
+		for j := range fista.z {
+			fista.z[j] = fista.y[j] - step*fista.gy[j]
+		}
+		err := fista.Prox.Prox(fista.z, step)
+		if err != nil {
+			return status.OptimizerError, errors.New("fista: error during proximal step: " + err.Error())
+		}
+		fz, gz, err = fun.ObjGrad(fista.z)
+		if err != nil {
+			return status.UserFunctionError, errors.New("fista: user defined function error: " + err.Error())
+		}
+		if len(gz) != fista.nDim {
+			return status.UserFunctionError, errors.New("fista: user defined function returned incorrect gradient length")
+		}
+		floats.SubTo(fista.diff, fista.z, fista.y)
+		normDiff := floats.Norm(fista.diff, 2)
+		bound := fista.fy + floats.Dot(fista.gy, fista.diff) + fista.lipschitz/2*normDiff*normDiff
+		if fz <= bound {
+			fista.gradMapNorm = fista.lipschitz * normDiff
+			break
+		}
+		fista.lipschitz *= fista.BacktrackFactor
+	}
+
+	// Find the next extrapolated point
+	copy(fista.zPrev, loc.Curr())
+	restart := !fista.Accelerate
+	if fista.Accelerate && fista.Restart {
+		// Restart if the momentum opposes the gradient mapping
+		var dot float64
+		for j := range fista.z {
+			dot += (fista.y[j] - fista.z[j]) * (fista.z[j] - fista.zPrev[j])
+		}
+		restart = dot > 0
+	}
+	if restart {
+		fista.t = 1
+		copy(fista.y, fista.z)
+		fista.fy = fz
+		copy(fista.gy, gz)
+		fista.yIsX = true
+	} else {
+		tNext := (1 + math.Sqrt(1+4*fista.t*fista.t)) / 2
+		beta := (fista.t - 1) / tNext
+		for j := range fista.y {
+			fista.y[j] = fista.z[j] + beta*(fista.z[j]-fista.zPrev[j])
+		}
+		fista.t = tNext
+		fista.yIsX = beta == 0
+		if fista.yIsX {
+			fista.fy = fz
+			copy(fista.gy, gz)
+		}
+	}
+
+	loc.SetCurr(fista.z)
+	obj.SetCurr(fz + fista.Prox.Value(fista.z))
+	grad.SetCurr(gz)
+	return status.Continue, nil
+}
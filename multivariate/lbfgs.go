@@ -75,7 +75,55 @@ func (lbfgs *Lbfgs) Initialize(loc *multi.Location, obj *uni.Objective, grad *mu
 	}
 
 	// Initialize rest of memory
+	lbfgs.initHist()
+	return nil
+}
 
+func (lbfgs *Lbfgs) Iterate(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient, fun optimize.MultiObjGrad) (status.Status, error) {
+	p_k := lbfgs.p_k
+	s_k := lbfgs.s_k
+	y_k := lbfgs.y_k
+
+	// Calculate search direction
+	lbfgs.searchDirection(p_k, grad.Curr())
+	normP_k := floats.Norm(p_k, 2)
+
+	// Perform line search -- need to find some way to implement this, especially bookkeeping function values
+	linesearchResult, err := linesearch.Linesearch(fun, lbfgs.LinesearchMethod, lbfgs.LinesearchSettings, lbfgs.Wolfe, p_k, loc.Curr(), obj.Curr(), grad.Curr())
+
+	// In the future add a check to switch to a different linesearcher?
+	if err != nil {
+		return status.LinesearchFailure, err
+	}
+	x_kp1 := linesearchResult.Loc
+	f_kp1 := linesearchResult.Obj
+	g_kp1 := linesearchResult.Grad
+	alpha_k := linesearchResult.Step
+
+	// Update hessian estimate
+	copy(s_k, p_k)
+	floats.Scale(alpha_k, s_k)
+
+	copy(y_k, g_kp1)
+	floats.Sub(y_k, grad.Curr())
+
+	// Bookkeep the results
+	stepSize := alpha_k * normP_k
+	lbfgs.step.AddToHist(stepSize)
+	lbfgs.step.SetCurr(stepSize)
+	loc.SetCurr(x_kp1)
+	//lbfgs.loc.AddToHist(x_kp1)
+
+	//fmt.Println(lbfgs.loc.GetHist())
+	obj.SetCurr(f_kp1)
+	grad.SetCurr(g_kp1)
+
+	lbfgs.updateHist(s_k, y_k)
+	return status.Continue, nil
+}
+
+// initHist allocates the memory for the two-loop recursion
+func (lbfgs *Lbfgs) initHist() {
 	// Replace this with overwriting?
 	lbfgs.q = make([]float64, lbfgs.nDim)
 	lbfgs.a = make([]float64, lbfgs.NumStore)
@@ -90,16 +138,18 @@ func (lbfgs *Lbfgs) Initialize(loc *multi.Location, obj *uni.Objective, grad *mu
 	}
 
 	lbfgs.gamma_k = 1.0
+	lbfgs.counter = 0
 
 	lbfgs.tmp = make([]float64, lbfgs.nDim)
 	lbfgs.p_k = make([]float64, lbfgs.nDim)
 	lbfgs.s_k = make([]float64, lbfgs.nDim)
 	lbfgs.y_k = make([]float64, lbfgs.nDim)
 	lbfgs.z = make([]float64, lbfgs.nDim)
-	return nil
 }
 
-func (lbfgs *Lbfgs) Iterate(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient, fun optimize.MultiObjGrad) (status.Status, error) {
+// searchDirection computes the quasi-newton direction -H * g using the
+// two-loop recursion and stores it in p_k
+func (lbfgs *Lbfgs) searchDirection(p_k, g []float64) {
 	counter := lbfgs.counter
 	q := lbfgs.q
 	a := lbfgs.a
@@ -109,13 +159,9 @@ func (lbfgs *Lbfgs) Iterate(loc *multi.Location, obj *uni.Objective, grad *multi
 	yHist := lbfgs.yHist
 	gamma_k := lbfgs.gamma_k
 	tmp := lbfgs.tmp
-	p_k := lbfgs.p_k
-	s_k := lbfgs.s_k
-	y_k := lbfgs.y_k
 	z := lbfgs.z
 
-	// Calculate search direction
-	for i, val := range grad.Curr() {
+	for i, val := range g {
 		q[i] = val
 	}
 	for i := counter - 1; i >= 0; i-- {
@@ -155,42 +201,16 @@ func (lbfgs *Lbfgs) Iterate(loc *multi.Location, obj *uni.Objective, grad *multi
 
 	copy(p_k, z)
 	floats.Scale(-1, p_k)
-	normP_k := floats.Norm(p_k, 2)
-
-	// Perform line search -- need to find some way to implement this, especially bookkeeping function values
-	linesearchResult, err := linesearch.Linesearch(fun, lbfgs.LinesearchMethod, lbfgs.LinesearchSettings, lbfgs.Wolfe, p_k, loc.Curr(), obj.Curr(), grad.Curr())
-
-	// In the future add a check to switch to a different linesearcher?
-	if err != nil {
-		return status.LinesearchFailure, err
-	}
-	x_kp1 := linesearchResult.Loc
-	f_kp1 := linesearchResult.Obj
-	g_kp1 := linesearchResult.Grad
-	alpha_k := linesearchResult.Step
-
-	// Update hessian estimate
-	copy(s_k, p_k)
-	floats.Scale(alpha_k, s_k)
+}
 
-	copy(y_k, g_kp1)
-	floats.Sub(y_k, grad.Curr())
+// updateHist adds the step s_k and the change in gradient y_k to the
+// stored history
+func (lbfgs *Lbfgs) updateHist(s_k, y_k []float64) {
 	skDotYk := floats.Dot(s_k, y_k)
 
-	// Bookkeep the results
-	stepSize := alpha_k * normP_k
-	lbfgs.step.AddToHist(stepSize)
-	lbfgs.step.SetCurr(stepSize)
-	loc.SetCurr(x_kp1)
-	//lbfgs.loc.AddToHist(x_kp1)
-
-	//fmt.Println(lbfgs.loc.GetHist())
-	obj.SetCurr(f_kp1)
-	grad.SetCurr(g_kp1)
-
-	copy(sHist[counter], s_k)
-	copy(yHist[counter], y_k)
-	rhoHist[counter] = 1 / skDotYk
+	copy(lbfgs.sHist[lbfgs.counter], s_k)
+	copy(lbfgs.yHist[lbfgs.counter], y_k)
+	lbfgs.rhoHist[lbfgs.counter] = 1 / skDotYk
 
 	lbfgs.gamma_k = skDotYk / floats.Dot(y_k, y_k)
 
@@ -198,5 +218,4 @@ func (lbfgs *Lbfgs) Iterate(loc *multi.Location, obj *uni.Objective, grad *multi
 	if lbfgs.counter == lbfgs.NumStore {
 		lbfgs.counter = 0
 	}
-	return status.Continue, nil
 }
@@ -2,7 +2,10 @@ package multivariate
 
 import (
 	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/projection"
+	"github.com/btracey/gofunopter/common/proximal"
 	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/univariate"
 
 	"github.com/gonum/floats"
 	"math"
@@ -57,6 +60,10 @@ type MisoGradTest struct {
 	name    string
 }
 
+// rosenRand generates the random starting locations. It is seeded so the
+// tests are repeatable
+var rosenRand = rand.New(rand.NewSource(1))
+
 func RandRosen(nDim int, low, high float64) MisoGradTest {
 	rosen := MisoGradTest{
 		MisoGradTestFunction: &Rosenbrock{nDim: nDim},
@@ -64,7 +71,7 @@ func RandRosen(nDim int, low, high float64) MisoGradTest {
 		InitLoc:              make([]float64, nDim),
 	}
 	for i := range rosen.InitLoc {
-		rosen.InitLoc[i] = rand.Float64()*(high-low) + low
+		rosen.InitLoc[i] = rosenRand.Float64()*(high-low) + low
 	}
 	return rosen
 }
@@ -83,7 +90,9 @@ func MisoGradBasedTest(t *testing.T, opter MultiGradOptimizer) {
 		// Run it once until very converged
 
 		settings := NewMultiGradSettings()
-		settings.GradientAbsoluteTolerance = 1e-14
+		// Roundoff keeps the 50 dimensional gradient norm near 1e-13
+		// at the optimum, so a smaller tolerance can't be reached
+		settings.GradientAbsoluteTolerance = 1e-10
 		settings.MaximumFunctionEvaluations = 1000
 
 		//opter.Loc().SetInit(fun.InitLoc)
@@ -112,7 +121,7 @@ func MisoGradBasedTest(t *testing.T, opter MultiGradOptimizer) {
 			return
 		}
 		firstLocVal := optLoc
-		if !floats.Eq(firstLocVal, fun.OptLoc(), MISO_TOLERANCE) {
+		if !floats.EqualApprox(firstLocVal, fun.OptLoc(), MISO_TOLERANCE) {
 			t.Errorf("For function "+fun.name+" optimum location not found. %v found, %v expected", firstLocVal, fun.OptLoc())
 		}
 		firstNFunEvals := result.FunctionEvaluations
@@ -124,7 +133,7 @@ func MisoGradBasedTest(t *testing.T, opter MultiGradOptimizer) {
 		//_, _, c, err = opter.Optimize(fun, fun.InitLoc)
 		optVal, optLoc, result, err = OptimizeGrad(fun, fun.InitLoc, settings, opter)
 		if err != nil {
-			t.Errorf("Error while re-using optimizer: ", err.Error())
+			t.Errorf("Error while re-using optimizer: %v", err)
 		}
 
 		if result.FunctionEvaluations != firstNFunEvals {
@@ -146,3 +155,242 @@ func TestLbfgs(t *testing.T) {
 	l := NewLbfgs()
 	MisoGradBasedTest(t, l)
 }
+
+// Squared distance to a target point
+type shiftedQuad struct {
+	target []float64
+}
+
+func (s shiftedQuad) ObjGrad(x []float64) (f float64, g []float64, err error) {
+	g = make([]float64, len(x))
+	for i := range x {
+		f += (x[i] - s.target[i]) * (x[i] - s.target[i])
+		g[i] = 2 * (x[i] - s.target[i])
+	}
+	return f, g, nil
+}
+
+func TestSpg(t *testing.T) {
+	simplex, _ := projection.NewSimplex(1)
+	fun := shiftedQuad{target: []float64{0.5, 0.5, 1}}
+	optLoc := []float64{1.0 / 6, 1.0 / 6, 2.0 / 3}
+	for _, opter := range []*Spg{NewSpg(simplex), NewProjectedGradient(simplex)} {
+		settings := NewMultiGradSettings()
+		settings.Display = false
+		settings.GradientAbsoluteTolerance = 1E-10
+		settings.MaximumFunctionEvaluations = 1000
+		_, loc, result, err := OptimizeGrad(fun, []float64{3, -1, 0}, settings, opter)
+		if err != nil {
+			t.Errorf("Error during projected optimization: " + err.Error())
+			continue
+		}
+		if result.Status != status.ProjGradAbsTol {
+			t.Errorf("Status is not ProjGradAbsTol. %v found", result.Status)
+		}
+		if !floats.EqualApprox(loc, optLoc, MISO_TOLERANCE) {
+			t.Errorf("Optimum location not found. %v found, %v expected", loc, optLoc)
+		}
+	}
+}
+
+func TestSpgStationary(t *testing.T) {
+	// Starting at the minimum with a zero tolerance ends at once rather
+	// than at the iteration limit
+	box := &projection.Box{Lower: []float64{-1, -1}, Upper: []float64{1, 1}}
+	settings := NewMultiGradSettings()
+	settings.Display = false
+	settings.GradientAbsoluteTolerance = 0
+	settings.MaximumIterations = 100
+	_, _, result, err := OptimizeGrad(shiftedQuad{target: []float64{0.5, -0.5}}, []float64{0.5, -0.5}, settings, NewSpg(box))
+	if err != nil {
+		t.Fatalf("Error during optimization: %v", err)
+	}
+	if result.Status != status.ProjGradAbsTol || result.Iterations != 1 {
+		t.Errorf("Status %v after %v iterations, ProjGradAbsTol after 1 expected", result.Status, result.Iterations)
+	}
+}
+
+func TestMultiGradSettings(t *testing.T) {
+	// The objective and location settings, not only the gradient
+	// tolerance and the initial values, are applied
+	rosen := &Rosenbrock{nDim: 2}
+	settings := NewMultiGradSettings()
+	settings.Display = false
+	settings.ObjectiveAbsoluteTolerance = 1E-2
+	settings.KeepObjectiveHistory = true
+	settings.KeepLocationHistory = true
+	obj, _, result, err := OptimizeGrad(rosen, []float64{-1.2, 1}, settings, NewLbfgs())
+	if err != nil {
+		t.Fatalf("Error during optimization: " + err.Error())
+	}
+	if result.Status != status.ObjAbsTol || obj > 1E-2 {
+		t.Errorf("Objective tolerance not applied. Status %v and objective %v found", result.Status, obj)
+	}
+	if len(result.ObjectiveHistory) == 0 || len(result.LocationHistory) == 0 {
+		t.Errorf("History not kept. %v objectives and %v locations found", len(result.ObjectiveHistory), len(result.LocationHistory))
+	}
+}
+
+func TestFista(t *testing.T) {
+	// The minimum of ||x - c||^2 + ||x||_1 is the soft-thresholded c
+	fun := shiftedQuad{target: []float64{2, -0.25, -1, 0.5}}
+	optLoc := []float64{1.5, 0, -0.5, 0}
+	l1 := proximal.L1{Lambda: 1}
+	for _, opter := range []*Fista{NewFista(l1), NewIsta(l1)} {
+		settings := NewMultiGradSettings()
+		settings.Display = false
+		settings.GradientAbsoluteTolerance = 1E-10
+		settings.MaximumFunctionEvaluations = 1000
+		obj, loc, result, err := OptimizeGrad(fun, []float64{3, 3, 3, 3}, settings, opter)
+		if err != nil {
+			t.Errorf("Error during proximal optimization: " + err.Error())
+			continue
+		}
+		if result.Status != status.ProjGradAbsTol {
+			t.Errorf("Status is not ProjGradAbsTol. %v found", result.Status)
+		}
+		if !floats.EqualApprox(loc, optLoc, MISO_TOLERANCE) {
+			t.Errorf("Optimum location not found. %v found, %v expected", loc, optLoc)
+		}
+		if math.Abs(obj-2.8125) > MISO_TOLERANCE {
+			t.Errorf("Optimum value not found. %v found, %v expected", obj, 2.8125)
+		}
+	}
+}
+
+func TestOwlqn(t *testing.T) {
+	// The minimum of ||x - c||^2 + ||x||_1 is the soft-thresholded c
+	fun := shiftedQuad{target: []float64{2, -0.25, -1, 0.5}}
+	optLoc := []float64{1.5, 0, -0.5, 0}
+	settings := NewMultiGradSettings()
+	settings.Display = false
+	settings.GradientAbsoluteTolerance = 1E-10
+	settings.MaximumFunctionEvaluations = 1000
+	obj, loc, result, err := OptimizeGrad(fun, []float64{3, 3, -3, 3}, settings, NewOwlqn(1))
+	if err != nil {
+		t.Fatalf("Error during owlqn optimization: " + err.Error())
+	}
+	if result.Status != status.GradAbsTol {
+		t.Errorf("Status is not GradAbsTol. %v found", result.Status)
+	}
+	if !floats.EqualApprox(loc, optLoc, MISO_TOLERANCE) {
+		t.Errorf("Optimum location not found. %v found, %v expected", loc, optLoc)
+	}
+	if math.Abs(obj-2.8125) > MISO_TOLERANCE {
+		t.Errorf("Optimum value not found. %v found, %v expected", obj, 2.8125)
+	}
+	// With no penalty it should find the minimum of the Rosenbrock function
+	rosen := &Rosenbrock{nDim: 4}
+	_, loc, _, err = OptimizeGrad(rosen, []float64{-1.2, 1, -1.2, 1}, settings, NewOwlqn(0))
+	if err != nil {
+		t.Fatalf("Error during owlqn optimization: " + err.Error())
+	}
+	if !floats.EqualApprox(loc, rosen.OptLoc(), MISO_TOLERANCE) {
+		t.Errorf("Optimum location not found. %v found, %v expected", loc, rosen.OptLoc())
+	}
+	// Invalid settings are an error rather than a panic
+	bad := NewOwlqn(1)
+	bad.NumStore = 0
+	if _, _, _, err = OptimizeGrad(fun, []float64{3, 3, -3, 3}, settings, bad); err == nil {
+		t.Errorf("No error for zero NumStore")
+	}
+}
+
+// Convex quadratic x^T A x / 2 - b^T x with a diagonally dominant A
+type coupledQuad struct {
+	a [][]float64
+	b []float64
+}
+
+func (q coupledQuad) ObjGrad(x []float64) (f float64, g []float64, err error) {
+	g = make([]float64, len(x))
+	for i := range x {
+		for j := range x {
+			g[i] += q.a[i][j] * x[j]
+		}
+		f += x[i] * (0.5*g[i] - q.b[i])
+		g[i] -= q.b[i]
+	}
+	return f, g, nil
+}
+
+func TestCoordinateDescent(t *testing.T) {
+	quad := coupledQuad{
+		a: [][]float64{
+			{4, 1, 0, 0.5},
+			{1, 3, 0.5, 0},
+			{0, 0.5, 5, 1},
+			{0.5, 0, 1, 2},
+		},
+		b: []float64{1, -2, 3, 0.5},
+	}
+	// Solution of A x = b
+	quadOpt := []float64{0.5232323232323233, -0.9656565656565657, 0.7474747474747475, -0.2545454545454545}
+	for _, order := range []CoordinateOrder{CyclicOrder, RandomOrder, GreedyOrder} {
+		settings := NewMultiGradSettings()
+		settings.Display = false
+		settings.GradientAbsoluteTolerance = 1E-6
+		settings.MaximumFunctionEvaluations = 2000
+		c := NewCoordinateDescent()
+		c.Order = order
+		c.UnivariateSettings.GradientAbsoluteTolerance = 1E-12
+		_, loc, result, err := OptimizeGrad(quad, []float64{3, 3, -3, 3}, settings, c)
+		if err != nil {
+			t.Errorf("order %v: error during coordinate descent: %v", order, err)
+			continue
+		}
+		if result.Status != status.GradAbsTol {
+			t.Errorf("order %v: status is not GradAbsTol. %v found", order, result.Status)
+		}
+		if !floats.EqualApprox(loc, quadOpt, MISO_TOLERANCE) {
+			t.Errorf("order %v: optimum location not found. %v found, %v expected", order, loc, quadOpt)
+		}
+		if c.UnivariateSettings.GradientAbsoluteTolerance != 1E-12 || !math.IsNaN(c.UnivariateSettings.InitialObjective) {
+			t.Errorf("order %v: univariate settings modified", order)
+		}
+
+		// The same minimizations using only objective values
+		c = NewCoordinateDescent()
+		c.Order = order
+		golden := univariate.NewGoldenSection()
+		golden.WidthTolerance = 1E-3
+		c.UnivariateObjMethod = golden
+		_, loc, result, err = OptimizeGrad(quad, []float64{3, 3, -3, 3}, settings, c)
+		if err != nil {
+			t.Errorf("order %v: error during derivative-free coordinate descent: %v", order, err)
+			continue
+		}
+		if result.Status != status.GradAbsTol {
+			t.Errorf("order %v: derivative-free status is not GradAbsTol. %v found", order, result.Status)
+		}
+		if !floats.EqualApprox(loc, quadOpt, MISO_TOLERANCE) {
+			t.Errorf("order %v: derivative-free optimum location not found. %v found, %v expected", order, loc, quadOpt)
+		}
+		if !math.IsNaN(c.UnivariateObjSettings.InitialObjective) {
+			t.Errorf("order %v: univariate objective settings modified", order)
+		}
+
+		// The minimum of ||x - c||^2 + ||x||_1 is the soft-thresholded c.
+		// The problem is separable, so each sweep reduces every derivative
+		// by at least the exactness, and few sweeps are needed
+		fun := shiftedQuad{target: []float64{2, -0.25, -1, 0.5}}
+		optLoc := []float64{1.5, 0, -0.5, 0}
+		c = NewCoordinateDescent()
+		c.Order = order
+		c.L1Weight = 1
+		obj, loc, result, err := OptimizeGrad(fun, []float64{3, 3, -3, 3}, settings, c)
+		if err != nil {
+			t.Errorf("order %v: error during l1 coordinate descent: %v", order, err)
+			continue
+		}
+		if !floats.EqualApprox(loc, optLoc, MISO_TOLERANCE) {
+			t.Errorf("order %v: l1 optimum location not found. %v found, %v expected", order, loc, optLoc)
+		}
+		if math.Abs(obj-2.8125) > MISO_TOLERANCE {
+			t.Errorf("order %v: l1 optimum value not found. %v found, %v expected", order, obj, 2.8125)
+		}
+		if result.Iterations > 3 {
+			t.Errorf("order %v: %v sweeps for a separable problem", order, result.Iterations)
+		}
+	}
+}
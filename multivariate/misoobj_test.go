@@ -0,0 +1,134 @@
+package multivariate
+
+import (
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/projection"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/gonum/floats"
+
+	"math"
+	"testing"
+)
+
+// objOnly hides the gradient of a function
+type objOnly struct {
+	MisoGradTestFunction
+}
+
+func (o objOnly) Objective(x []float64) (float64, error) {
+	f, _, err := o.ObjGrad(x)
+	return f, err
+}
+
+// shiftedQuadratic is sum_i i * (x_i - c_i)^2
+type shiftedQuadratic struct {
+	c []float64
+}
+
+func (q shiftedQuadratic) Objective(x []float64) (float64, error) {
+	var f float64
+	for i, v := range x {
+		f += float64(i+1) * (v - q.c[i]) * (v - q.c[i])
+	}
+	return f, nil
+}
+
+func newObjTestSettings() *MultiObjSettings {
+	settings := NewMultiObjSettings()
+	settings.Display = false
+	return settings
+}
+
+func TestBobyqa(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		fun      optimize.MultiObj
+		x0       []float64
+		maxEvals int
+	}{
+		{"Rosenbrock 2D", objOnly{&Rosenbrock{2}}, []float64{-1.2, 1}, 300},
+		{"Rosenbrock 5D", objOnly{&Rosenbrock{5}}, []float64{0, 0, 0, 0, 0}, 800},
+		{"Quadratic 8D", shiftedQuadratic{c: []float64{1, -2, 3, -4, 5, -6, 7, -8}}, make([]float64, 8), 200},
+	} {
+		settings := newObjTestSettings()
+		opt, loc, result, err := OptimizeObj(test.fun, test.x0, settings, NewBobyqa(nil))
+		if err != nil {
+			t.Errorf("%v: error during optimization: %v", test.name, err)
+			continue
+		}
+		if result.Status != status.StepAbsTol {
+			t.Errorf("%v: status %v, StepAbsTol expected", test.name, result.Status)
+		}
+		if opt > 1e-8 {
+			t.Errorf("%v: minimum %v at %v not found", test.name, opt, loc)
+		}
+		if result.FunctionEvaluations > test.maxEvals {
+			t.Errorf("%v: %v function evaluations, at most %v expected", test.name, result.FunctionEvaluations, test.maxEvals)
+		}
+	}
+}
+
+func TestMultiObjSettings(t *testing.T) {
+	// The objective and location settings are applied, not only the
+	// initial objective and the absolute tolerance
+	settings := newObjTestSettings()
+	settings.KeepObjectiveHistory = true
+	settings.KeepLocationHistory = true
+	settings.ObjectiveAbsoluteTolerance = 1E-2
+	opt, _, result, err := OptimizeObj(objOnly{&Rosenbrock{2}}, []float64{-1.2, 1}, settings, NewBobyqa(nil))
+	if err != nil {
+		t.Fatalf("error during optimization: %v", err)
+	}
+	if result.Status != status.ObjAbsTol || opt > 1E-2 {
+		t.Errorf("objective tolerance not applied. Status %v and objective %v found", result.Status, opt)
+	}
+	if len(result.ObjectiveHistory) == 0 || len(result.LocationHistory) == 0 {
+		t.Errorf("history not kept. %v objectives and %v locations found", len(result.ObjectiveHistory), len(result.LocationHistory))
+	}
+}
+
+func TestBobyqaBounds(t *testing.T) {
+	// The minimum of the quadratic is outside the bounds in the first two
+	// coordinates
+	fun := shiftedQuadratic{c: []float64{2, -3, 0.5, 0}}
+	bounds := &projection.Box{
+		Lower: []float64{-1, -1, -1, -1},
+		Upper: []float64{1, 1, 1, 1},
+	}
+	settings := newObjTestSettings()
+	settings.KeepLocationHistory = true
+	_, loc, result, err := OptimizeObj(fun, []float64{0, 0, 0, 1}, settings, NewBobyqa(bounds))
+	if err != nil {
+		t.Fatalf("error during optimization: %v", err)
+	}
+	want := []float64{1, -1, 0.5, 0}
+	for i := range want {
+		if math.Abs(loc[i]-want[i]) > 1e-5 {
+			t.Errorf("minimum %v found, %v expected", loc, want)
+			break
+		}
+	}
+	for _, x := range result.LocationHistory {
+		for i, v := range x {
+			if v < bounds.Lower[i] || v > bounds.Upper[i] {
+				t.Errorf("location %v outside the bounds evaluated", x)
+			}
+		}
+	}
+
+	// The budget is respected during the initial interpolation points
+	settings = newObjTestSettings()
+	settings.MaximumFunctionEvaluations = 5
+	settings.KeepObjectiveHistory = true
+	opt, _, result, err := OptimizeObj(fun, []float64{0, 0, 0, 1}, settings, NewBobyqa(bounds))
+	if err != nil {
+		t.Fatalf("error during optimization: %v", err)
+	}
+	if result.Status != status.MaximumFunctionEvaluations || result.FunctionEvaluations != 5 {
+		t.Errorf("status %v after %v function evaluations, MaximumFunctionEvaluations after 5 expected", result.Status, result.FunctionEvaluations)
+	}
+	// The best of the points evaluated is kept
+	if best := floats.Min(result.ObjectiveHistory); opt != best {
+		t.Errorf("objective %v returned, %v evaluated", opt, best)
+	}
+}
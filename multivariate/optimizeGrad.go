@@ -42,6 +42,12 @@ func (m *moddedFun) ObjGrad(x []float64) (obj float64, grad []float64, err error
 	return
 }
 
+// RemainingEvaluations returns the number of function evaluations left
+// before the maximum is reached
+func (m *moddedFun) RemainingEvaluations() int {
+	return m.funEvals.Max() - m.funEvals.Curr()
+}
+
 type MultiGradOptimizer interface {
 	Initialize(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient) error
 	Iterate(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient, fun optimize.MultiObjGrad) (status.Status, error)
@@ -129,19 +135,34 @@ func (m *multiGradStruct) CommonSettings() *common.CommonSettings {
 }
 
 func (m *multiGradStruct) SetSettings() error {
-	m.grad.SetInit(m.settings.InitialGradient)
-	m.obj.SetInit(m.settings.InitialObjective)
-	m.grad.SetAbsTol(m.settings.GradientAbsoluteTolerance)
+	m.obj.SetSettings(m.settings.ObjectiveSettings)
+	m.grad.SetSettings(m.settings.GradientSettings)
+	m.loc.SetSettings(m.settings.LocationSettings)
 	return nil
 }
 
 func (m *multiGradStruct) Status() status.Status {
-	return status.CheckStatus(m.obj, m.grad)
+	c := status.CheckStatus(m.obj, m.grad)
+	if c != status.Continue {
+		return c
+	}
+	// Optimizers may have their own convergence criteria (projected
+	// gradient norm, etc.)
+	statuser, ok := m.optimizer.(status.Statuser)
+	if ok {
+		return statuser.Status()
+	}
+	return c
 }
 
 func (m *multiGradStruct) AddToDisplay(d []*display.Struct) []*display.Struct {
 	//fmt.Println("In multi add to display")
-	return display.AddToDisplay(d, m.loc, m.obj, m.grad)
+	d = display.AddToDisplay(d, m.loc, m.obj, m.grad)
+	displayer, ok := m.optimizer.(display.Displayer)
+	if ok {
+		d = displayer.AddToDisplay(d)
+	}
+	return d
 }
 
 func (m *multiGradStruct) Result() *MultiGradResult {
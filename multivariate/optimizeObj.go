@@ -0,0 +1,163 @@
+package multivariate
+
+import (
+	"github.com/btracey/gofunopter/common"
+	"github.com/btracey/gofunopter/common/display"
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+	"math"
+)
+
+// MultiObjOptimizer is a derivative-free optimizer which uses only
+// function values. The location and objective value set by the optimizer
+// should be the best found so far
+type MultiObjOptimizer interface {
+	Initialize(loc *multi.Location, obj *uni.Objective) error
+	Iterate(loc *multi.Location, obj *uni.Objective, fun optimize.MultiObj) (status.Status, error)
+}
+
+// OptimizeObj minimizes a function for which only the objective value is
+// available. The function evaluations never exceed
+// settings.MaximumFunctionEvaluations, even part way through an iteration.
+// If optimizer is nil, an unconstrained Bobyqa is used
+func OptimizeObj(function optimize.MultiObj, initialLocation []float64, settings *MultiObjSettings, optimizer MultiObjOptimizer) (optValue float64, optLocation []float64, result *MultiObjResult, err error) {
+
+	if settings == nil {
+		settings = NewMultiObjSettings()
+	}
+
+	if optimizer == nil {
+		optimizer = NewBobyqa(nil)
+	}
+
+	m := newMultiObjStruct()
+	m.fun = &optimize.LimitedMultiObj{
+		Fun:      function,
+		Loc:      m.loc,
+		Obj:      m.obj,
+		FunEvals: m.FunEvals,
+	}
+	m.settings = settings
+	m.optimizer = optimizer
+
+	m.loc.SetInit(initialLocation)
+	err = optimize.OptimizeOpter(m, function)
+
+	return m.obj.Opt(), m.loc.Opt(), m.Result(), err
+}
+
+type MultiObjResult struct {
+	*common.CommonResult
+	*uni.ObjectiveResult
+	*multi.LocationResult
+}
+
+type MultiObjSettings struct {
+	*common.CommonSettings
+	*uni.ObjectiveSettings
+	*multi.LocationSettings
+}
+
+func NewMultiObjSettings() *MultiObjSettings {
+	return &MultiObjSettings{
+		CommonSettings:    common.NewCommonSettings(),
+		ObjectiveSettings: uni.NewObjectiveSettings(),
+		LocationSettings:  multi.NewLocationSettings(),
+	}
+}
+
+type multiObjStruct struct {
+	*common.OptCommon
+
+	loc *multi.Location
+	obj *uni.Objective
+
+	// User defined function
+	fun *optimize.LimitedMultiObj
+
+	// Optimization model
+	optimizer MultiObjOptimizer
+
+	// Settings
+	settings *MultiObjSettings
+}
+
+func newMultiObjStruct() *multiObjStruct {
+	return &multiObjStruct{
+		OptCommon: common.NewOptCommon(),
+		loc:       multi.NewLocation(),
+		obj:       uni.NewObjective(),
+	}
+}
+
+func (m *multiObjStruct) CommonSettings() *common.CommonSettings {
+	return m.settings.CommonSettings
+}
+
+func (m *multiObjStruct) SetSettings() error {
+	m.obj.SetSettings(m.settings.ObjectiveSettings)
+	m.loc.SetSettings(m.settings.LocationSettings)
+	return nil
+}
+
+func (m *multiObjStruct) Status() status.Status {
+	c := status.CheckStatus(m.obj)
+	if c != status.Continue {
+		return c
+	}
+	statuser, ok := m.optimizer.(status.Statuser)
+	if ok {
+		return statuser.Status()
+	}
+	return c
+}
+
+func (m *multiObjStruct) AddToDisplay(d []*display.Struct) []*display.Struct {
+	d = display.AddToDisplay(d, m.loc, m.obj)
+	displayer, ok := m.optimizer.(display.Displayer)
+	if ok {
+		d = displayer.AddToDisplay(d)
+	}
+	return d
+}
+
+func (m *multiObjStruct) Result() *MultiObjResult {
+	return &MultiObjResult{
+		CommonResult:    m.OptCommon.CommonResult(),
+		ObjectiveResult: m.obj.Result(),
+		LocationResult:  m.loc.Result(),
+	}
+}
+
+func (m *multiObjStruct) SetResult() {
+	optimize.SetResult(m.loc, m.obj)
+
+	setResulter, ok := m.optimizer.(optimize.SetResulter)
+	if ok {
+		setResulter.SetResult()
+	}
+}
+
+func (m *multiObjStruct) Initialize() error {
+	if math.IsNaN(m.obj.Init()) {
+		initObj, err := m.fun.Objective(m.loc.Init())
+		if err != nil {
+			return errors.New("error calling function during optimization: \n" + err.Error())
+		}
+		m.obj.SetInit(initObj)
+	}
+
+	err := optimize.Initialize(m.loc, m.obj)
+	if err != nil {
+		return err
+	}
+	return m.optimizer.Initialize(m.loc, m.obj)
+}
+
+func (m *multiObjStruct) Iterate() (status.Status, error) {
+	return m.optimizer.Iterate(m.loc, m.obj, m.fun)
+}
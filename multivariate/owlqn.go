@@ -0,0 +1,202 @@
+package multivariate
+
+import (
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+	"github.com/gonum/floats"
+)
+
+// Owlqn is the orthant-wise limited-memory quasi-newton method of Andrew
+// and Gao (2007) for minimizing f(x) + L1Weight * ||x||_1 where f is the
+// smooth user defined function. The search direction is found with the
+// Lbfgs two-loop recursion on the pseudo-gradient, and the linesearch
+// keeps every iterate within the orthant of the current location.
+// The objective value reported is the composite function value, and the
+// gradient reported is the pseudo-gradient (the minimum norm subgradient),
+// so the gradient tolerances apply to the pseudo-gradient.
+// With L1Weight zero no orthant constraints are applied, and Owlqn is
+// Lbfgs with a backtracking linesearch
+type Owlqn struct {
+	// Tunable parameters
+	L1Weight        float64
+	NumStore        int     // How many gradients to store
+	FunConst        float64 // Sufficient decrease constant for the backtracking linesearch
+	BacktrackFactor float64 // Step multiplier when sufficient decrease is not met
+	MaxBacktracks   int     // Maximum number of function evaluations in a linesearch
+
+	// Other needed variables
+	hist       *Lbfgs
+	first      bool
+	nDim       int
+	smoothGrad []float64 // gradient of f at the current location
+	pseudo     []float64
+	dir        []float64
+	orthant    []float64
+	xTrial     []float64
+	s_k        []float64
+	y_k        []float64
+}
+
+// NewOwlqn returns a new OWL-QN optimizer with an L1 penalty of weight l1
+func NewOwlqn(l1 float64) *Owlqn {
+	return &Owlqn{
+		L1Weight:        l1,
+		NumStore:        30,
+		FunConst:        1E-4,
+		BacktrackFactor: 0.5,
+		MaxBacktracks:   100,
+	}
+}
+
+func (o *Owlqn) Initialize(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient) error {
+	if o.L1Weight < 0 {
+		return errors.New("owlqn: l1 weight must be non-negative")
+	}
+	if o.BacktrackFactor <= 0 || o.BacktrackFactor >= 1 {
+		return errors.New("owlqn: backtrack factor must be between zero and one")
+	}
+	if o.NumStore < 1 {
+		return errors.New("owlqn: number of stored gradients must be at least one")
+	}
+	o.nDim = len(loc.Init())
+	o.hist = &Lbfgs{NumStore: o.NumStore, nDim: o.nDim}
+	o.hist.initHist()
+	o.first = true
+
+	o.smoothGrad = make([]float64, o.nDim)
+	o.pseudo = make([]float64, o.nDim)
+	o.dir = make([]float64, o.nDim)
+	o.orthant = make([]float64, o.nDim)
+	o.xTrial = make([]float64, o.nDim)
+	o.s_k = make([]float64, o.nDim)
+	o.y_k = make([]float64, o.nDim)
+
+	copy(o.smoothGrad, grad.Curr())
+	o.pseudoGradient(loc.Curr())
+	obj.SetCurr(obj.Curr() + o.L1Weight*floats.Norm(loc.Curr(), 1))
+	grad.SetCurr(o.pseudo)
+	return nil
+}
+
+// pseudoGradient computes the pseudo-gradient at x from the smooth gradient
+func (o *Owlqn) pseudoGradient(x []float64) {
+	pseudoGradient(o.pseudo, x, o.smoothGrad, o.L1Weight)
+}
+
+// pseudoGradient stores in dst the minimum norm subgradient of
+// f(x) + l1 * ||x||_1, where grad is the gradient of f
+func pseudoGradient(dst, x, grad []float64, l1 float64) {
+	for i, g := range grad {
+		switch {
+		case x[i] > 0:
+			dst[i] = g + l1
+		case x[i] < 0:
+			dst[i] = g - l1
+		case g+l1 < 0:
+			dst[i] = g + l1
+		case g-l1 > 0:
+			dst[i] = g - l1
+		default:
+			dst[i] = 0
+		}
+	}
+}
+
+func (o *Owlqn) Iterate(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient, fun optimize.MultiObjGrad) (status.Status, error) {
+	x := loc.Curr()
+	fCurr := obj.Curr()
+
+	// Quasi-newton direction on the pseudo-gradient, constrained to
+	// agree in sign with the steepest descent direction
+	o.hist.searchDirection(o.dir, o.pseudo)
+	constrain := o.L1Weight > 0
+	steepest := false
+	if constrain {
+		for i, val := range o.dir {
+			if val*o.pseudo[i] >= 0 {
+				o.dir[i] = 0
+			}
+		}
+		// If no component agrees (which only roundoff in the history can
+		// cause) step along the negative pseudo-gradient instead of
+		// stalling with a zero step
+		if floats.Norm(o.dir, 2) == 0 {
+			for i, val := range o.pseudo {
+				o.dir[i] = -val
+			}
+			steepest = true
+		}
+	}
+	// Orthant for the linesearch
+	for i, val := range x {
+		switch {
+		case val > 0:
+			o.orthant[i] = 1
+		case val < 0:
+			o.orthant[i] = -1
+		case o.pseudo[i] > 0:
+			o.orthant[i] = -1
+		case o.pseudo[i] < 0:
+			o.orthant[i] = 1
+		default:
+			o.orthant[i] = 0
+		}
+	}
+
+	alpha := 1.0
+	if o.first || steepest {
+		norm := floats.Norm(o.dir, 2)
+		if norm > 1 {
+			alpha = 1 / norm
+		}
+	}
+
+	// Backtracking linesearch along the projected path
+	var fTrial float64
+	var gTrial []float64
+	for i := 0; ; i++ {
+		if i == o.MaxBacktracks {
+			return status.LinesearchFailure, errors.New("owlqn: maximum number of backtracking steps reached")
+		}
+		for j := range o.xTrial {
+			o.xTrial[j] = x[j] + alpha*o.dir[j]
+			if constrain && o.xTrial[j]*o.orthant[j] <= 0 {
+				o.xTrial[j] = 0
+			}
+		}
+		var err error
+		fTrial, gTrial, err = fun.ObjGrad(o.xTrial)
+		if err != nil {
+			return status.UserFunctionError, errors.New("owlqn: user defined function error: " + err.Error())
+		}
+		if len(gTrial) != o.nDim {
+			return status.UserFunctionError, errors.New("owlqn: user defined function returned incorrect gradient length")
+		}
+		floats.SubTo(o.s_k, o.xTrial, x)
+		compTrial := fTrial + o.L1Weight*floats.Norm(o.xTrial, 1)
+		if compTrial <= fCurr+o.FunConst*floats.Dot(o.pseudo, o.s_k) {
+			fCurr = compTrial
+			break
+		}
+		alpha *= o.BacktrackFactor
+	}
+	o.first = false
+
+	// Update the curvature history using the smooth gradients
+	floats.SubTo(o.y_k, gTrial, o.smoothGrad)
+	if floats.Dot(o.s_k, o.y_k) > 0 {
+		o.hist.updateHist(o.s_k, o.y_k)
+	}
+
+	copy(o.smoothGrad, gTrial)
+	o.pseudoGradient(o.xTrial)
+
+	loc.SetCurr(o.xTrial)
+	obj.SetCurr(fCurr)
+	grad.SetCurr(o.pseudo)
+	return status.Continue, nil
+}
@@ -0,0 +1,276 @@
+package multivariate
+
+import (
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/projection"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+	"github.com/gonum/floats"
+	"math"
+)
+
+// Spg is the spectral projected gradient method of Birgin, Martinez and
+// Raydan (2000) for minimizing a function over a convex set. The set is
+// expressed by a projection.Projector. At each iteration the search
+// direction is P(x - lambda * g) - x, where lambda is the Barzilai-Borwein
+// step, followed by a nonmonotone backtracking linesearch.
+// If the initial location is infeasible it is projected onto the set
+// during the first iteration.
+// Spg converges with status.ProjGradAbsTol when the norm of the projected
+// gradient P(x - g) - x is less than the gradient absolute tolerance
+// (the norm of the unprojected gradient is not used)
+type Spg struct {
+	// Tunable parameters
+	Projector      projection.Projector
+	Spectral       bool    // Use the Barzilai-Borwein step (if false, lambda = 1 at every iteration)
+	Memory         int     // Number of past function values used in the nonmonotone linesearch
+	FunConst       float64 // Sufficient decrease constant
+	LambdaMin      float64 // Minimum spectral step
+	LambdaMax      float64 // Maximum spectral step
+	BacktrackMin   float64 // Minimum ratio of successive linesearch steps
+	BacktrackMax   float64 // Maximum ratio of successive linesearch steps
+	MaxBacktracks  int     // Maximum number of function evaluations in a linesearch
+	FeasibilityTol float64 // Tolerance for deciding if the initial location is feasible
+
+	// Other needed variables
+	tol          float64
+	projGradNorm float64
+	needProject  bool
+	lambda       float64
+	fHist        []float64
+	counter      int
+	nDim         int
+	d            []float64
+	xTrial       []float64
+	gTrial       []float64
+	s            []float64
+	y            []float64
+	tmp          []float64
+}
+
+// NewSpg returns a spectral projected gradient optimizer onto the set
+// given by p with the default settings
+func NewSpg(p projection.Projector) *Spg {
+	return &Spg{
+		Projector:      p,
+		Spectral:       true,
+		Memory:         10,
+		FunConst:       1E-4,
+		LambdaMin:      1E-30,
+		LambdaMax:      1E30,
+		BacktrackMin:   0.1,
+		BacktrackMax:   0.9,
+		MaxBacktracks:  100,
+		FeasibilityTol: 1E-12,
+	}
+}
+
+// NewProjectedGradient returns a classical projected gradient method
+// with a monotone Armijo linesearch
+func NewProjectedGradient(p projection.Projector) *Spg {
+	s := NewSpg(p)
+	s.Spectral = false
+	s.Memory = 1
+	return s
+}
+
+func (spg *Spg) Initialize(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient) error {
+	if spg.Projector == nil {
+		return errors.New("spg: projector is nil")
+	}
+	if spg.Memory < 1 {
+		return errors.New("spg: memory must be at least one")
+	}
+	spg.nDim = len(loc.Init())
+
+	// The norm of the gradient is not a meaningful convergence test on
+	// the boundary of the set. Use the tolerance on the projected gradient
+	// instead
+	spg.tol = grad.AbsTol()
+	grad.SetAbsTol(0)
+
+	spg.d = make([]float64, spg.nDim)
+	spg.xTrial = make([]float64, spg.nDim)
+	spg.gTrial = make([]float64, spg.nDim)
+	spg.s = make([]float64, spg.nDim)
+	spg.y = make([]float64, spg.nDim)
+	spg.tmp = make([]float64, spg.nDim)
+	spg.fHist = make([]float64, spg.Memory)
+	for i := range spg.fHist {
+		spg.fHist[i] = math.Inf(-1)
+	}
+	spg.counter = 0
+
+	feasible, err := projection.Feasible(spg.Projector, loc.Curr(), spg.FeasibilityTol)
+	if err != nil {
+		return errors.New("spg: error projecting initial location: " + err.Error())
+	}
+	spg.needProject = !feasible
+	if spg.needProject {
+		spg.projGradNorm = math.Inf(1)
+		return nil
+	}
+	spg.addHist(obj.Curr())
+	return spg.initLambda(loc.Curr(), grad.Curr())
+}
+
+// initLambda sets the initial spectral step and the projected gradient norm
+func (spg *Spg) initLambda(x, g []float64) error {
+	err := spg.projectedGradient(x, g, 1)
+	if err != nil {
+		return err
+	}
+	spg.projGradNorm = floats.Norm(spg.d, 2)
+	spg.lambda = 1
+	if spg.Spectral {
+		infNorm := floats.Norm(spg.d, math.Inf(1))
+		if infNorm > 0 {
+			spg.lambda = spg.clampLambda(1 / infNorm)
+		}
+	}
+	return nil
+}
+
+// projectedGradient sets d = P(x - lambda * g) - x
+func (spg *Spg) projectedGradient(x, g []float64, lambda float64) error {
+	for i := range spg.d {
+		spg.d[i] = x[i] - lambda*g[i]
+	}
+	err := spg.Projector.Project(spg.d)
+	if err != nil {
+		return errors.New("spg: error during projection: " + err.Error())
+	}
+	floats.Sub(spg.d, x)
+	return nil
+}
+
+func (spg *Spg) clampLambda(l float64) float64 {
+	return math.Min(math.Max(l, spg.LambdaMin), spg.LambdaMax)
+}
+
+func (spg *Spg) addHist(f float64) {
+	spg.fHist[spg.counter] = f
+	spg.counter++
+	if spg.counter == spg.Memory {
+		spg.counter = 0
+	}
+}
+
+func (spg *Spg) Status() status.Status {
+	if spg.projGradNorm < spg.tol {
+		return status.ProjGradAbsTol
+	}
+	return status.Continue
+}
+
+func (spg *Spg) Iterate(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient, fun optimize.MultiObjGrad) (status.Status, error) {
+	if spg.needProject {
+		// Move the initial location onto the feasible set
+		copy(spg.xTrial, loc.Curr())
+		err := spg.Projector.Project(spg.xTrial)
+		if err != nil {
+			return status.OptimizerError, errors.New("spg: error during projection: " + err.Error())
+		}
+		f, g, err := fun.ObjGrad(spg.xTrial)
+		if err != nil {
+			return status.UserFunctionError, errors.New("spg: user defined function error: " + err.Error())
+		}
+		if len(g) != spg.nDim {
+			return status.UserFunctionError, errors.New("spg: user defined function returned incorrect gradient length")
+		}
+		loc.SetCurr(spg.xTrial)
+		obj.SetCurr(f)
+		grad.SetCurr(g)
+		spg.needProject = false
+		spg.addHist(f)
+		err = spg.initLambda(loc.Curr(), grad.Curr())
+		if err != nil {
+			return status.OptimizerError, err
+		}
+		return status.Continue, nil
+	}
+
+	x := loc.Curr()
+	g := grad.Curr()
+	f := obj.Curr()
+
+	err := spg.projectedGradient(x, g, spg.lambda)
+	if err != nil {
+		return status.OptimizerError, err
+	}
+	gDotD := floats.Dot(g, spg.d)
+	if gDotD >= 0 {
+		// Only happens at a stationary point (up to round-off), which is
+		// converged even if the tolerance is zero
+		spg.projGradNorm = 0
+		return status.ProjGradAbsTol, nil
+	}
+
+	// Nonmonotone reference value
+	fMax := math.Inf(-1)
+	for _, val := range spg.fHist {
+		fMax = math.Max(fMax, val)
+	}
+
+	alpha := 1.0
+	var fTrial float64
+	var gTrial []float64
+	for i := 0; ; i++ {
+		if i == spg.MaxBacktracks {
+			return status.LinesearchFailure, errors.New("spg: maximum number of backtracking steps reached")
+		}
+		for j := range spg.xTrial {
+			spg.xTrial[j] = x[j] + alpha*spg.d[j]
+		}
+		fTrial, gTrial, err = fun.ObjGrad(spg.xTrial)
+		if err != nil {
+			return status.UserFunctionError, errors.New("spg: user defined function error: " + err.Error())
+		}
+		if len(gTrial) != spg.nDim {
+			return status.UserFunctionError, errors.New("spg: user defined function returned incorrect gradient length")
+		}
+		if fTrial <= fMax+spg.FunConst*alpha*gDotD {
+			break
+		}
+		// Safeguarded quadratic interpolation
+		alphaTmp := -0.5 * alpha * alpha * gDotD / (fTrial - f - alpha*gDotD)
+		if alphaTmp >= spg.BacktrackMin*alpha && alphaTmp <= spg.BacktrackMax*alpha {
+			alpha = alphaTmp
+		} else {
+			alpha /= 2
+		}
+	}
+	copy(spg.gTrial, gTrial)
+
+	// Spectral step from the change in location and gradient
+	floats.SubTo(spg.s, spg.xTrial, x)
+	floats.SubTo(spg.y, spg.gTrial, g)
+	if spg.Spectral {
+		sDotY := floats.Dot(spg.s, spg.y)
+		if sDotY <= 0 {
+			spg.lambda = spg.LambdaMax
+		} else {
+			spg.lambda = spg.clampLambda(floats.Dot(spg.s, spg.s) / sDotY)
+		}
+	}
+
+	loc.SetCurr(spg.xTrial)
+	obj.SetCurr(fTrial)
+	grad.SetCurr(spg.gTrial)
+	spg.addHist(fTrial)
+
+	// Convergence is tested on the unit-step projected gradient
+	for i := range spg.tmp {
+		spg.tmp[i] = spg.xTrial[i] - spg.gTrial[i]
+	}
+	err = spg.Projector.Project(spg.tmp)
+	if err != nil {
+		return status.OptimizerError, errors.New("spg: error during projection: " + err.Error())
+	}
+	floats.Sub(spg.tmp, spg.xTrial)
+	spg.projGradNorm = floats.Norm(spg.tmp, 2)
+	return status.Continue, nil
+}
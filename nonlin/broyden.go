@@ -0,0 +1,172 @@
+package nonlin
+
+import (
+	"github.com/btracey/gofunopter/common/linalg"
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+
+	"errors"
+	"github.com/gonum/floats"
+	"math"
+)
+
+// BroydenUpdate is the quasi-Newton update used by Broyden
+type BroydenUpdate int
+
+const (
+	// GoodBroyden updates the Jacobian estimate B with the smallest
+	// change for which B s = y, and solves B d = -F every iteration
+	GoodBroyden BroydenUpdate = iota
+	// BadBroyden updates the estimate H of the inverse Jacobian with the
+	// smallest change for which H y = s, so the step is just d = -H F
+	BadBroyden
+)
+
+// Broyden is Broyden's quasi-Newton method, where s is the step and y the
+// change in the residual. The initial Jacobian is that of the user defined
+// function if it implements optimize.MultiJacobian, and otherwise is
+// estimated with forward differences of relative size
+// FiniteDifferenceStep. If the merit linesearch fails the Jacobian is
+// evaluated again, since the estimate may have drifted
+type Broyden struct {
+	// Tunable parameters
+	*Merit
+	Update               BroydenUpdate
+	FiniteDifferenceStep float64
+
+	// Other needed variables
+	b     [][]float64 // Jacobian estimate (GoodBroyden) or its inverse (BadBroyden)
+	fresh bool        // b has not been updated since it was evaluated
+	d     []float64
+	jd    []float64
+	tmp   []float64
+}
+
+func NewBroyden() *Broyden {
+	return &Broyden{
+		Merit:                NewMerit(),
+		Update:               GoodBroyden,
+		FiniteDifferenceStep: 1E-7,
+	}
+}
+
+func (br *Broyden) Initialize(loc *multi.Location, res *multi.Floats) error {
+	switch br.Update {
+	case GoodBroyden, BadBroyden:
+	default:
+		return errors.New("broyden: unknown update")
+	}
+	if !(br.FiniteDifferenceStep > 0) {
+		return errors.New("broyden: finite difference step must be positive")
+	}
+	nDim := len(loc.Init())
+	br.b = nil
+	br.d = make([]float64, nDim)
+	br.jd = make([]float64, nDim)
+	br.tmp = make([]float64, nDim)
+	return nil
+}
+
+// jacobian evaluates the Jacobian at the current location and sets the
+// estimate from it
+func (br *Broyden) jacobian(loc *multi.Location, res *multi.Floats, fun optimize.MultiResidual) (status.Status, error) {
+	var jac [][]float64
+	var err error
+	if hasJacobian(fun) {
+		jac, err = fun.(optimize.MultiJacobian).Jacobian(loc.Curr())
+	} else {
+		jac, err = finiteDifferenceJacobian(fun, loc.Curr(), res.Curr(), br.FiniteDifferenceStep)
+	}
+	if err != nil {
+		return status.UserFunctionError, errors.New("broyden: user defined function error: " + err.Error())
+	}
+	br.fresh = true
+	if br.Update == GoodBroyden {
+		br.b = jac
+		return status.Continue, nil
+	}
+	lu, err := linalg.NewLU(jac)
+	if err != nil {
+		return status.IllConditioned, errors.New("broyden: " + err.Error())
+	}
+	n := len(jac)
+	// Columns of the inverse, stored by row
+	br.b = make([][]float64, n)
+	for i := range br.b {
+		br.b[i] = make([]float64, n)
+	}
+	e := make([]float64, n)
+	for j := 0; j < n; j++ {
+		e[j] = 1
+		lu.Solve(br.tmp, e)
+		e[j] = 0
+		for i := range br.b {
+			br.b[i][j] = br.tmp[i]
+		}
+	}
+	return status.Continue, nil
+}
+
+func (br *Broyden) Iterate(loc *multi.Location, res *multi.Floats, fun optimize.MultiResidual) (status.Status, error) {
+	for {
+		if br.b == nil {
+			if c, err := br.jacobian(loc, res, fun); err != nil {
+				return c, err
+			}
+		}
+		c, err := br.step(loc, res, fun)
+		if err == nil || br.fresh {
+			return c, err
+		}
+		// The estimate may have drifted, so try again from a new Jacobian
+		br.b = nil
+	}
+}
+
+// step takes a step from the current estimate and updates it
+func (br *Broyden) step(loc *multi.Location, res *multi.Floats, fun optimize.MultiResidual) (status.Status, error) {
+	f := res.Curr()
+	// In both cases the model gives J d = -F
+	for i, v := range f {
+		br.jd[i] = -v
+	}
+	if br.Update == GoodBroyden {
+		lu, err := linalg.NewLU(br.b)
+		if err != nil {
+			return status.IllConditioned, errors.New("broyden: " + err.Error())
+		}
+		lu.Solve(br.d, br.jd)
+	} else {
+		matVec(br.d, br.b, br.jd)
+	}
+
+	s, y, err := br.search(loc, res, fun, br.d, br.jd)
+	if err != nil {
+		return status.LinesearchFailure, errors.New("broyden: " + err.Error())
+	}
+	br.fresh = false
+
+	if br.Update == GoodBroyden {
+		// B += (y - B s) s^T / (s^T s)
+		ss := floats.Dot(s, s)
+		if ss == 0 {
+			return status.Continue, nil
+		}
+		matVec(br.tmp, br.b, s)
+		for i, row := range br.b {
+			floats.AddScaled(row, (y[i]-br.tmp[i])/ss, s)
+		}
+		return status.Continue, nil
+	}
+	// H += (s - H y) y^T / (y^T y)
+	yy := floats.Dot(y, y)
+	if yy == 0 || math.IsInf(yy, 0) {
+		return status.Continue, nil
+	}
+	matVec(br.tmp, br.b, y)
+	for i, row := range br.b {
+		floats.AddScaled(row, (s[i]-br.tmp[i])/yy, y)
+	}
+	return status.Continue, nil
+}
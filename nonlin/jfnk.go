@@ -0,0 +1,122 @@
+package nonlin
+
+import (
+	"github.com/btracey/gofunopter/common/display"
+	"github.com/btracey/gofunopter/common/linalg"
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+
+	"errors"
+	"github.com/gonum/floats"
+	"math"
+)
+
+// NewtonKrylov is the Jacobian-free Newton-Krylov method. Each iteration
+// solves J d = -F with GMRES until the residual of the linear system is
+// less than Forcing times the norm of F, where the products of J with a
+// vector v are estimated by the forward difference of F along v. Each
+// product costs one function evaluation, and GMRES is limited to
+// MaxProducts products per iteration (restarted every Restart products).
+// Only a handful of products are needed when the Jacobian is well
+// conditioned, so NewtonKrylov suits large systems whose Jacobian is
+// expensive to form
+type NewtonKrylov struct {
+	// Tunable parameters
+	*Merit
+	Forcing              float64 // Relative tolerance of the linear solves
+	Restart              int
+	MaxProducts          int
+	FiniteDifferenceStep float64 // Relative size of the difference for the products
+
+	// Other needed variables
+	d       []float64
+	jd      []float64
+	rhs     []float64
+	xh      []float64
+	nLinear int // products in the last linear solve
+}
+
+func NewNewtonKrylov() *NewtonKrylov {
+	return &NewtonKrylov{
+		Merit:                NewMerit(),
+		Forcing:              1E-4,
+		Restart:              30,
+		MaxProducts:          100,
+		FiniteDifferenceStep: 1.4901161193847656E-8, // square root of machine precision
+	}
+}
+
+func (nk *NewtonKrylov) Initialize(loc *multi.Location, res *multi.Floats) error {
+	if !(nk.Forcing > 0 && nk.Forcing < 1) {
+		return errors.New("newton krylov: forcing term must be in (0, 1)")
+	}
+	if nk.Restart <= 0 || nk.MaxProducts <= 0 {
+		return errors.New("newton krylov: restart and maximum products must be positive")
+	}
+	if !(nk.FiniteDifferenceStep > 0) {
+		return errors.New("newton krylov: finite difference step must be positive")
+	}
+	nDim := len(loc.Init())
+	nk.d = make([]float64, nDim)
+	nk.jd = make([]float64, nDim)
+	nk.rhs = make([]float64, nDim)
+	nk.xh = make([]float64, nDim)
+	return nil
+}
+
+func (nk *NewtonKrylov) Iterate(loc *multi.Location, res *multi.Floats, fun optimize.MultiResidual) (status.Status, error) {
+	x := loc.Curr()
+	f := res.Curr()
+	xNorm := floats.Norm(x, 2)
+	// dst = J v by forward differences
+	mul := func(dst, v []float64) error {
+		vNorm := floats.Norm(v, 2)
+		if vNorm == 0 {
+			for i := range dst {
+				dst[i] = 0
+			}
+			return nil
+		}
+		h := nk.FiniteDifferenceStep * (1 + xNorm) / vNorm
+		for i := range x {
+			nk.xh[i] = x[i] + h*v[i]
+		}
+		fh, err := fun.Residual(nk.xh)
+		if err != nil {
+			return err
+		}
+		for i := range dst {
+			dst[i] = (fh[i] - f[i]) / h
+		}
+		return nil
+	}
+
+	for i, v := range f {
+		nk.rhs[i] = -v
+		nk.d[i] = 0
+	}
+	var err error
+	_, nk.nLinear, err = linalg.GMRES(mul, nk.d, nk.rhs, nk.Forcing*floats.Norm(f, 2), nk.Restart, nk.MaxProducts)
+	if err != nil {
+		return status.UserFunctionError, errors.New("newton krylov: user defined function error: " + err.Error())
+	}
+	if floats.Norm(nk.d, 2) == 0 || math.IsNaN(floats.Norm(nk.d, 2)) {
+		return status.OptimizerError, errors.New("newton krylov: linear solve failed")
+	}
+	// The derivative along d for the merit linesearch, from a fresh
+	// forward-difference product at d itself rather than the residual
+	// GMRES reached. It is an estimate, so d may not quite be a descent
+	// direction, in which case the linesearch fails
+	if err := mul(nk.jd, nk.d); err != nil {
+		return status.UserFunctionError, errors.New("newton krylov: user defined function error: " + err.Error())
+	}
+	if _, _, err := nk.search(loc, res, fun, nk.d, nk.jd); err != nil {
+		return status.LinesearchFailure, errors.New("newton krylov: " + err.Error())
+	}
+	return status.Continue, nil
+}
+
+func (nk *NewtonKrylov) AddToDisplay(d []*display.Struct) []*display.Struct {
+	return append(d, &display.Struct{Value: float64(nk.nLinear), Heading: "Products"})
+}
@@ -0,0 +1,65 @@
+package nonlin
+
+import (
+	"github.com/btracey/gofunopter/common/linalg"
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+
+	"errors"
+)
+
+// Newton is Newton's method with the Jacobian provided by the user
+// defined function, which must implement optimize.MultiJacobian. Each
+// iteration solves J d = -F with an LU factorization and searches along d
+// with the merit linesearch
+type Newton struct {
+	// Tunable parameters
+	*Merit
+
+	// Other needed variables
+	d  []float64
+	jd []float64
+}
+
+func NewNewton() *Newton {
+	return &Newton{
+		Merit: NewMerit(),
+	}
+}
+
+func (n *Newton) Initialize(loc *multi.Location, res *multi.Floats) error {
+	nDim := len(loc.Init())
+	n.d = make([]float64, nDim)
+	n.jd = make([]float64, nDim)
+	return nil
+}
+
+func (n *Newton) Iterate(loc *multi.Location, res *multi.Floats, fun optimize.MultiResidual) (status.Status, error) {
+	j, ok := fun.(optimize.MultiJacobian)
+	if !ok {
+		return status.OptimizerError, errors.New("newton: " + errNoJacobian.Error())
+	}
+	jac, err := j.Jacobian(loc.Curr())
+	if err == errNoJacobian {
+		return status.OptimizerError, errors.New("newton: " + err.Error())
+	}
+	if err != nil {
+		return status.UserFunctionError, errors.New("newton: user defined function error: " + err.Error())
+	}
+	lu, err := linalg.NewLU(jac)
+	if err != nil {
+		return status.IllConditioned, errors.New("newton: " + err.Error())
+	}
+	f := res.Curr()
+	for i, v := range f {
+		n.jd[i] = -v
+	}
+	lu.Solve(n.d, n.jd)
+	matVec(n.jd, jac, n.d)
+
+	if _, _, err := n.search(loc, res, fun, n.d, n.jd); err != nil {
+		return status.LinesearchFailure, errors.New("newton: " + err.Error())
+	}
+	return status.Continue, nil
+}
@@ -0,0 +1,143 @@
+package nonlin
+
+import (
+	"github.com/btracey/gofunopter/common/status"
+
+	"github.com/gonum/floats"
+	"math"
+	"testing"
+)
+
+// circleExp is x0^2 + x1^2 = 4, exp(x0) + x1 = 1
+type circleExp struct{}
+
+func (circleExp) Residual(x []float64) ([]float64, error) {
+	return []float64{x[0]*x[0] + x[1]*x[1] - 4, math.Exp(x[0]) + x[1] - 1}, nil
+}
+
+func (circleExp) Jacobian(x []float64) ([][]float64, error) {
+	return [][]float64{
+		{2 * x[0], 2 * x[1]},
+		{math.Exp(x[0]), 1},
+	}, nil
+}
+
+// broydenTridiagonal is Broyden's tridiagonal function
+type broydenTridiagonal struct{}
+
+func (broydenTridiagonal) Residual(x []float64) ([]float64, error) {
+	n := len(x)
+	f := make([]float64, n)
+	for i := range x {
+		f[i] = (3-2*x[i])*x[i] + 1
+		if i > 0 {
+			f[i] -= x[i-1]
+		}
+		if i < n-1 {
+			f[i] -= 2 * x[i+1]
+		}
+	}
+	return f, nil
+}
+
+func (broydenTridiagonal) Jacobian(x []float64) ([][]float64, error) {
+	n := len(x)
+	jac := make([][]float64, n)
+	for i := range jac {
+		jac[i] = make([]float64, n)
+		jac[i][i] = 3 - 4*x[i]
+		if i > 0 {
+			jac[i][i-1] = -1
+		}
+		if i < n-1 {
+			jac[i][i+1] = -2
+		}
+	}
+	return jac, nil
+}
+
+// residualOnly hides the Jacobian of the embedded function
+type residualOnly struct {
+	fun interface {
+		Residual([]float64) ([]float64, error)
+	}
+}
+
+func (r residualOnly) Residual(x []float64) ([]float64, error) {
+	return r.fun.Residual(x)
+}
+
+func TestSolve(t *testing.T) {
+	tridiagInit := make([]float64, 10)
+	for i := range tridiagInit {
+		tridiagInit[i] = -1
+	}
+	problems := []struct {
+		name string
+		fun  interface {
+			Residual([]float64) ([]float64, error)
+			Jacobian([]float64) ([][]float64, error)
+		}
+		init []float64
+	}{
+		{"CircleExp", circleExp{}, []float64{1, -1.5}},
+		{"BroydenTridiagonal", broydenTridiagonal{}, tridiagInit},
+	}
+	methods := []struct {
+		name    string
+		method  func() Method
+		needJac bool
+	}{
+		{"Newton", func() Method { return NewNewton() }, true},
+		{"GoodBroyden", func() Method { return NewBroyden() }, false},
+		{"BadBroyden", func() Method {
+			b := NewBroyden()
+			b.Update = BadBroyden
+			return b
+		}, false},
+		{"NewtonKrylov", func() Method { return NewNewtonKrylov() }, false},
+	}
+	for _, p := range problems {
+		for _, m := range methods {
+			settings := NewSettings()
+			settings.Display = false
+			var x []float64
+			var result *Result
+			var err error
+			if m.needJac {
+				x, result, err = Solve(p.fun, p.init, settings, m.method())
+			} else {
+				x, result, err = Solve(residualOnly{p.fun}, p.init, settings, m.method())
+			}
+			if err != nil {
+				t.Errorf("%v %v: error solving: %v", p.name, m.name, err)
+				continue
+			}
+			if result.Status != status.ObjAbsTol {
+				t.Errorf("%v %v: status %v, expected %v", p.name, m.name, result.Status, status.ObjAbsTol)
+			}
+			f, _ := p.fun.Residual(x)
+			if floats.Norm(f, 2) > 1E-8 {
+				t.Errorf("%v %v: residual norm %v at solution", p.name, m.name, floats.Norm(f, 2))
+			}
+		}
+	}
+}
+
+func TestSolveDefault(t *testing.T) {
+	settings := NewSettings()
+	settings.Display = false
+	_, result, err := Solve(residualOnly{circleExp{}}, []float64{1, -1.5}, settings, nil)
+	if err != nil {
+		t.Fatalf("error solving without a jacobian: %v", err)
+	}
+	if result.ResidualNorm > 1E-10 {
+		t.Errorf("residual norm %v at solution", result.ResidualNorm)
+	}
+
+	// Newton requires the jacobian
+	_, _, err = Solve(residualOnly{circleExp{}}, []float64{1, -1.5}, settings, NewNewton())
+	if err == nil {
+		t.Errorf("no error for newton without a jacobian")
+	}
+}
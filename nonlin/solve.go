@@ -0,0 +1,362 @@
+// Package nonlin solves systems of nonlinear equations F(x) = 0 with as
+// many equations as unknowns
+package nonlin
+
+import (
+	"github.com/btracey/gofunopter/common"
+	"github.com/btracey/gofunopter/common/display"
+	"github.com/btracey/gofunopter/common/linesearch"
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/univariate"
+
+	"errors"
+	"github.com/gonum/floats"
+	"math"
+)
+
+var errNoJacobian = errors.New("user defined function does not implement optimize.MultiJacobian")
+
+type moddedFun struct {
+	fun      optimize.MultiResidual
+	loc      *multi.Location
+	res      *multi.Floats
+	funEvals *common.FunctionEvaluations
+}
+
+func (m *moddedFun) Residual(x []float64) (f []float64, err error) {
+	f, err = m.fun.Residual(x)
+	m.loc.AddToHist(x)
+	m.res.AddToHist(f)
+	m.funEvals.Add(1)
+	if err == nil && len(f) != len(x) {
+		err = errors.New("user defined function returned incorrect residual length")
+	}
+	return
+}
+
+// Jacobian evaluates the user defined Jacobian, which is counted as a
+// function evaluation. It returns an error if the user defined function
+// does not provide the Jacobian
+func (m *moddedFun) Jacobian(x []float64) (jac [][]float64, err error) {
+	j, ok := m.fun.(optimize.MultiJacobian)
+	if !ok {
+		return nil, errNoJacobian
+	}
+	jac, err = j.Jacobian(x)
+	m.funEvals.Add(1)
+	if err == nil && len(jac) != len(x) {
+		err = errors.New("user defined function returned incorrect jacobian size")
+	}
+	return
+}
+
+// hasJacobian returns true if the user defined function provides the
+// Jacobian
+func hasJacobian(fun optimize.MultiResidual) bool {
+	if m, ok := fun.(*moddedFun); ok {
+		fun = m.fun
+	}
+	_, ok := fun.(optimize.MultiJacobian)
+	return ok
+}
+
+// finiteDifferenceJacobian estimates the Jacobian at x, where the residual
+// is f, with forward differences
+func finiteDifferenceJacobian(fun optimize.MultiResidual, x, f []float64, step float64) ([][]float64, error) {
+	n := len(x)
+	jac := make([][]float64, n)
+	for i := range jac {
+		jac[i] = make([]float64, n)
+	}
+	xh := make([]float64, n)
+	copy(xh, x)
+	for j := range x {
+		h := step * math.Max(1, math.Abs(x[j]))
+		xh[j] = x[j] + h
+		fh, err := fun.Residual(xh)
+		if err != nil {
+			return nil, err
+		}
+		for i := range jac {
+			jac[i][j] = (fh[i] - f[i]) / h
+		}
+		xh[j] = x[j]
+	}
+	return jac, nil
+}
+
+func matVec(dst []float64, a [][]float64, v []float64) {
+	for i, row := range a {
+		dst[i] = floats.Dot(row, v)
+	}
+}
+
+// Method is a method for solving nonlinear equations. Each iteration
+// should move the location and set the residual there
+type Method interface {
+	Initialize(loc *multi.Location, res *multi.Floats) error
+	Iterate(loc *multi.Location, res *multi.Floats, fun optimize.MultiResidual) (status.Status, error)
+}
+
+// Solve finds a solution of F(x) = 0 starting from initialLocation. The
+// search converges with status.ObjAbsTol when the norm of the residual is
+// less than settings.ResidualAbsoluteTolerance, and with
+// status.StepAbsTol when an iteration moves the location less than
+// settings.StepAbsoluteTolerance. If method is nil, Newton is used if the
+// function implements optimize.MultiJacobian and Broyden otherwise
+func Solve(function optimize.MultiResidual, initialLocation []float64, settings *Settings, method Method) (x []float64, result *Result, err error) {
+	if settings == nil {
+		settings = NewSettings()
+	}
+	if method == nil {
+		if hasJacobian(function) {
+			method = NewNewton()
+		} else {
+			method = NewBroyden()
+		}
+	}
+
+	s := newSolveStruct()
+	s.fun = &moddedFun{
+		fun:      function,
+		loc:      s.loc,
+		res:      s.res,
+		funEvals: s.FunEvals,
+	}
+	s.settings = settings
+	s.method = method
+
+	s.loc.SetInit(initialLocation)
+	err = optimize.OptimizeOpter(s, function)
+	return s.loc.Opt(), s.Result(), err
+}
+
+type Result struct {
+	*common.CommonResult
+	*multi.LocationResult
+	Residual        []float64
+	ResidualHistory [][]float64
+	ResidualNorm    float64
+}
+
+type Settings struct {
+	*common.CommonSettings
+	*multi.LocationSettings
+	InitialResidual           []float64
+	DisplayResidual           bool
+	KeepResidualHistory       bool
+	ResidualAbsoluteTolerance float64 // Norm of the residual below which the search converges
+	StepAbsoluteTolerance     float64 // Length of a step below which the search converges
+}
+
+func NewSettings() *Settings {
+	return &Settings{
+		CommonSettings:            common.NewCommonSettings(),
+		LocationSettings:          multi.NewLocationSettings(),
+		DisplayResidual:           true,
+		ResidualAbsoluteTolerance: 1E-10,
+		StepAbsoluteTolerance:     1E-14,
+	}
+}
+
+type solveStruct struct {
+	*common.OptCommon
+
+	loc  *multi.Location
+	res  *multi.Floats
+	prev []float64 // location at the start of the last iteration
+	step float64   // length of the last step
+
+	// User defined function
+	fun *moddedFun
+
+	// Solution method
+	method Method
+
+	// Settings
+	settings *Settings
+}
+
+func newSolveStruct() *solveStruct {
+	return &solveStruct{
+		OptCommon: common.NewOptCommon(),
+		loc:       multi.NewLocation(),
+		res:       multi.NewFloat("Residual", true),
+	}
+}
+
+func (s *solveStruct) CommonSettings() *common.CommonSettings {
+	return s.settings.CommonSettings
+}
+
+func (s *solveStruct) SetSettings() error {
+	s.loc.SetSettings(s.settings.LocationSettings)
+	if s.settings.InitialResidual != nil {
+		s.res.SetInit(s.settings.InitialResidual)
+	}
+	s.res.SetDisp(s.settings.DisplayResidual)
+	s.res.SetSaveHist(s.settings.KeepResidualHistory)
+	return nil
+}
+
+func (s *solveStruct) Status() status.Status {
+	if s.res.Norm() < s.settings.ResidualAbsoluteTolerance {
+		return status.ObjAbsTol
+	}
+	if s.step < s.settings.StepAbsoluteTolerance {
+		return status.StepAbsTol
+	}
+	statuser, ok := s.method.(status.Statuser)
+	if ok {
+		return statuser.Status()
+	}
+	return status.Continue
+}
+
+func (s *solveStruct) AddToDisplay(d []*display.Struct) []*display.Struct {
+	d = display.AddToDisplay(d, s.loc, s.res)
+	displayer, ok := s.method.(display.Displayer)
+	if ok {
+		d = displayer.AddToDisplay(d)
+	}
+	return d
+}
+
+func (s *solveStruct) Result() *Result {
+	r := &Result{
+		CommonResult:    s.OptCommon.CommonResult(),
+		LocationResult:  s.loc.Result(),
+		Residual:        s.res.Opt(),
+		ResidualHistory: s.res.Hist(),
+	}
+	r.ResidualNorm = floats.Norm(r.Residual, 2)
+	return r
+}
+
+func (s *solveStruct) SetResult() {
+	optimize.SetResult(s.loc, s.res)
+
+	setResulter, ok := s.method.(optimize.SetResulter)
+	if ok {
+		setResulter.SetResult()
+	}
+}
+
+func (s *solveStruct) Initialize() error {
+	if s.res.Init() == nil {
+		initRes, err := s.fun.Residual(s.loc.Init())
+		if err != nil {
+			return errors.New("error calling function during optimization: \n" + err.Error())
+		}
+		s.res.SetInit(initRes)
+	}
+	if len(s.res.Init()) != len(s.loc.Init()) {
+		return errors.New("nonlin: residual and location lengths do not match")
+	}
+	s.prev = make([]float64, len(s.loc.Init()))
+	s.step = math.Inf(1)
+
+	err := optimize.Initialize(s.loc, s.res)
+	if err != nil {
+		return err
+	}
+	return s.method.Initialize(s.loc, s.res)
+}
+
+func (s *solveStruct) Iterate() (status.Status, error) {
+	copy(s.prev, s.loc.Curr())
+	c, err := s.method.Iterate(s.loc, s.res, s.fun)
+	s.step = floats.Distance(s.prev, s.loc.Curr(), 2)
+	return c, err
+}
+
+// Merit is the linesearch on the merit function 1/2 ||F(x)||^2 used by
+// the methods to globalize their steps. The linesearch starts with the
+// full step. The derivative of the merit function along the step d is
+// F^T J d, where J d is only known at the start of the linesearch, so it
+// is used at every trial location. The approximation is exact at the
+// start, and good near a solution or when F is nearly linear, which is
+// when the full step is accepted
+type Merit struct {
+	LinesearchMethod   linesearch.LinesearchMethod
+	LinesearchSettings *univariate.UniGradSettings
+	Wolfe              linesearch.WolfeConditioner
+}
+
+// NewMerit returns the default merit linesearch, which uses Cubic and the
+// strong Wolfe conditions with a sufficient decrease of 1E-4
+func NewMerit() *Merit {
+	m := &Merit{
+		LinesearchMethod:   univariate.NewCubic(),
+		LinesearchSettings: univariate.NewUniGradSettings(),
+		Wolfe:              &linesearch.StrongWolfeConditions{},
+	}
+	m.Wolfe.SetFunConst(1E-4)
+	m.Wolfe.SetGradConst(0.9)
+	m.LinesearchSettings.MaximumFunctionEvaluations = 100
+	m.LinesearchSettings.Display = false
+	m.LinesearchSettings.GradientAbsoluteTolerance = 0 // Force convergence from wolfe conditions
+	return m
+}
+
+// meritFun is the merit function along the step d, where the derivative
+// of the residual along d is jd
+type meritFun struct {
+	fun    optimize.MultiResidual
+	d      []float64
+	jd     []float64
+	dNorm2 float64
+	f      []float64 // residual at the last location evaluated
+	grad   []float64
+}
+
+func (m *meritFun) ObjGrad(x []float64) (obj float64, grad []float64, err error) {
+	f, err := m.fun.Residual(x)
+	if err != nil {
+		return math.NaN(), nil, err
+	}
+	copy(m.f, f)
+	// The gradient along the line, since the rest is unknown
+	s := floats.Dot(f, m.jd) / m.dNorm2
+	for i, v := range m.d {
+		m.grad[i] = s * v
+	}
+	return 0.5 * floats.Dot(f, f), m.grad, nil
+}
+
+// search runs the linesearch along d from the current location, where the
+// derivative of the residual along d is jd, and moves the location and
+// residual to the point found. It returns the step taken and the change
+// in the residual
+func (m *Merit) search(loc *multi.Location, res *multi.Floats, fun optimize.MultiResidual, d, jd []float64) (s, y []float64, err error) {
+	x := loc.Curr()
+	f := res.Curr()
+	n := len(x)
+	mf := &meritFun{
+		fun:    fun,
+		d:      d,
+		jd:     jd,
+		dNorm2: floats.Dot(d, d),
+		f:      make([]float64, n),
+		grad:   make([]float64, n),
+	}
+	initGrad := make([]float64, n)
+	copy(initGrad, d)
+	floats.Scale(floats.Dot(f, jd)/mf.dNorm2, initGrad)
+	if floats.Dot(f, jd) >= 0 {
+		return nil, nil, errors.New("step is not a descent direction of the merit function")
+	}
+	result, err := linesearch.Linesearch(mf, m.LinesearchMethod, m.LinesearchSettings, m.Wolfe, d, x, 0.5*floats.Dot(f, f), initGrad)
+	if err != nil {
+		return nil, nil, err
+	}
+	s = make([]float64, n)
+	floats.SubTo(s, result.Loc, x)
+	y = make([]float64, n)
+	floats.SubTo(y, mf.f, f)
+	loc.SetCurr(result.Loc)
+	res.SetCurr(mf.f)
+	return s, y, nil
+}
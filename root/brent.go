@@ -0,0 +1,119 @@
+package root
+
+import (
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+
+	"math"
+)
+
+// eps is the machine precision
+const eps = 2.220446049250313e-16
+
+// Brent is the Brent-Dekker method, which combines inverse quadratic
+// interpolation and the secant method with bisection. It converges
+// superlinearly for smooth functions, and never takes many more iterations
+// than bisection
+type Brent struct {
+	// Other needed variables
+	a, fa float64 // previous estimate
+	b, fb float64 // current estimate
+	c, fc float64 // contrapoint, where the function has the opposite sign to b
+	d, e  float64 // last step and the step before
+}
+
+func NewBrent() *Brent {
+	return &Brent{}
+}
+
+func (br *Brent) Initialize(b *Bracket) error {
+	br.a, br.fa = b.Lo, b.FLo
+	br.b, br.fb = b.Hi, b.FHi
+	br.c, br.fc = br.a, br.fa
+	br.d = br.b - br.a
+	br.e = br.d
+	br.order()
+	return nil
+}
+
+// order swaps b and c so that b is the better estimate
+func (br *Brent) order() {
+	if math.Abs(br.fc) < math.Abs(br.fb) {
+		br.a, br.fa = br.b, br.fb
+		br.b, br.fb = br.c, br.fc
+		br.c, br.fc = br.a, br.fa
+	}
+}
+
+func (br *Brent) Iterate(b *Bracket, fun optimize.UniObj) (status.Status, error) {
+	tol := 2*eps*math.Abs(br.b) + math.SmallestNonzeroFloat64
+	xm := (br.c - br.b) / 2
+	if math.Abs(xm) <= tol {
+		return status.StepAbsTol, nil
+	}
+	if math.Abs(br.e) >= tol && math.Abs(br.fa) > math.Abs(br.fb) {
+		// Interpolate
+		var p, q float64
+		s := br.fb / br.fa
+		if br.a == br.c {
+			// Secant
+			p = 2 * xm * s
+			q = 1 - s
+		} else {
+			// Inverse quadratic
+			q = br.fa / br.fc
+			r := br.fb / br.fc
+			p = s * (2*xm*q*(q-r) - (br.b-br.a)*(r-1))
+			q = (q - 1) * (r - 1) * (s - 1)
+		}
+		if p > 0 {
+			q = -q
+		}
+		p = math.Abs(p)
+		if 2*p < math.Min(3*xm*q-math.Abs(tol*q), math.Abs(br.e*q)) {
+			br.e = br.d
+			br.d = p / q
+		} else {
+			// Interpolation failed, bisect
+			br.d = xm
+			br.e = br.d
+		}
+	} else {
+		// Bounds decreasing too slowly, bisect
+		br.d = xm
+		br.e = br.d
+	}
+	br.a, br.fa = br.b, br.fb
+	if math.Abs(br.d) > tol {
+		br.b += br.d
+	} else if xm > 0 {
+		br.b += tol
+	} else {
+		br.b -= tol
+	}
+	fb, err := fun.Objective(br.b)
+	if err != nil {
+		return optimize.EvalStatus("brent", err)
+	}
+	br.fb = fb
+	if sameSign(br.fb, br.fc) {
+		br.c, br.fc = br.a, br.fa
+		br.d = br.b - br.a
+		br.e = br.d
+	}
+	br.order()
+
+	if br.fb == 0 {
+		b.Update(br.b, br.fb)
+		return status.Continue, nil
+	}
+	// The bracket is between b and the contrapoint
+	b.Lo, b.FLo = br.b, br.fb
+	b.Hi, b.FHi = br.c, br.fc
+	if b.Lo > b.Hi {
+		b.Lo, b.Hi = b.Hi, b.Lo
+		b.FLo, b.FHi = b.FHi, b.FLo
+	}
+	b.X, b.FX = br.b, br.fb
+	return status.Continue, nil
+}
@@ -0,0 +1,256 @@
+// Package root finds zeros of univariate functions f(x) = 0 within an
+// interval over which the function changes sign
+package root
+
+import (
+	"github.com/btracey/gofunopter/common"
+	"github.com/btracey/gofunopter/common/display"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+	"math"
+)
+
+// Bracket is the state shared by the root finding methods: an interval
+// [Lo, Hi] over which the function changes sign, and the current estimate
+// X of the root within it. The function values at Lo and Hi are never of
+// the same sign
+type Bracket struct {
+	Lo, Hi   float64
+	FLo, FHi float64
+	X, FX    float64
+}
+
+// Width returns the width of the bracket
+func (b *Bracket) Width() float64 {
+	return b.Hi - b.Lo
+}
+
+// Update sets the estimate of the root to x, where the function value is
+// fx, and replaces the end of the bracket with the same sign as fx. If fx
+// is zero the bracket closes onto x
+func (b *Bracket) Update(x, fx float64) {
+	b.X, b.FX = x, fx
+	switch {
+	case fx == 0:
+		b.Lo, b.Hi = x, x
+		b.FLo, b.FHi = fx, fx
+	case sameSign(fx, b.FLo):
+		b.Lo, b.FLo = x, fx
+	default:
+		b.Hi, b.FHi = x, fx
+	}
+}
+
+// Midpoint returns the midpoint of the bracket, and false if it is not
+// strictly inside the bracket because the bracket is as narrow as floating
+// point allows
+func (b *Bracket) Midpoint() (float64, bool) {
+	m := b.Lo + (b.Hi-b.Lo)/2
+	return m, m > b.Lo && m < b.Hi
+}
+
+// Inside returns true if x is strictly inside the bracket
+func (b *Bracket) Inside(x float64) bool {
+	return x > b.Lo && x < b.Hi
+}
+
+func sameSign(a, b float64) bool {
+	return (a > 0 && b > 0) || (a < 0 && b < 0)
+}
+
+// Method is a root finding method. Each iteration should shrink the
+// bracket and set the estimate of the root
+type Method interface {
+	Initialize(b *Bracket) error
+	Iterate(b *Bracket, fun optimize.UniObj) (status.Status, error)
+}
+
+// Find finds a zero of function in [lo, hi], over which the function must
+// change sign. The search converges with status.ObjAbsTol when the
+// magnitude of the residual is at most settings.ResidualTolerance, and
+// with status.StepAbsTol when the bracket is narrower than
+// settings.WidthTolerance, can't be narrowed further, or the method's own
+// step criterion is met (see Ridders and Newton). If method is nil, Brent
+// is used
+func Find(function optimize.UniObj, lo, hi float64, settings *Settings, method Method) (x float64, result *Result, err error) {
+	if settings == nil {
+		settings = NewSettings()
+	}
+	if method == nil {
+		method = NewBrent()
+	}
+
+	r := newRootStruct()
+	r.fun = &optimize.LimitedUniObj{
+		Fun:      function,
+		Loc:      r.loc,
+		FunEvals: r.FunEvals,
+	}
+	r.settings = settings
+	r.method = method
+	r.lo, r.hi = lo, hi
+
+	err = optimize.OptimizeOpter(r, function)
+	return r.loc.Opt(), r.Result(), err
+}
+
+type Result struct {
+	*common.CommonResult
+	*uni.LocationResult
+	Residual     float64 // Function value at the root found
+	BracketWidth float64 // Width of the final bracket
+}
+
+type Settings struct {
+	*common.CommonSettings
+	*uni.LocationSettings
+	ResidualTolerance float64 // Largest magnitude of the function value accepted as a root
+	WidthTolerance    float64 // Bracket width below which the search converges
+	DisplayResidual   bool
+	DisplayWidth      bool
+}
+
+func NewSettings() *Settings {
+	return &Settings{
+		CommonSettings:    common.NewCommonSettings(),
+		LocationSettings:  uni.NewLocationSettings(),
+		ResidualTolerance: 0,
+		WidthTolerance:    1E-12,
+		DisplayResidual:   true,
+		DisplayWidth:      true,
+	}
+}
+
+type rootStruct struct {
+	*common.OptCommon
+
+	loc    *uni.Location
+	lo, hi float64
+	br     *Bracket
+
+	// User defined function
+	fun *optimize.LimitedUniObj
+
+	// Root finding method
+	method Method
+
+	// Settings
+	settings *Settings
+
+	residual float64
+	width    float64
+}
+
+func newRootStruct() *rootStruct {
+	return &rootStruct{
+		OptCommon: common.NewOptCommon(),
+		loc:       uni.NewLocation(),
+		br:        &Bracket{},
+	}
+}
+
+func (r *rootStruct) CommonSettings() *common.CommonSettings {
+	return r.settings.CommonSettings
+}
+
+func (r *rootStruct) SetSettings() error {
+	r.loc.SetSettings(r.settings.LocationSettings)
+	return nil
+}
+
+func (r *rootStruct) Status() status.Status {
+	if math.Abs(r.br.FX) <= r.settings.ResidualTolerance {
+		return status.ObjAbsTol
+	}
+	if r.br.Width() < r.settings.WidthTolerance {
+		return status.StepAbsTol
+	}
+	statuser, ok := r.method.(status.Statuser)
+	if ok {
+		return statuser.Status()
+	}
+	return status.Continue
+}
+
+func (r *rootStruct) AddToDisplay(d []*display.Struct) []*display.Struct {
+	r.loc.SetCurr(r.br.X)
+	d = display.AddToDisplay(d, r.loc)
+	if r.settings.DisplayResidual {
+		d = append(d, &display.Struct{Value: r.br.FX, Heading: "Residual"})
+	}
+	if r.settings.DisplayWidth {
+		d = append(d, &display.Struct{Value: r.br.Width(), Heading: "Width"})
+	}
+	displayer, ok := r.method.(display.Displayer)
+	if ok {
+		d = displayer.AddToDisplay(d)
+	}
+	return d
+}
+
+func (r *rootStruct) Result() *Result {
+	return &Result{
+		CommonResult:   r.OptCommon.CommonResult(),
+		LocationResult: r.loc.Result(),
+		Residual:       r.residual,
+		BracketWidth:   r.width,
+	}
+}
+
+func (r *rootStruct) SetResult() {
+	r.loc.SetCurr(r.br.X)
+	r.residual = r.br.FX
+	r.width = r.br.Width()
+	optimize.SetResult(r.loc)
+
+	setResulter, ok := r.method.(optimize.SetResulter)
+	if ok {
+		setResulter.SetResult()
+	}
+}
+
+func (r *rootStruct) Initialize() error {
+	lo, hi := r.lo, r.hi
+	if !(lo <= hi) {
+		return errors.New("root: lower end of the bracket is above the upper end")
+	}
+	// The bracket is set first so the result is sensible on error
+	r.br.Lo, r.br.Hi = lo, hi
+	r.br.FLo, r.br.FHi = math.NaN(), math.NaN()
+	r.br.X, r.br.FX = lo, math.NaN()
+	r.loc.SetInit(lo)
+	if err := r.loc.Initialize(); err != nil {
+		return err
+	}
+	flo, err := r.fun.Objective(lo)
+	if err != nil {
+		return errors.New("root: error calling function during initialization: " + err.Error())
+	}
+	fhi, err := r.fun.Objective(hi)
+	if err != nil {
+		return errors.New("root: error calling function during initialization: " + err.Error())
+	}
+	if math.IsNaN(flo) || math.IsNaN(fhi) {
+		return errors.New("root: function value is NaN at the end of the bracket")
+	}
+	r.br.FLo, r.br.FHi = flo, fhi
+	r.br.X, r.br.FX = lo, flo
+	if math.Abs(fhi) < math.Abs(flo) {
+		r.br.X, r.br.FX = hi, fhi
+	}
+	if flo == 0 || fhi == 0 {
+		r.br.Update(r.br.X, r.br.FX)
+		return r.method.Initialize(r.br)
+	}
+	if sameSign(flo, fhi) {
+		return errors.New("root: function has the same sign at both ends of the bracket")
+	}
+	return r.method.Initialize(r.br)
+}
+
+func (r *rootStruct) Iterate() (status.Status, error) {
+	return r.method.Iterate(r.br, r.fun)
+}
@@ -0,0 +1,59 @@
+package root
+
+import (
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+)
+
+// Illinois is regula falsi (the secant through the ends of the bracket)
+// with the Illinois modification: when the same end of the bracket is
+// kept twice in a row, its function value is halved. This prevents one
+// end from getting stuck, as happens with plain regula falsi on convex
+// functions, and gives superlinear convergence
+type Illinois struct {
+	// Other needed variables
+	side int // -1 if Lo was replaced last, 1 if Hi was, 0 otherwise
+}
+
+func NewIllinois() *Illinois {
+	return &Illinois{}
+}
+
+func (il *Illinois) Initialize(b *Bracket) error {
+	il.side = 0
+	return nil
+}
+
+func (il *Illinois) Iterate(b *Bracket, fun optimize.UniObj) (status.Status, error) {
+	x := (b.Lo*b.FHi - b.Hi*b.FLo) / (b.FHi - b.FLo)
+	if !b.Inside(x) {
+		var ok bool
+		x, ok = b.Midpoint()
+		if !ok {
+			return status.StepAbsTol, nil
+		}
+	}
+	fx, err := fun.Objective(x)
+	if err != nil {
+		return optimize.EvalStatus("illinois", err)
+	}
+	// The function values at the ends are modified, so keep them
+	// for the update to compare signs against
+	flo, fhi := b.FLo, b.FHi
+	replacedLo := sameSign(fx, flo)
+	b.Update(x, fx)
+	switch {
+	case fx == 0:
+	case replacedLo:
+		if il.side == -1 {
+			b.FHi = fhi / 2
+		}
+		il.side = -1
+	default:
+		if il.side == 1 {
+			b.FLo = flo / 2
+		}
+		il.side = 1
+	}
+	return status.Continue, nil
+}
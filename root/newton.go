@@ -0,0 +1,75 @@
+package root
+
+import (
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+
+	"errors"
+	"math"
+)
+
+// Newton is Newton's method safeguarded by the bracket. The function must
+// implement optimize.UniObjGrad, where the gradient is the derivative of
+// the function whose root is sought. When the Newton step would leave the
+// bracket (or the derivative is zero) the bracket is bisected instead. The
+// first iteration bisects, since the derivative at the ends of the
+// bracket is not known. Newton converges from one side for convex
+// functions, so it also converges with status.StepAbsTol when the step is
+// smaller than StepTolerance
+type Newton struct {
+	// Tunable parameters
+	StepTolerance float64
+
+	// Other needed variables
+	deriv float64 // derivative at the current estimate of the root
+	step  float64 // length of the last step
+}
+
+func NewNewton() *Newton {
+	return &Newton{
+		StepTolerance: 1E-12,
+	}
+}
+
+func (n *Newton) Initialize(b *Bracket) error {
+	if n.StepTolerance < 0 {
+		return errors.New("newton: step tolerance must be non-negative")
+	}
+	n.deriv = math.NaN()
+	n.step = math.Inf(1)
+	return nil
+}
+
+func (n *Newton) Status() status.Status {
+	if n.step < n.StepTolerance {
+		return status.StepAbsTol
+	}
+	return status.Continue
+}
+
+func (n *Newton) Iterate(b *Bracket, fun optimize.UniObj) (status.Status, error) {
+	g, ok := fun.(optimize.UniObjGrad)
+	if !ok {
+		return optimize.EvalStatus("newton", optimize.ErrNoDerivative)
+	}
+	x := b.X - b.FX/n.deriv
+	if x == b.X {
+		// The step is lost to rounding
+		return status.StepAbsTol, nil
+	}
+	if !b.Inside(x) {
+		// Also true for NaN
+		x, ok = b.Midpoint()
+		if !ok {
+			return status.StepAbsTol, nil
+		}
+	}
+	fx, deriv, err := g.ObjGrad(x)
+	if err != nil {
+		return optimize.EvalStatus("newton", err)
+	}
+	n.deriv = deriv
+	n.step = math.Abs(x - b.X)
+	b.Update(x, fx)
+	return status.Continue, nil
+}
@@ -0,0 +1,92 @@
+package root
+
+import (
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+
+	"errors"
+	"math"
+)
+
+// Ridders is Ridders' method. Each iteration evaluates the function at the
+// midpoint of the bracket, and then at the root of the exponential
+// interpolation through the ends and the midpoint. It converges
+// quadratically per iteration (two function evaluations), and the bracket
+// at least halves every iteration. The estimate often approaches the root
+// from one side, leaving the other end of the bracket behind, so Ridders
+// also converges with status.StepAbsTol when the estimate moves less than
+// StepTolerance
+type Ridders struct {
+	// Tunable parameters
+	StepTolerance float64
+
+	// Other needed variables
+	step float64 // change in the estimate in the last iteration
+}
+
+func NewRidders() *Ridders {
+	return &Ridders{
+		StepTolerance: 1E-12,
+	}
+}
+
+func (r *Ridders) Initialize(b *Bracket) error {
+	if r.StepTolerance < 0 {
+		return errors.New("ridders: step tolerance must be non-negative")
+	}
+	r.step = math.Inf(1)
+	return nil
+}
+
+func (r *Ridders) Status() status.Status {
+	if r.step < r.StepTolerance {
+		return status.StepAbsTol
+	}
+	return status.Continue
+}
+
+func (r *Ridders) Iterate(b *Bracket, fun optimize.UniObj) (status.Status, error) {
+	prev := b.X
+	defer func() { r.step = math.Abs(b.X - prev) }()
+	m, ok := b.Midpoint()
+	if !ok {
+		return status.StepAbsTol, nil
+	}
+	fm, err := fun.Objective(m)
+	if err != nil {
+		return optimize.EvalStatus("ridders", err)
+	}
+	if fm == 0 {
+		b.Update(m, fm)
+		return status.Continue, nil
+	}
+	s := math.Sqrt(fm*fm - b.FLo*b.FHi)
+	x := m + (m-b.Lo)*math.Copysign(1, b.FLo-b.FHi)*fm/s
+	if !b.Inside(x) || x == m {
+		// Rounding error, so only the midpoint is used
+		b.Update(m, fm)
+		return status.Continue, nil
+	}
+	fx, err := fun.Objective(x)
+	if err != nil {
+		// The midpoint still improves the bracket
+		b.Update(m, fm)
+		return optimize.EvalStatus("ridders", err)
+	}
+	if !sameSign(fm, fx) && fx != 0 {
+		// The root is between the midpoint and x
+		if m < x {
+			b.Lo, b.FLo, b.Hi, b.FHi = m, fm, x, fx
+		} else {
+			b.Lo, b.FLo, b.Hi, b.FHi = x, fx, m, fm
+		}
+		b.X, b.FX = x, fx
+		if math.Abs(fm) < math.Abs(fx) {
+			b.X, b.FX = m, fm
+		}
+		return status.Continue, nil
+	}
+	b.Update(m, fm)
+	b.Update(x, fx)
+	return status.Continue, nil
+}
@@ -0,0 +1,124 @@
+package root
+
+import (
+	"github.com/btracey/gofunopter/common/status"
+
+	"math"
+	"testing"
+)
+
+type rootTestFunction struct {
+	name   string
+	f      func(x float64) float64
+	df     func(x float64) float64
+	lo, hi float64
+	root   float64
+}
+
+func (r rootTestFunction) Objective(x float64) (float64, error) {
+	return r.f(x), nil
+}
+
+// withDeriv adds the derivative for Newton
+type withDeriv struct {
+	rootTestFunction
+}
+
+func (w withDeriv) ObjGrad(x float64) (float64, float64, error) {
+	return w.f(x), w.df(x), nil
+}
+
+func rootTestFunctions() []rootTestFunction {
+	return []rootTestFunction{
+		{
+			name: "Cubic",
+			f:    func(x float64) float64 { return x*x*x - 2*x - 5 },
+			df:   func(x float64) float64 { return 3*x*x - 2 },
+			lo:   1, hi: 4,
+			root: 2.0945514815423265,
+		},
+		{
+			name: "CosMinusX",
+			f:    func(x float64) float64 { return math.Cos(x) - x },
+			df:   func(x float64) float64 { return -math.Sin(x) - 1 },
+			lo:   -2, hi: 3,
+			root: 0.7390851332151607,
+		},
+		{
+			// Very flat near the root and steep far from it, which stalls
+			// plain regula falsi
+			name: "Exp",
+			f:    func(x float64) float64 { return math.Exp(x) - 1E-3 },
+			df:   func(x float64) float64 { return math.Exp(x) },
+			lo:   -20, hi: 5,
+			root: math.Log(1E-3),
+		},
+	}
+}
+
+func TestFind(t *testing.T) {
+	methods := map[string]func() Method{
+		"Brent":    func() Method { return NewBrent() },
+		"Illinois": func() Method { return NewIllinois() },
+		"Ridders":  func() Method { return NewRidders() },
+		"Newton":   func() Method { return NewNewton() },
+	}
+	for name, method := range methods {
+		for _, fun := range rootTestFunctions() {
+			settings := NewSettings()
+			settings.Display = false
+			settings.MaximumFunctionEvaluations = 100
+			x, result, err := Find(withDeriv{fun}, fun.lo, fun.hi, settings, method())
+			if err != nil {
+				t.Errorf("%v, %v: error finding root: %v", name, fun.name, err)
+				continue
+			}
+			if result.Status != status.StepAbsTol && result.Status != status.ObjAbsTol {
+				t.Errorf("%v, %v: status is not converged. %v found", name, fun.name, result.Status)
+			}
+			if math.Abs(x-fun.root) > 1E-10 {
+				t.Errorf("%v, %v: root not found. %v found, %v expected", name, fun.name, x, fun.root)
+			}
+			if result.Residual != fun.f(x) {
+				t.Errorf("%v, %v: residual %v is not the function value %v", name, fun.name, result.Residual, fun.f(x))
+			}
+			if math.Abs(x-fun.root) > result.BracketWidth+1E-15 {
+				t.Errorf("%v, %v: bracket width %v does not cover the root", name, fun.name, result.BracketWidth)
+			}
+			if result.FunctionEvaluations > 40 {
+				t.Errorf("%v, %v: %v function evaluations", name, fun.name, result.FunctionEvaluations)
+			}
+		}
+	}
+
+	fun := rootTestFunctions()[0]
+	settings := NewSettings()
+	settings.Display = false
+	settings.ResidualTolerance = 1E-3
+	x, result, err := Find(fun, fun.lo, fun.hi, settings, nil)
+	if err != nil {
+		t.Fatalf("error with a residual tolerance: %v", err)
+	}
+	if result.Status != status.ObjAbsTol || math.Abs(fun.f(x)) > 1E-3 {
+		t.Errorf("residual tolerance not respected. Status %v, residual %v", result.Status, fun.f(x))
+	}
+
+	// Ridders evaluates twice per iteration, and the budget still holds
+	settings = NewSettings()
+	settings.Display = false
+	settings.MaximumFunctionEvaluations = 5
+	_, result, err = Find(fun, fun.lo, fun.hi, settings, NewRidders())
+	if err != nil {
+		t.Errorf("error when the budget runs out: %v", err)
+	}
+	if result.Status != status.MaximumFunctionEvaluations || result.FunctionEvaluations != 5 {
+		t.Errorf("budget not respected. Status %v, %v evaluations", result.Status, result.FunctionEvaluations)
+	}
+
+	if _, _, err = Find(fun, 3, 4, settings, nil); err == nil {
+		t.Errorf("no error without a sign change")
+	}
+	if _, _, err = Find(fun, fun.lo, fun.hi, settings, NewNewton()); err == nil {
+		t.Errorf("no error for Newton without a derivative")
+	}
+}
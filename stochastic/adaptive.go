@@ -0,0 +1,157 @@
+package stochastic
+
+import (
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+	"math"
+)
+
+// Adagrad scales the step in each coordinate by the inverse root of the
+// sum of the squared gradients in that coordinate (Duchi et. al. 2011)
+type Adagrad struct {
+	// Tunable parameters
+	Schedule Schedule
+	Epsilon  float64 // Added to the denominator for numerical stability
+
+	// Other needed variables
+	iter  int
+	sumSq []float64
+	x     []float64
+}
+
+func NewAdagrad() *Adagrad {
+	return &Adagrad{
+		Schedule: Constant{Rate: 0.1},
+		Epsilon:  1E-8,
+	}
+}
+
+func (a *Adagrad) Initialize(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient) error {
+	if a.Schedule == nil {
+		return errors.New("adagrad: schedule is nil")
+	}
+	nDim := len(loc.Init())
+	a.iter = 0
+	a.sumSq = make([]float64, nDim)
+	a.x = make([]float64, nDim)
+	return nil
+}
+
+func (a *Adagrad) Iterate(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient, fun optimize.MultiObjGrad) (status.Status, error) {
+	g := grad.Curr()
+	copy(a.x, loc.Curr())
+	rate := a.Schedule.LearningRate(a.iter)
+	for i, val := range g {
+		a.sumSq[i] += val * val
+		a.x[i] -= rate * val / (math.Sqrt(a.sumSq[i]) + a.Epsilon)
+	}
+	a.iter++
+	return moveTo(a.x, loc, obj, grad, fun)
+}
+
+// Rmsprop scales the step in each coordinate by the inverse root of an
+// exponentially weighted average of the squared gradients
+type Rmsprop struct {
+	// Tunable parameters
+	Schedule Schedule
+	Decay    float64 // Weight of the previous average
+	Epsilon  float64 // Added to the denominator for numerical stability
+
+	// Other needed variables
+	iter  int
+	avgSq []float64
+	x     []float64
+}
+
+func NewRmsprop() *Rmsprop {
+	return &Rmsprop{
+		Schedule: Constant{Rate: 0.001},
+		Decay:    0.9,
+		Epsilon:  1E-8,
+	}
+}
+
+func (r *Rmsprop) Initialize(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient) error {
+	if r.Schedule == nil {
+		return errors.New("rmsprop: schedule is nil")
+	}
+	if r.Decay < 0 || r.Decay >= 1 {
+		return errors.New("rmsprop: decay must be in [0,1)")
+	}
+	nDim := len(loc.Init())
+	r.iter = 0
+	r.avgSq = make([]float64, nDim)
+	r.x = make([]float64, nDim)
+	return nil
+}
+
+func (r *Rmsprop) Iterate(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient, fun optimize.MultiObjGrad) (status.Status, error) {
+	g := grad.Curr()
+	copy(r.x, loc.Curr())
+	rate := r.Schedule.LearningRate(r.iter)
+	for i, val := range g {
+		r.avgSq[i] = r.Decay*r.avgSq[i] + (1-r.Decay)*val*val
+		r.x[i] -= rate * val / (math.Sqrt(r.avgSq[i]) + r.Epsilon)
+	}
+	r.iter++
+	return moveTo(r.x, loc, obj, grad, fun)
+}
+
+// Adam uses bias-corrected exponentially weighted averages of the gradient
+// and the squared gradient (Kingma and Ba, 2015)
+type Adam struct {
+	// Tunable parameters
+	Schedule Schedule
+	Beta1    float64 // Decay of the gradient average
+	Beta2    float64 // Decay of the squared gradient average
+	Epsilon  float64 // Added to the denominator for numerical stability
+
+	// Other needed variables
+	iter  int
+	avg   []float64
+	avgSq []float64
+	x     []float64
+}
+
+func NewAdam() *Adam {
+	return &Adam{
+		Schedule: Constant{Rate: 0.001},
+		Beta1:    0.9,
+		Beta2:    0.999,
+		Epsilon:  1E-8,
+	}
+}
+
+func (a *Adam) Initialize(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient) error {
+	if a.Schedule == nil {
+		return errors.New("adam: schedule is nil")
+	}
+	if a.Beta1 < 0 || a.Beta1 >= 1 || a.Beta2 < 0 || a.Beta2 >= 1 {
+		return errors.New("adam: decay rates must be in [0,1)")
+	}
+	nDim := len(loc.Init())
+	a.iter = 0
+	a.avg = make([]float64, nDim)
+	a.avgSq = make([]float64, nDim)
+	a.x = make([]float64, nDim)
+	return nil
+}
+
+func (a *Adam) Iterate(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient, fun optimize.MultiObjGrad) (status.Status, error) {
+	g := grad.Curr()
+	copy(a.x, loc.Curr())
+	rate := a.Schedule.LearningRate(a.iter)
+	a.iter++
+	correct1 := 1 - math.Pow(a.Beta1, float64(a.iter))
+	correct2 := 1 - math.Pow(a.Beta2, float64(a.iter))
+	for i, val := range g {
+		a.avg[i] = a.Beta1*a.avg[i] + (1-a.Beta1)*val
+		a.avgSq[i] = a.Beta2*a.avgSq[i] + (1-a.Beta2)*val*val
+		a.x[i] -= rate * (a.avg[i] / correct1) / (math.Sqrt(a.avgSq[i]/correct2) + a.Epsilon)
+	}
+	return moveTo(a.x, loc, obj, grad, fun)
+}
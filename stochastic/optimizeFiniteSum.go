@@ -0,0 +1,255 @@
+package stochastic
+
+import (
+	"github.com/btracey/gofunopter/common"
+	"github.com/btracey/gofunopter/common/display"
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+	"math"
+)
+
+// FiniteSumFunction is the user defined finite sum as seen by the optimizer.
+// ObjGrad evaluates the full sum (and counts as one function evaluation),
+// while ComponentObjGrad evaluates a single component (and counts as one
+// component evaluation)
+type FiniteSumFunction interface {
+	optimize.FiniteSum
+	optimize.MultiObjGrad
+}
+
+// errMaximumComponentEvaluations is returned by the component evaluations
+// once the budget has been spent. The optimizers take many component steps
+// per iteration, so the budget is checked at every component evaluation
+// rather than only between iterations
+var errMaximumComponentEvaluations = errors.New("maximum component evaluations reached")
+
+type finiteSumFun struct {
+	fun            optimize.FiniteSum
+	loc            *multi.Location
+	obj            *uni.Objective
+	grad           *multi.Gradient
+	funEvals       *common.FunctionEvaluations
+	componentEvals *common.ComponentEvaluations
+
+	sum []float64
+}
+
+func (f *finiteSumFun) NumComponents() int {
+	return f.fun.NumComponents()
+}
+
+func (f *finiteSumFun) ComponentObjGrad(i int, x []float64) (obj float64, grad []float64, err error) {
+	if f.componentEvals.Curr() >= f.componentEvals.Max() {
+		return math.NaN(), nil, errMaximumComponentEvaluations
+	}
+	obj, grad, err = f.fun.ComponentObjGrad(i, x)
+	f.componentEvals.Add(1)
+	return
+}
+
+// ObjGrad sums all of the components
+func (f *finiteSumFun) ObjGrad(x []float64) (obj float64, grad []float64, err error) {
+	if len(f.sum) != len(x) {
+		f.sum = make([]float64, len(x))
+	}
+	for i := range f.sum {
+		f.sum[i] = 0
+	}
+	n := f.fun.NumComponents()
+	for i := 0; i < n; i++ {
+		o, g, err := f.fun.ComponentObjGrad(i, x)
+		if err != nil {
+			return math.NaN(), nil, err
+		}
+		if len(g) != len(x) {
+			return math.NaN(), nil, errors.New("stochastic: user defined function returned incorrect gradient length")
+		}
+		obj += o
+		for j, val := range g {
+			f.sum[j] += val
+		}
+	}
+	f.loc.AddToHist(x)
+	f.obj.AddToHist(obj)
+	f.grad.AddToHist(f.sum)
+	f.funEvals.Add(1)
+	return obj, f.sum, nil
+}
+
+// FiniteSumOptimizer is a method for minimizing a finite sum. The objective
+// value and gradient set by the optimizer should be those of the full sum.
+// Once ComponentObjGrad reports that the maximum number of component
+// evaluations has been reached, the optimizer should return
+// status.MaximumComponentEvaluations
+type FiniteSumOptimizer interface {
+	Initialize(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient, nComponents int) error
+	Iterate(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient, fun FiniteSumFunction) (status.Status, error)
+}
+
+// OptimizeFiniteSum minimizes f(x) = sum_i f_i(x). FunctionEvaluations in the
+// result counts evaluations of the full sum and ComponentEvaluations counts
+// evaluations of single components outside of full evaluations
+func OptimizeFiniteSum(function optimize.FiniteSum, initialLocation []float64, settings *FiniteSumSettings, optimizer FiniteSumOptimizer) (optValue float64, optLocation []float64, result *FiniteSumResult, err error) {
+
+	if settings == nil {
+		settings = NewFiniteSumSettings()
+	}
+
+	if optimizer == nil {
+		optimizer = NewSvrg()
+	}
+
+	m := newFiniteSumStruct()
+	m.fun = &finiteSumFun{
+		fun:            function,
+		loc:            m.loc,
+		obj:            m.obj,
+		grad:           m.grad,
+		funEvals:       m.FunEvals,
+		componentEvals: m.componentEvals,
+	}
+	m.settings = settings
+	m.optimizer = optimizer
+
+	m.loc.SetInit(initialLocation)
+	err = optimize.OptimizeOpter(m, function)
+
+	return m.obj.Opt(), m.loc.Opt(), m.Result(), err
+}
+
+type FiniteSumResult struct {
+	*common.CommonResult
+	*uni.ObjectiveResult
+	*multi.GradientResult
+	*multi.LocationResult
+	ComponentEvaluations int // Total number of single component evaluations
+}
+
+type FiniteSumSettings struct {
+	*common.CommonSettings
+	*common.ComponentSettings
+	*uni.ObjectiveSettings
+	*multi.GradientSettings
+	*multi.LocationSettings
+}
+
+func NewFiniteSumSettings() *FiniteSumSettings {
+	return &FiniteSumSettings{
+		CommonSettings:    common.NewCommonSettings(),
+		ComponentSettings: common.NewComponentSettings(),
+		ObjectiveSettings: uni.NewObjectiveSettings(),
+		GradientSettings:  multi.NewGradientSettings(),
+		LocationSettings:  multi.NewLocationSettings(),
+	}
+}
+
+type finiteSumStruct struct {
+	*common.OptCommon
+
+	loc            *multi.Location
+	obj            *uni.Objective
+	grad           *multi.Gradient
+	componentEvals *common.ComponentEvaluations
+
+	// User defined function
+	fun *finiteSumFun
+
+	// Optimization model
+	optimizer FiniteSumOptimizer
+
+	// Settings
+	settings *FiniteSumSettings
+}
+
+func newFiniteSumStruct() *finiteSumStruct {
+	return &finiteSumStruct{
+		OptCommon:      common.NewOptCommon(),
+		loc:            multi.NewLocation(),
+		obj:            uni.NewObjective(),
+		grad:           multi.NewGradient(),
+		componentEvals: common.NewComponentEvaluations(),
+	}
+}
+
+func (m *finiteSumStruct) CommonSettings() *common.CommonSettings {
+	return m.settings.CommonSettings
+}
+
+func (m *finiteSumStruct) SetSettings() error {
+	m.obj.SetSettings(m.settings.ObjectiveSettings)
+	m.grad.SetSettings(m.settings.GradientSettings)
+	m.loc.SetSettings(m.settings.LocationSettings)
+	m.componentEvals.SetSettings(m.settings.ComponentSettings)
+	return nil
+}
+
+func (m *finiteSumStruct) Status() status.Status {
+	return status.CheckStatus(m.obj, m.grad, m.componentEvals)
+}
+
+func (m *finiteSumStruct) AddToDisplay(d []*display.Struct) []*display.Struct {
+	return display.AddToDisplay(d, m.componentEvals, m.loc, m.obj, m.grad)
+}
+
+func (m *finiteSumStruct) Result() *FiniteSumResult {
+	return &FiniteSumResult{
+		CommonResult:         m.OptCommon.CommonResult(),
+		ObjectiveResult:      m.obj.Result(),
+		GradientResult:       m.grad.Result(),
+		LocationResult:       m.loc.Result(),
+		ComponentEvaluations: m.componentEvals.Opt(),
+	}
+}
+
+func (m *finiteSumStruct) SetResult() {
+	optimize.SetResult(m.loc, m.grad, m.obj, m.componentEvals)
+
+	setResulter, ok := m.optimizer.(optimize.SetResulter)
+	if ok {
+		setResulter.SetResult()
+	}
+}
+
+func (m *finiteSumStruct) Initialize() error {
+	nComponents := m.fun.NumComponents()
+	if nComponents <= 0 {
+		return errors.New("stochastic: function has no components")
+	}
+	m.componentEvals.Initialize()
+
+	initLoc := m.loc.Init()
+	initObj := m.obj.Init()
+	initGrad := m.grad.Init()
+
+	// The initial values need to both be NaN or both not nan
+	if math.IsNaN(initObj) {
+		if len(initGrad) != 0 {
+			return errors.New("initial function value and gradient must either both be set or neither set")
+		}
+		// Both nan, so compute the initial fuction value and gradient
+		initObj, initGrad, err := m.fun.ObjGrad(initLoc)
+		if err != nil {
+			return errors.New("error calling function during optimization: \n" + err.Error())
+		}
+		m.obj.SetInit(initObj)
+		m.grad.SetInit(initGrad)
+	} else {
+		if len(initGrad) == 0 {
+			return errors.New("initial function value and gradient must either both be set or neither set")
+		}
+	}
+
+	err := optimize.Initialize(m.loc, m.obj, m.grad)
+	if err != nil {
+		return err
+	}
+	return m.optimizer.Initialize(m.loc, m.obj, m.grad, nComponents)
+}
+
+func (m *finiteSumStruct) Iterate() (status.Status, error) {
+	return m.optimizer.Iterate(m.loc, m.obj, m.grad, m.fun)
+}
@@ -0,0 +1,261 @@
+// Package stochastic contains optimizers for functions defined over a set
+// of samples (or components of a finite sum) that take steps using only a
+// subset of the samples at a time
+package stochastic
+
+import (
+	"github.com/btracey/gofunopter/common"
+	"github.com/btracey/gofunopter/common/display"
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// batchFun is the user defined function restricted to the current minibatch
+type batchFun struct {
+	fun      optimize.MultiBatchObjGrad
+	batch    []int
+	loc      *multi.Location
+	obj      *uni.Objective
+	grad     *multi.Gradient
+	funEvals *common.FunctionEvaluations
+}
+
+func (b *batchFun) ObjGrad(x []float64) (obj float64, grad []float64, err error) {
+	obj, grad, err = b.fun.BatchObjGrad(x, b.batch)
+	b.loc.AddToHist(x)
+	b.obj.AddToHist(obj)
+	b.grad.AddToHist(grad)
+	b.funEvals.Add(1)
+	return
+}
+
+// StochasticOptimizer is a method which takes a step using the current
+// gradient, which is that of the previous minibatch, and then evaluates the
+// function restricted to the next minibatch at the new location. The
+// minibatch changes between every call to Iterate. The objective value and
+// gradient set by the optimizer are those of the minibatch at the current
+// location
+type StochasticOptimizer interface {
+	Initialize(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient) error
+	Iterate(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient, fun optimize.MultiObjGrad) (status.Status, error)
+}
+
+func OptimizeGrad(function optimize.MultiBatchObjGrad, initialLocation []float64, settings *StochasticSettings, optimizer StochasticOptimizer) (optValue float64, optLocation []float64, result *StochasticResult, err error) {
+
+	if settings == nil {
+		settings = NewStochasticSettings()
+	}
+
+	if optimizer == nil {
+		optimizer = NewAdam()
+	}
+
+	m := newStochasticStruct()
+	m.fun = &batchFun{
+		fun:      function,
+		loc:      m.loc,
+		obj:      m.obj,
+		grad:     m.grad,
+		funEvals: m.FunEvals,
+	}
+	m.settings = settings
+	m.optimizer = optimizer
+
+	m.loc.SetInit(initialLocation)
+	err = optimize.OptimizeOpter(m, function)
+
+	return m.obj.Opt(), m.loc.Opt(), m.Result(), err
+}
+
+type StochasticResult struct {
+	*common.CommonResult
+	*uni.ObjectiveResult
+	*multi.GradientResult
+	*multi.LocationResult
+	Epochs int // Total number of passes through the data
+}
+
+type StochasticSettings struct {
+	*common.CommonSettings
+	*common.EpochSettings
+	*uni.ObjectiveSettings
+	*multi.GradientSettings
+	*multi.LocationSettings
+}
+
+// NewStochasticSettings returns the default settings. The minibatch
+// gradient is noisy, so the default gradient tolerance is zero and
+// the optimization stops after MaximumEpochs
+func NewStochasticSettings() *StochasticSettings {
+	s := &StochasticSettings{
+		CommonSettings:    common.NewCommonSettings(),
+		EpochSettings:     common.NewEpochSettings(),
+		ObjectiveSettings: uni.NewObjectiveSettings(),
+		GradientSettings:  multi.NewGradientSettings(),
+		LocationSettings:  multi.NewLocationSettings(),
+	}
+	s.GradientAbsoluteTolerance = 0
+	return s
+}
+
+type stochasticStruct struct {
+	*common.OptCommon
+
+	loc    *multi.Location
+	obj    *uni.Objective
+	grad   *multi.Gradient
+	epochs *common.Epochs
+
+	// User defined function
+	fun *batchFun
+
+	// Sample ordering
+	rng   *rand.Rand
+	order []int
+	next  int
+
+	// Optimization model
+	optimizer StochasticOptimizer
+
+	// Settings
+	settings *StochasticSettings
+}
+
+func newStochasticStruct() *stochasticStruct {
+	return &stochasticStruct{
+		OptCommon: common.NewOptCommon(),
+		loc:       multi.NewLocation(),
+		obj:       uni.NewObjective(),
+		grad:      multi.NewGradient(),
+		epochs:    common.NewEpochs(),
+	}
+}
+
+func (m *stochasticStruct) CommonSettings() *common.CommonSettings {
+	return m.settings.CommonSettings
+}
+
+func (m *stochasticStruct) SetSettings() error {
+	m.obj.SetSettings(m.settings.ObjectiveSettings)
+	m.grad.SetSettings(m.settings.GradientSettings)
+	m.loc.SetSettings(m.settings.LocationSettings)
+	m.epochs.SetSettings(m.settings.EpochSettings)
+	return nil
+}
+
+func (m *stochasticStruct) Status() status.Status {
+	return status.CheckStatus(m.obj, m.grad, m.epochs)
+}
+
+func (m *stochasticStruct) AddToDisplay(d []*display.Struct) []*display.Struct {
+	return display.AddToDisplay(d, m.epochs, m.loc, m.obj, m.grad)
+}
+
+func (m *stochasticStruct) Result() *StochasticResult {
+	return &StochasticResult{
+		CommonResult:    m.OptCommon.CommonResult(),
+		ObjectiveResult: m.obj.Result(),
+		GradientResult:  m.grad.Result(),
+		LocationResult:  m.loc.Result(),
+		Epochs:          m.epochs.Opt(),
+	}
+}
+
+func (m *stochasticStruct) SetResult() {
+	optimize.SetResult(m.loc, m.grad, m.obj, m.epochs)
+
+	setResulter, ok := m.optimizer.(optimize.SetResulter)
+	if ok {
+		setResulter.SetResult()
+	}
+}
+
+// nextBatch sets the indices of the next minibatch, starting a new
+// epoch if all of the samples have been used
+func (m *stochasticStruct) nextBatch() {
+	batchSize := m.settings.BatchSize
+	if batchSize > len(m.order) {
+		batchSize = len(m.order)
+	}
+	if m.next+batchSize > len(m.order) {
+		m.epochs.Add(1)
+		m.next = 0
+		if m.settings.Shuffle {
+			m.shuffle()
+		}
+	}
+	m.fun.batch = m.order[m.next : m.next+batchSize]
+	m.next += batchSize
+}
+
+func (m *stochasticStruct) shuffle() {
+	for i := len(m.order) - 1; i > 0; i-- {
+		j := m.rng.Intn(i + 1)
+		m.order[i], m.order[j] = m.order[j], m.order[i]
+	}
+}
+
+func (m *stochasticStruct) Initialize() error {
+	nSamples := m.fun.fun.NumSamples()
+	if nSamples <= 0 {
+		return errors.New("stochastic: function has no samples")
+	}
+	if m.settings.BatchSize <= 0 {
+		return errors.New("stochastic: batch size must be positive")
+	}
+	m.rng = rand.New(rand.NewSource(m.settings.Seed))
+	m.order = make([]int, nSamples)
+	for i := range m.order {
+		m.order[i] = i
+	}
+	if m.settings.Shuffle {
+		m.shuffle()
+	}
+	m.next = 0
+	m.epochs.Initialize()
+
+	initLoc := m.loc.Init()
+	initObj := m.obj.Init()
+	initGrad := m.grad.Init()
+
+	// The initial values need to both be NaN or both not nan
+	if math.IsNaN(initObj) {
+		if len(initGrad) != 0 {
+			return errors.New("initial function value and gradient must either both be set or neither set")
+		}
+		// Both nan, so compute the initial fuction value and gradient on the
+		// first batch. The first step is taken with this gradient
+		m.nextBatch()
+		initObj, initGrad, err := m.fun.ObjGrad(initLoc)
+		if err != nil {
+			return errors.New("error calling function during optimization: \n" + err.Error())
+		}
+		m.obj.SetInit(initObj)
+		m.grad.SetInit(initGrad)
+	} else {
+		if len(initGrad) == 0 {
+			return errors.New("initial function value and gradient must either both be set or neither set")
+		}
+	}
+
+	err := optimize.Initialize(m.loc, m.obj, m.grad)
+	if err != nil {
+		return err
+	}
+	return m.optimizer.Initialize(m.loc, m.obj, m.grad)
+}
+
+func (m *stochasticStruct) Iterate() (status.Status, error) {
+	// The epoch limit is checked before moving on to the next batch
+	if c := m.epochs.Status(); c != status.Continue {
+		return c, nil
+	}
+	m.nextBatch()
+	return m.optimizer.Iterate(m.loc, m.obj, m.grad, m.fun)
+}
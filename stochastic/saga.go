@@ -0,0 +1,108 @@
+package stochastic
+
+import (
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+	"math/rand"
+)
+
+// Saga is the incremental gradient method of Defazio, Bach and
+// Lacoste-Julien (2014). It stores the most recent gradient of every
+// component, and steps using n * (g_i(x) - stored_i) + sum_j stored_j.
+// Each iteration takes one step per component (the stored gradients are
+// filled by a pass through all of the components during the first
+// iteration), after which the full sum is evaluated at the new location.
+// If the maximum number of component evaluations is reached, the remaining
+// steps of the iteration are skipped
+type Saga struct {
+	// Tunable parameters
+	StepSize float64
+	Seed     int64
+
+	// Other needed variables
+	rng         *rand.Rand
+	nComponents int
+	filled      bool
+	table       [][]float64
+	sum         []float64
+	x           []float64
+}
+
+func NewSaga() *Saga {
+	return &Saga{
+		StepSize: 1E-2,
+		Seed:     1,
+	}
+}
+
+func (s *Saga) Initialize(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient, nComponents int) error {
+	if s.StepSize <= 0 {
+		return errors.New("saga: step size must be positive")
+	}
+	nDim := len(loc.Init())
+	s.rng = rand.New(rand.NewSource(s.Seed))
+	s.nComponents = nComponents
+	s.filled = false
+	s.table = make([][]float64, nComponents)
+	for i := range s.table {
+		s.table[i] = make([]float64, nDim)
+	}
+	s.sum = make([]float64, nDim)
+	s.x = make([]float64, nDim)
+	return nil
+}
+
+func (s *Saga) Iterate(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient, fun FiniteSumFunction) (status.Status, error) {
+	copy(s.x, loc.Curr())
+	if !s.filled {
+		for i := range s.table {
+			_, g, err := componentGrad(fun, i, s.x)
+			if err == errMaximumComponentEvaluations {
+				// No step has been taken, so the location is unchanged
+				return status.MaximumComponentEvaluations, nil
+			}
+			if err != nil {
+				return status.UserFunctionError, err
+			}
+			copy(s.table[i], g)
+			for j, val := range g {
+				s.sum[j] += val
+			}
+		}
+		s.filled = true
+	}
+
+	c := status.Continue
+	n := float64(s.nComponents)
+	for k := 0; k < s.nComponents; k++ {
+		i := s.rng.Intn(s.nComponents)
+		_, g, err := componentGrad(fun, i, s.x)
+		if err == errMaximumComponentEvaluations {
+			c = status.MaximumComponentEvaluations
+			break
+		}
+		if err != nil {
+			return status.UserFunctionError, err
+		}
+		stored := s.table[i]
+		for j := range s.x {
+			s.x[j] -= s.StepSize * (n*(g[j]-stored[j]) + s.sum[j])
+		}
+		for j, val := range g {
+			s.sum[j] += val - stored[j]
+			stored[j] = val
+		}
+	}
+
+	f, g, err := evaluate(fun, s.x)
+	if err != nil {
+		return status.UserFunctionError, err
+	}
+	loc.SetCurr(s.x)
+	obj.SetCurr(f)
+	grad.SetCurr(g)
+	return c, nil
+}
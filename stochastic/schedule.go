@@ -0,0 +1,54 @@
+package stochastic
+
+import (
+	"math"
+)
+
+// A Schedule gives the learning rate (step size) as a function of the
+// number of iterations taken
+type Schedule interface {
+	LearningRate(iter int) float64
+}
+
+// Constant is a fixed learning rate
+type Constant struct {
+	Rate float64
+}
+
+func (c Constant) LearningRate(iter int) float64 {
+	return c.Rate
+}
+
+// InverseTime decays the learning rate as Rate / (1 + Decay * iter)
+type InverseTime struct {
+	Rate  float64
+	Decay float64
+}
+
+func (it InverseTime) LearningRate(iter int) float64 {
+	return it.Rate / (1 + it.Decay*float64(iter))
+}
+
+// Exponential decays the learning rate as Rate * Decay^iter
+type Exponential struct {
+	Rate  float64
+	Decay float64
+}
+
+func (e Exponential) LearningRate(iter int) float64 {
+	return e.Rate * math.Pow(e.Decay, float64(iter))
+}
+
+// Step multiplies the learning rate by Factor every Interval iterations
+type Step struct {
+	Rate     float64
+	Factor   float64
+	Interval int
+}
+
+func (s Step) LearningRate(iter int) float64 {
+	if s.Interval <= 0 {
+		return s.Rate
+	}
+	return s.Rate * math.Pow(s.Factor, float64(iter/s.Interval))
+}
@@ -0,0 +1,101 @@
+package stochastic
+
+import (
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+)
+
+// Sgd is stochastic gradient descent with optional (heavy ball or
+// Nesterov) momentum. The update is v = Momentum * v - rate * g followed
+// by x = x + v, where g is the minibatch gradient at x. If Nesterov is true,
+// the location is the lookahead point x + Momentum * v, so that g is the
+// gradient at the location, and the update is
+// x = x + Momentum * v - rate * g with the new v
+type Sgd struct {
+	// Tunable parameters
+	Schedule Schedule
+	Momentum float64
+	Nesterov bool
+
+	// Other needed variables
+	iter     int
+	velocity []float64
+	x        []float64
+}
+
+// NewSgd returns plain stochastic gradient descent with a
+// constant learning rate
+func NewSgd() *Sgd {
+	return &Sgd{
+		Schedule: Constant{Rate: 0.01},
+	}
+}
+
+// NewNesterov returns stochastic gradient descent with
+// Nesterov momentum
+func NewNesterov() *Sgd {
+	return &Sgd{
+		Schedule: Constant{Rate: 0.01},
+		Momentum: 0.9,
+		Nesterov: true,
+	}
+}
+
+func (s *Sgd) Initialize(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient) error {
+	if s.Schedule == nil {
+		return errors.New("sgd: schedule is nil")
+	}
+	if s.Momentum < 0 || s.Momentum >= 1 {
+		return errors.New("sgd: momentum must be in [0,1)")
+	}
+	nDim := len(loc.Init())
+	s.iter = 0
+	s.velocity = make([]float64, nDim)
+	s.x = make([]float64, nDim)
+	return nil
+}
+
+func (s *Sgd) Iterate(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient, fun optimize.MultiObjGrad) (status.Status, error) {
+	g := grad.Curr()
+	rate := s.Schedule.LearningRate(s.iter)
+	copy(s.x, loc.Curr())
+	for i := range s.x {
+		s.velocity[i] = s.Momentum*s.velocity[i] - rate*g[i]
+		if s.Nesterov {
+			s.x[i] += s.Momentum*s.velocity[i] - rate*g[i]
+		} else {
+			s.x[i] += s.velocity[i]
+		}
+	}
+	s.iter++
+	return moveTo(s.x, loc, obj, grad, fun)
+}
+
+// evaluate calls the function, checking the gradient length
+func evaluate(fun optimize.MultiObjGrad, x []float64) (float64, []float64, error) {
+	f, g, err := fun.ObjGrad(x)
+	if err != nil {
+		return f, g, errors.New("stochastic: user defined function error: " + err.Error())
+	}
+	if len(g) != len(x) {
+		return f, g, errors.New("stochastic: user defined function returned incorrect gradient length")
+	}
+	return f, g, nil
+}
+
+// moveTo evaluates the function at x and sets it as the current location,
+// so the location, objective and gradient always describe the same point
+func moveTo(x []float64, loc *multi.Location, obj *uni.Objective, grad *multi.Gradient, fun optimize.MultiObjGrad) (status.Status, error) {
+	f, g, err := evaluate(fun, x)
+	if err != nil {
+		return status.UserFunctionError, err
+	}
+	loc.SetCurr(x)
+	obj.SetCurr(f)
+	grad.SetCurr(g)
+	return status.Continue, nil
+}
@@ -0,0 +1,235 @@
+package stochastic
+
+import (
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/gonum/floats"
+
+	"math"
+	"math/rand"
+	"testing"
+)
+
+const STOCHASTIC_TOLERANCE = 1E-3
+
+// linearRegression is the mean squared error of a noise-free linear model
+type linearRegression struct {
+	features [][]float64
+	targets  []float64
+}
+
+func newLinearRegression(weights []float64, nSamples int) *linearRegression {
+	rnd := rand.New(rand.NewSource(1))
+	l := &linearRegression{
+		features: make([][]float64, nSamples),
+		targets:  make([]float64, nSamples),
+	}
+	for i := range l.features {
+		l.features[i] = make([]float64, len(weights))
+		for j := range weights {
+			l.features[i][j] = 2*rnd.Float64() - 1
+		}
+		l.targets[i] = floats.Dot(weights, l.features[i])
+	}
+	return l
+}
+
+func (l *linearRegression) NumSamples() int {
+	return len(l.targets)
+}
+
+func (l *linearRegression) BatchObjGrad(x []float64, batch []int) (obj float64, grad []float64, err error) {
+	grad = make([]float64, len(x))
+	for _, idx := range batch {
+		resid := floats.Dot(x, l.features[idx]) - l.targets[idx]
+		obj += resid * resid / 2
+		for j, val := range l.features[idx] {
+			grad[j] += resid * val
+		}
+	}
+	n := float64(len(batch))
+	floats.Scale(1/n, grad)
+	return obj / n, grad, nil
+}
+
+func TestStochastic(t *testing.T) {
+	weights := []float64{1, -2, 0.5}
+	fun := newLinearRegression(weights, 200)
+
+	sgd := NewSgd()
+	sgd.Schedule = Constant{Rate: 0.2}
+	nesterov := NewNesterov()
+	nesterov.Schedule = Constant{Rate: 0.05}
+	adagrad := NewAdagrad()
+	adagrad.Schedule = Constant{Rate: 0.5}
+	rmsprop := NewRmsprop()
+	rmsprop.Schedule = Exponential{Rate: 0.01, Decay: 0.999}
+	adam := NewAdam()
+	adam.Schedule = InverseTime{Rate: 0.05, Decay: 0.01}
+
+	for _, test := range []struct {
+		name  string
+		opter StochasticOptimizer
+	}{
+		{"Sgd", sgd},
+		{"Nesterov", nesterov},
+		{"Adagrad", adagrad},
+		{"Rmsprop", rmsprop},
+		{"Adam", adam},
+	} {
+		settings := NewStochasticSettings()
+		settings.Display = false
+		settings.BatchSize = 10
+		settings.MaximumEpochs = 200
+		_, loc, result, err := OptimizeGrad(fun, []float64{0, 0, 0}, settings, test.opter)
+		if err != nil {
+			t.Errorf("%v: error during optimization: %v", test.name, err)
+			continue
+		}
+		if result.Status != status.MaximumEpochs {
+			t.Errorf("%v: status is not MaximumEpochs. %v found", test.name, result.Status)
+		}
+		if result.Epochs != settings.MaximumEpochs {
+			t.Errorf("%v: %v epochs found, %v expected", test.name, result.Epochs, settings.MaximumEpochs)
+		}
+		// Every batch, including the one of the initial evaluation, is used
+		// for exactly one step
+		if result.Iterations != settings.MaximumEpochs*fun.NumSamples()/settings.BatchSize {
+			t.Errorf("%v: %v iterations for %v epochs", test.name, result.Iterations, result.Epochs)
+		}
+		if result.FunctionEvaluations != result.Iterations+1 {
+			t.Errorf("%v: %v evaluations for %v iterations", test.name, result.FunctionEvaluations, result.Iterations)
+		}
+		if !floats.EqualApprox(loc, weights, STOCHASTIC_TOLERANCE) {
+			t.Errorf("%v: optimum location not found. %v found, %v expected", test.name, loc, weights)
+		}
+
+		// Run it again to test that the reset works fine
+		_, loc2, result2, err := OptimizeGrad(fun, []float64{0, 0, 0}, settings, test.opter)
+		if err != nil {
+			t.Errorf("%v: error while re-using optimizer: %v", test.name, err)
+			continue
+		}
+		if result2.FunctionEvaluations != result.FunctionEvaluations || !floats.Equal(loc, loc2) {
+			t.Errorf("%v: different result the second time", test.name)
+		}
+	}
+}
+
+// TestStochasticFullBatch tests that the returned location, objective and
+// gradient describe the same point when the batch is the whole data set
+func TestStochasticFullBatch(t *testing.T) {
+	fun := newLinearRegression([]float64{1, -2, 0.5}, 20)
+	all := make([]int, fun.NumSamples())
+	for i := range all {
+		all[i] = i
+	}
+	for _, test := range []struct {
+		name  string
+		opter StochasticOptimizer
+	}{
+		{"Sgd", NewSgd()},
+		{"Nesterov", NewNesterov()},
+		{"Adagrad", NewAdagrad()},
+		{"Rmsprop", NewRmsprop()},
+		{"Adam", NewAdam()},
+	} {
+		settings := NewStochasticSettings()
+		settings.Display = false
+		settings.BatchSize = fun.NumSamples()
+		settings.MaximumEpochs = 5
+		settings.KeepObjectiveHistory = true
+		val, loc, result, err := OptimizeGrad(fun, []float64{0, 0, 0}, settings, test.opter)
+		if err != nil {
+			t.Errorf("%v: error during optimization: %v", test.name, err)
+			continue
+		}
+		f, g, _ := fun.BatchObjGrad(loc, all)
+		if math.Abs(f-val) > 1E-14 {
+			t.Errorf("%v: objective %v returned, %v at the location", test.name, val, f)
+		}
+		if !floats.EqualApprox(g, result.Gradient, 1E-14) {
+			t.Errorf("%v: gradient %v returned, %v at the location", test.name, result.Gradient, g)
+		}
+		if len(result.ObjectiveHistory) != result.Iterations {
+			t.Errorf("%v: objective history not kept", test.name)
+		}
+	}
+}
+
+func (l *linearRegression) NumComponents() int {
+	return len(l.targets)
+}
+
+func (l *linearRegression) ComponentObjGrad(i int, x []float64) (obj float64, grad []float64, err error) {
+	return l.BatchObjGrad(x, []int{i})
+}
+
+func TestFiniteSum(t *testing.T) {
+	weights := []float64{1, -2, 0.5}
+	fun := newLinearRegression(weights, 50)
+
+	svrg := NewSvrg()
+	svrg.StepSize = 2E-3
+	saga := NewSaga()
+	saga.StepSize = 2E-3
+
+	for _, test := range []struct {
+		name  string
+		opter FiniteSumOptimizer
+	}{
+		{"Svrg", svrg},
+		{"Saga", saga},
+	} {
+		settings := NewFiniteSumSettings()
+		settings.Display = false
+		settings.GradientAbsoluteTolerance = 1E-8
+		settings.MaximumIterations = 1000
+		_, loc, result, err := OptimizeFiniteSum(fun, []float64{0, 0, 0}, settings, test.opter)
+		if err != nil {
+			t.Errorf("%v: error during optimization: %v", test.name, err)
+			continue
+		}
+		if result.Status != status.GradAbsTol {
+			t.Errorf("%v: status is not GradAbsTol. %v found", test.name, result.Status)
+		}
+		if !floats.EqualApprox(loc, weights, STOCHASTIC_TOLERANCE) {
+			t.Errorf("%v: optimum location not found. %v found, %v expected", test.name, loc, weights)
+		}
+		if result.FunctionEvaluations != result.Iterations+1 {
+			t.Errorf("%v: %v full evaluations for %v iterations", test.name, result.FunctionEvaluations, result.Iterations)
+		}
+		if result.ComponentEvaluations == 0 {
+			t.Errorf("%v: component evaluations not counted", test.name)
+		}
+	}
+}
+
+func TestFiniteSumComponentEvaluations(t *testing.T) {
+	fun := newLinearRegression([]float64{1, -2, 0.5}, 50)
+	for _, test := range []struct {
+		name  string
+		opter FiniteSumOptimizer
+	}{
+		{"Svrg", NewSvrg()},
+		{"Saga", NewSaga()},
+	} {
+		// Both the first pass of Saga and a partial iteration of Svrg
+		// run out of component evaluations
+		for _, maxEvals := range []int{30, 130} {
+			settings := NewFiniteSumSettings()
+			settings.Display = false
+			settings.MaximumComponentEvaluations = maxEvals
+			_, _, result, err := OptimizeFiniteSum(fun, []float64{0, 0, 0}, settings, test.opter)
+			if err != nil {
+				t.Errorf("%v: error during optimization: %v", test.name, err)
+				continue
+			}
+			if result.Status != status.MaximumComponentEvaluations {
+				t.Errorf("%v: status is not MaximumComponentEvaluations. %v found", test.name, result.Status)
+			}
+			if result.ComponentEvaluations > maxEvals {
+				t.Errorf("%v: %v component evaluations with a maximum of %v", test.name, result.ComponentEvaluations, maxEvals)
+			}
+		}
+	}
+}
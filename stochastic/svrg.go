@@ -0,0 +1,113 @@
+package stochastic
+
+import (
+	"github.com/btracey/gofunopter/common/multi"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+	"math/rand"
+)
+
+// Svrg is the stochastic variance reduced gradient method of Johnson
+// and Zhang (2013). Each iteration takes InnerIterations steps using the
+// variance reduced gradient n * (g_i(x) - g_i(xs)) + g(xs), where xs is the
+// location at the start of the iteration and g(xs) is the full gradient
+// there. The full sum is then evaluated at the new location. If the
+// maximum number of component evaluations is reached, the remaining steps
+// of the iteration are skipped
+type Svrg struct {
+	// Tunable parameters
+	StepSize        float64
+	InnerIterations int // Number of component steps per iteration (if zero, twice the number of components)
+	Seed            int64
+
+	// Other needed variables
+	rng         *rand.Rand
+	nComponents int
+	snapshot    []float64
+	fullGrad    []float64
+	x           []float64
+	v           []float64
+}
+
+func NewSvrg() *Svrg {
+	return &Svrg{
+		StepSize: 1E-2,
+		Seed:     1,
+	}
+}
+
+func (s *Svrg) Initialize(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient, nComponents int) error {
+	if s.StepSize <= 0 {
+		return errors.New("svrg: step size must be positive")
+	}
+	nDim := len(loc.Init())
+	s.rng = rand.New(rand.NewSource(s.Seed))
+	s.nComponents = nComponents
+	s.snapshot = make([]float64, nDim)
+	s.fullGrad = make([]float64, nDim)
+	s.x = make([]float64, nDim)
+	s.v = make([]float64, nDim)
+	return nil
+}
+
+func (s *Svrg) Iterate(loc *multi.Location, obj *uni.Objective, grad *multi.Gradient, fun FiniteSumFunction) (status.Status, error) {
+	copy(s.snapshot, loc.Curr())
+	copy(s.fullGrad, grad.Curr())
+	copy(s.x, s.snapshot)
+
+	inner := s.InnerIterations
+	if inner <= 0 {
+		inner = 2 * s.nComponents
+	}
+	c := status.Continue
+	n := float64(s.nComponents)
+	for k := 0; k < inner; k++ {
+		i := s.rng.Intn(s.nComponents)
+		_, g, err := componentGrad(fun, i, s.x)
+		if err == errMaximumComponentEvaluations {
+			c = status.MaximumComponentEvaluations
+			break
+		}
+		if err != nil {
+			return status.UserFunctionError, err
+		}
+		copy(s.v, g)
+		_, gSnap, err := componentGrad(fun, i, s.snapshot)
+		if err == errMaximumComponentEvaluations {
+			c = status.MaximumComponentEvaluations
+			break
+		}
+		if err != nil {
+			return status.UserFunctionError, err
+		}
+		for j := range s.x {
+			s.x[j] -= s.StepSize * (n*(s.v[j]-gSnap[j]) + s.fullGrad[j])
+		}
+	}
+
+	f, g, err := evaluate(fun, s.x)
+	if err != nil {
+		return status.UserFunctionError, err
+	}
+	loc.SetCurr(s.x)
+	obj.SetCurr(f)
+	grad.SetCurr(g)
+	return c, nil
+}
+
+// componentGrad calls the ith component, checking the gradient length
+func componentGrad(fun FiniteSumFunction, i int, x []float64) (float64, []float64, error) {
+	f, g, err := fun.ComponentObjGrad(i, x)
+	if err == errMaximumComponentEvaluations {
+		return f, g, err
+	}
+	if err != nil {
+		return f, g, errors.New("stochastic: user defined function error: " + err.Error())
+	}
+	if len(g) != len(x) {
+		return f, g, errors.New("stochastic: user defined function returned incorrect gradient length")
+	}
+	return f, g, nil
+}
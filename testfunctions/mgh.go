@@ -0,0 +1,568 @@
+package testfunctions
+
+import (
+	"math"
+)
+
+// The functions in this file are sums of squares of residuals, and are
+// numbered as in Moré, Garbow and Hillstrom
+
+// ExtendedRosenbrock is function 21, the sum of Dim/2 uncoupled Rosenbrock
+// functions of consecutive pairs of variables. Dim must be even, and
+// Dim = 2 is function 1, the Rosenbrock function
+type ExtendedRosenbrock struct {
+	Dim int
+}
+
+func (e ExtendedRosenbrock) ObjGrad(x []float64) (obj float64, grad []float64, err error) {
+	if err := checkDim(x, e.Dim, 2); err != nil {
+		return math.NaN(), nil, err
+	}
+	n := len(x)
+	r := make([]float64, n)
+	jac := newJacobian(n, n)
+	for i := 0; i < n; i += 2 {
+		r[i] = 10 * (x[i+1] - x[i]*x[i])
+		jac[i][i] = -20 * x[i]
+		jac[i][i+1] = 10
+		r[i+1] = 1 - x[i]
+		jac[i+1][i] = -1
+	}
+	grad = make([]float64, n)
+	return leastSquares(r, jac, grad), grad, nil
+}
+
+func (e ExtendedRosenbrock) Objective(x []float64) (obj float64, err error) {
+	obj, _, err = e.ObjGrad(x)
+	return obj, err
+}
+
+func (e ExtendedRosenbrock) Name() string {
+	return dimName("ExtendedRosenbrock", e.Dim)
+}
+
+func (e ExtendedRosenbrock) OptVal() float64 {
+	return 0
+}
+
+func (e ExtendedRosenbrock) OptLoc() []float64 {
+	return constant(e.Dim, 1)
+}
+
+func (e ExtendedRosenbrock) InitLoc() []float64 {
+	x := make([]float64, e.Dim)
+	for i := 0; i < len(x); i += 2 {
+		x[i] = -1.2
+		x[i+1] = 1
+	}
+	return x
+}
+
+// FreudensteinRoth is function 2. It also has a local minimum of value
+// 48.9842 at (11.41, -0.8968)
+type FreudensteinRoth struct{}
+
+func (FreudensteinRoth) ObjGrad(x []float64) (obj float64, grad []float64, err error) {
+	if err := checkDim(x, 2, 0); err != nil {
+		return math.NaN(), nil, err
+	}
+	r := []float64{
+		-13 + x[0] + ((5-x[1])*x[1]-2)*x[1],
+		-29 + x[0] + ((x[1]+1)*x[1]-14)*x[1],
+	}
+	jac := [][]float64{
+		{1, (10-3*x[1])*x[1] - 2},
+		{1, (3*x[1]+2)*x[1] - 14},
+	}
+	grad = make([]float64, 2)
+	return leastSquares(r, jac, grad), grad, nil
+}
+
+func (f FreudensteinRoth) Objective(x []float64) (obj float64, err error) {
+	obj, _, err = f.ObjGrad(x)
+	return obj, err
+}
+
+func (FreudensteinRoth) Name() string {
+	return "FreudensteinRoth"
+}
+
+func (FreudensteinRoth) OptVal() float64 {
+	return 0
+}
+
+func (FreudensteinRoth) OptLoc() []float64 {
+	return []float64{5, 4}
+}
+
+func (FreudensteinRoth) InitLoc() []float64 {
+	return []float64{0.5, -2}
+}
+
+// PowellBadlyScaled is function 3
+type PowellBadlyScaled struct{}
+
+func (PowellBadlyScaled) ObjGrad(x []float64) (obj float64, grad []float64, err error) {
+	if err := checkDim(x, 2, 0); err != nil {
+		return math.NaN(), nil, err
+	}
+	e0, e1 := math.Exp(-x[0]), math.Exp(-x[1])
+	r := []float64{
+		1E4*x[0]*x[1] - 1,
+		e0 + e1 - 1.0001,
+	}
+	jac := [][]float64{
+		{1E4 * x[1], 1E4 * x[0]},
+		{-e0, -e1},
+	}
+	grad = make([]float64, 2)
+	return leastSquares(r, jac, grad), grad, nil
+}
+
+func (p PowellBadlyScaled) Objective(x []float64) (obj float64, err error) {
+	obj, _, err = p.ObjGrad(x)
+	return obj, err
+}
+
+func (PowellBadlyScaled) Name() string {
+	return "PowellBadlyScaled"
+}
+
+func (PowellBadlyScaled) OptVal() float64 {
+	return 0
+}
+
+func (PowellBadlyScaled) OptLoc() []float64 {
+	return []float64{1.0981593296997559E-5, 9.106146739867036}
+}
+
+func (PowellBadlyScaled) InitLoc() []float64 {
+	return []float64{0, 1}
+}
+
+// BrownBadlyScaled is function 4
+type BrownBadlyScaled struct{}
+
+func (BrownBadlyScaled) ObjGrad(x []float64) (obj float64, grad []float64, err error) {
+	if err := checkDim(x, 2, 0); err != nil {
+		return math.NaN(), nil, err
+	}
+	r := []float64{
+		x[0] - 1E6,
+		x[1] - 2E-6,
+		x[0]*x[1] - 2,
+	}
+	jac := [][]float64{
+		{1, 0},
+		{0, 1},
+		{x[1], x[0]},
+	}
+	grad = make([]float64, 2)
+	return leastSquares(r, jac, grad), grad, nil
+}
+
+func (b BrownBadlyScaled) Objective(x []float64) (obj float64, err error) {
+	obj, _, err = b.ObjGrad(x)
+	return obj, err
+}
+
+func (BrownBadlyScaled) Name() string {
+	return "BrownBadlyScaled"
+}
+
+func (BrownBadlyScaled) OptVal() float64 {
+	return 0
+}
+
+func (BrownBadlyScaled) OptLoc() []float64 {
+	return []float64{1E6, 2E-6}
+}
+
+func (BrownBadlyScaled) InitLoc() []float64 {
+	return []float64{1, 1}
+}
+
+// Beale is function 5
+type Beale struct{}
+
+func (Beale) ObjGrad(x []float64) (obj float64, grad []float64, err error) {
+	if err := checkDim(x, 2, 0); err != nil {
+		return math.NaN(), nil, err
+	}
+	y := []float64{1.5, 2.25, 2.625}
+	r := make([]float64, 3)
+	jac := newJacobian(3, 2)
+	for i := range r {
+		p := float64(i + 1)
+		r[i] = y[i] - x[0]*(1-math.Pow(x[1], p))
+		jac[i][0] = math.Pow(x[1], p) - 1
+		jac[i][1] = x[0] * p * math.Pow(x[1], p-1)
+	}
+	grad = make([]float64, 2)
+	return leastSquares(r, jac, grad), grad, nil
+}
+
+func (b Beale) Objective(x []float64) (obj float64, err error) {
+	obj, _, err = b.ObjGrad(x)
+	return obj, err
+}
+
+func (Beale) Name() string {
+	return "Beale"
+}
+
+func (Beale) OptVal() float64 {
+	return 0
+}
+
+func (Beale) OptLoc() []float64 {
+	return []float64{3, 0.5}
+}
+
+func (Beale) InitLoc() []float64 {
+	return []float64{1, 1}
+}
+
+// HelicalValley is function 7
+type HelicalValley struct{}
+
+func (HelicalValley) ObjGrad(x []float64) (obj float64, grad []float64, err error) {
+	if err := checkDim(x, 3, 0); err != nil {
+		return math.NaN(), nil, err
+	}
+	theta := math.Atan(x[1]/x[0]) / (2 * math.Pi)
+	if x[0] < 0 {
+		theta += 0.5
+	}
+	rho2 := x[0]*x[0] + x[1]*x[1]
+	rho := math.Sqrt(rho2)
+	r := []float64{
+		10 * (x[2] - 10*theta),
+		10 * (rho - 1),
+		x[2],
+	}
+	jac := [][]float64{
+		{100 * x[1] / (2 * math.Pi * rho2), -100 * x[0] / (2 * math.Pi * rho2), 10},
+		{10 * x[0] / rho, 10 * x[1] / rho, 0},
+		{0, 0, 1},
+	}
+	grad = make([]float64, 3)
+	return leastSquares(r, jac, grad), grad, nil
+}
+
+func (h HelicalValley) Objective(x []float64) (obj float64, err error) {
+	obj, _, err = h.ObjGrad(x)
+	return obj, err
+}
+
+func (HelicalValley) Name() string {
+	return "HelicalValley"
+}
+
+func (HelicalValley) OptVal() float64 {
+	return 0
+}
+
+func (HelicalValley) OptLoc() []float64 {
+	return []float64{1, 0, 0}
+}
+
+func (HelicalValley) InitLoc() []float64 {
+	return []float64{-1, 0, 0}
+}
+
+// Box3D is function 12 with ten residuals. Every location with x0 = x1
+// and x2 = 0 is also a global minimum, as is (10, 1, -1)
+type Box3D struct{}
+
+func (Box3D) ObjGrad(x []float64) (obj float64, grad []float64, err error) {
+	if err := checkDim(x, 3, 0); err != nil {
+		return math.NaN(), nil, err
+	}
+	r := make([]float64, 10)
+	jac := newJacobian(10, 3)
+	for i := range r {
+		t := 0.1 * float64(i+1)
+		e0, e1 := math.Exp(-t*x[0]), math.Exp(-t*x[1])
+		c := math.Exp(-t) - math.Exp(-10*t)
+		r[i] = e0 - e1 - x[2]*c
+		jac[i][0] = -t * e0
+		jac[i][1] = t * e1
+		jac[i][2] = -c
+	}
+	grad = make([]float64, 3)
+	return leastSquares(r, jac, grad), grad, nil
+}
+
+func (b Box3D) Objective(x []float64) (obj float64, err error) {
+	obj, _, err = b.ObjGrad(x)
+	return obj, err
+}
+
+func (Box3D) Name() string {
+	return "Box3D"
+}
+
+func (Box3D) OptVal() float64 {
+	return 0
+}
+
+func (Box3D) OptLoc() []float64 {
+	return []float64{1, 10, 1}
+}
+
+func (Box3D) InitLoc() []float64 {
+	return []float64{0, 10, 20}
+}
+
+// powellSingular sets the residuals and Jacobian of the Powell singular
+// function of x[i:i+4]
+func powellSingular(x, r []float64, jac [][]float64, i int) {
+	s5, s10 := math.Sqrt(5), math.Sqrt(10)
+	a := x[i+1] - 2*x[i+2]
+	b := x[i] - x[i+3]
+	r[i] = x[i] + 10*x[i+1]
+	jac[i][i] = 1
+	jac[i][i+1] = 10
+	r[i+1] = s5 * (x[i+2] - x[i+3])
+	jac[i+1][i+2] = s5
+	jac[i+1][i+3] = -s5
+	r[i+2] = a * a
+	jac[i+2][i+1] = 2 * a
+	jac[i+2][i+2] = -4 * a
+	r[i+3] = s10 * b * b
+	jac[i+3][i] = 2 * s10 * b
+	jac[i+3][i+3] = -2 * s10 * b
+}
+
+// PowellSingular is function 13. Its Hessian is singular at the minimum
+type PowellSingular struct{}
+
+func (PowellSingular) ObjGrad(x []float64) (obj float64, grad []float64, err error) {
+	if err := checkDim(x, 4, 0); err != nil {
+		return math.NaN(), nil, err
+	}
+	r := make([]float64, 4)
+	jac := newJacobian(4, 4)
+	powellSingular(x, r, jac, 0)
+	grad = make([]float64, 4)
+	return leastSquares(r, jac, grad), grad, nil
+}
+
+func (p PowellSingular) Objective(x []float64) (obj float64, err error) {
+	obj, _, err = p.ObjGrad(x)
+	return obj, err
+}
+
+func (PowellSingular) Name() string {
+	return "PowellSingular"
+}
+
+func (PowellSingular) OptVal() float64 {
+	return 0
+}
+
+func (PowellSingular) OptLoc() []float64 {
+	return make([]float64, 4)
+}
+
+func (PowellSingular) InitLoc() []float64 {
+	return []float64{3, -1, 0, 1}
+}
+
+// ExtendedPowellSingular is function 22, the sum of Dim/4 uncoupled
+// PowellSingular functions. Dim must be a multiple of 4
+type ExtendedPowellSingular struct {
+	Dim int
+}
+
+func (e ExtendedPowellSingular) ObjGrad(x []float64) (obj float64, grad []float64, err error) {
+	if err := checkDim(x, e.Dim, 4); err != nil {
+		return math.NaN(), nil, err
+	}
+	n := len(x)
+	r := make([]float64, n)
+	jac := newJacobian(n, n)
+	for i := 0; i < n; i += 4 {
+		powellSingular(x, r, jac, i)
+	}
+	grad = make([]float64, n)
+	return leastSquares(r, jac, grad), grad, nil
+}
+
+func (e ExtendedPowellSingular) Objective(x []float64) (obj float64, err error) {
+	obj, _, err = e.ObjGrad(x)
+	return obj, err
+}
+
+func (e ExtendedPowellSingular) Name() string {
+	return dimName("ExtendedPowellSingular", e.Dim)
+}
+
+func (e ExtendedPowellSingular) OptVal() float64 {
+	return 0
+}
+
+func (e ExtendedPowellSingular) OptLoc() []float64 {
+	return make([]float64, e.Dim)
+}
+
+func (e ExtendedPowellSingular) InitLoc() []float64 {
+	x := make([]float64, e.Dim)
+	for i := 0; i < len(x); i += 4 {
+		copy(x[i:], []float64{3, -1, 0, 1})
+	}
+	return x
+}
+
+// Wood is function 14
+type Wood struct{}
+
+func (Wood) ObjGrad(x []float64) (obj float64, grad []float64, err error) {
+	if err := checkDim(x, 4, 0); err != nil {
+		return math.NaN(), nil, err
+	}
+	s90, s10 := math.Sqrt(90), math.Sqrt(10)
+	r := []float64{
+		10 * (x[1] - x[0]*x[0]),
+		1 - x[0],
+		s90 * (x[3] - x[2]*x[2]),
+		1 - x[2],
+		s10 * (x[1] + x[3] - 2),
+		(x[1] - x[3]) / s10,
+	}
+	jac := [][]float64{
+		{-20 * x[0], 10, 0, 0},
+		{-1, 0, 0, 0},
+		{0, 0, -2 * s90 * x[2], s90},
+		{0, 0, -1, 0},
+		{0, s10, 0, s10},
+		{0, 1 / s10, 0, -1 / s10},
+	}
+	grad = make([]float64, 4)
+	return leastSquares(r, jac, grad), grad, nil
+}
+
+func (w Wood) Objective(x []float64) (obj float64, err error) {
+	obj, _, err = w.ObjGrad(x)
+	return obj, err
+}
+
+func (Wood) Name() string {
+	return "Wood"
+}
+
+func (Wood) OptVal() float64 {
+	return 0
+}
+
+func (Wood) OptLoc() []float64 {
+	return []float64{1, 1, 1, 1}
+}
+
+func (Wood) InitLoc() []float64 {
+	return []float64{-3, -1, -3, -1}
+}
+
+// VariablyDimensioned is function 25
+type VariablyDimensioned struct {
+	Dim int
+}
+
+func (v VariablyDimensioned) ObjGrad(x []float64) (obj float64, grad []float64, err error) {
+	if err := checkDim(x, v.Dim, 0); err != nil {
+		return math.NaN(), nil, err
+	}
+	n := len(x)
+	r := make([]float64, n+2)
+	jac := newJacobian(n+2, n)
+	var s float64
+	for j := range x {
+		r[j] = x[j] - 1
+		jac[j][j] = 1
+		s += float64(j+1) * (x[j] - 1)
+	}
+	r[n] = s
+	r[n+1] = s * s
+	for j := range x {
+		jac[n][j] = float64(j + 1)
+		jac[n+1][j] = 2 * s * float64(j+1)
+	}
+	grad = make([]float64, n)
+	return leastSquares(r, jac, grad), grad, nil
+}
+
+func (v VariablyDimensioned) Objective(x []float64) (obj float64, err error) {
+	obj, _, err = v.ObjGrad(x)
+	return obj, err
+}
+
+func (v VariablyDimensioned) Name() string {
+	return dimName("VariablyDimensioned", v.Dim)
+}
+
+func (v VariablyDimensioned) OptVal() float64 {
+	return 0
+}
+
+func (v VariablyDimensioned) OptLoc() []float64 {
+	return constant(v.Dim, 1)
+}
+
+func (v VariablyDimensioned) InitLoc() []float64 {
+	x := make([]float64, v.Dim)
+	for j := range x {
+		x[j] = 1 - float64(j+1)/float64(v.Dim)
+	}
+	return x
+}
+
+// Trigonometric is function 26. The minimum is repeated with period 2 pi
+// in every variable
+type Trigonometric struct {
+	Dim int
+}
+
+func (tr Trigonometric) ObjGrad(x []float64) (obj float64, grad []float64, err error) {
+	if err := checkDim(x, tr.Dim, 0); err != nil {
+		return math.NaN(), nil, err
+	}
+	n := len(x)
+	var sumCos float64
+	for _, v := range x {
+		sumCos += math.Cos(v)
+	}
+	r := make([]float64, n)
+	jac := newJacobian(n, n)
+	for i, v := range x {
+		c, s := math.Cos(v), math.Sin(v)
+		r[i] = float64(n) - sumCos + float64(i+1)*(1-c) - s
+		for j, w := range x {
+			jac[i][j] = math.Sin(w)
+		}
+		jac[i][i] += float64(i+1)*s - c
+	}
+	grad = make([]float64, n)
+	return leastSquares(r, jac, grad), grad, nil
+}
+
+func (tr Trigonometric) Objective(x []float64) (obj float64, err error) {
+	obj, _, err = tr.ObjGrad(x)
+	return obj, err
+}
+
+func (tr Trigonometric) Name() string {
+	return dimName("Trigonometric", tr.Dim)
+}
+
+func (tr Trigonometric) OptVal() float64 {
+	return 0
+}
+
+func (tr Trigonometric) OptLoc() []float64 {
+	return make([]float64, tr.Dim)
+}
+
+func (tr Trigonometric) InitLoc() []float64 {
+	return constant(tr.Dim, 1/float64(tr.Dim))
+}
@@ -0,0 +1,172 @@
+package testfunctions
+
+import (
+	"math"
+)
+
+// The functions in this file have many local minima around the global
+// minimum at the origin. The starting location is halfway to the upper
+// bound in every variable
+
+// Rastrigin is the Rastrigin function on [-5.12, 5.12]^Dim, a paraboloid
+// with a cosine ripple that puts a local minimum near every integer
+// location
+type Rastrigin struct {
+	Dim int
+}
+
+func (ra Rastrigin) ObjGrad(x []float64) (obj float64, grad []float64, err error) {
+	if err := checkDim(x, ra.Dim, 0); err != nil {
+		return math.NaN(), nil, err
+	}
+	grad = make([]float64, len(x))
+	obj = 10 * float64(len(x))
+	for i, v := range x {
+		obj += v*v - 10*math.Cos(2*math.Pi*v)
+		grad[i] = 2*v + 20*math.Pi*math.Sin(2*math.Pi*v)
+	}
+	return obj, grad, nil
+}
+
+func (ra Rastrigin) Objective(x []float64) (obj float64, err error) {
+	obj, _, err = ra.ObjGrad(x)
+	return obj, err
+}
+
+func (ra Rastrigin) Name() string {
+	return dimName("Rastrigin", ra.Dim)
+}
+
+func (ra Rastrigin) OptVal() float64 {
+	return 0
+}
+
+func (ra Rastrigin) OptLoc() []float64 {
+	return make([]float64, ra.Dim)
+}
+
+func (ra Rastrigin) InitLoc() []float64 {
+	return constant(ra.Dim, 2.56)
+}
+
+func (ra Rastrigin) Bounds() (lower, upper []float64) {
+	return constant(ra.Dim, -5.12), constant(ra.Dim, 5.12)
+}
+
+// Ackley is the Ackley function on [-32.768, 32.768]^Dim, which is nearly
+// flat far from the origin. The gradient is set to zero at the origin,
+// where the function is not differentiable
+type Ackley struct {
+	Dim int
+}
+
+func (a Ackley) ObjGrad(x []float64) (obj float64, grad []float64, err error) {
+	if err := checkDim(x, a.Dim, 0); err != nil {
+		return math.NaN(), nil, err
+	}
+	n := float64(len(x))
+	var sumSq, sumCos float64
+	for _, v := range x {
+		sumSq += v * v
+		sumCos += math.Cos(2 * math.Pi * v)
+	}
+	rms := math.Sqrt(sumSq / n)
+	e1 := math.Exp(-0.2 * rms)
+	e2 := math.Exp(sumCos / n)
+	obj = -20*e1 - e2 + 20 + math.E
+
+	grad = make([]float64, len(x))
+	for i, v := range x {
+		if rms != 0 {
+			grad[i] = 4 * e1 * v / (n * rms)
+		}
+		grad[i] += e2 * 2 * math.Pi * math.Sin(2*math.Pi*v) / n
+	}
+	return obj, grad, nil
+}
+
+func (a Ackley) Objective(x []float64) (obj float64, err error) {
+	obj, _, err = a.ObjGrad(x)
+	return obj, err
+}
+
+func (a Ackley) Name() string {
+	return dimName("Ackley", a.Dim)
+}
+
+func (a Ackley) OptVal() float64 {
+	return 0
+}
+
+func (a Ackley) OptLoc() []float64 {
+	return make([]float64, a.Dim)
+}
+
+func (a Ackley) InitLoc() []float64 {
+	return constant(a.Dim, 16.384)
+}
+
+func (a Ackley) Bounds() (lower, upper []float64) {
+	return constant(a.Dim, -32.768), constant(a.Dim, 32.768)
+}
+
+// Griewank is the Griewank function on [-600, 600]^Dim, a shallow
+// paraboloid with a product of cosines that couples the variables
+type Griewank struct {
+	Dim int
+}
+
+func (gr Griewank) ObjGrad(x []float64) (obj float64, grad []float64, err error) {
+	if err := checkDim(x, gr.Dim, 0); err != nil {
+		return math.NaN(), nil, err
+	}
+	cos := make([]float64, len(x))
+	prod := 1.0
+	var sumSq float64
+	for i, v := range x {
+		sumSq += v * v
+		cos[i] = math.Cos(v / math.Sqrt(float64(i+1)))
+		prod *= cos[i]
+	}
+	obj = 1 + sumSq/4000 - prod
+
+	grad = make([]float64, len(x))
+	for i, v := range x {
+		// The product without the ith term, computed directly so that
+		// a zero cosine elsewhere is handled
+		others := 1.0
+		for j, c := range cos {
+			if j != i {
+				others *= c
+			}
+		}
+		s := math.Sqrt(float64(i + 1))
+		grad[i] = v/2000 + others*math.Sin(v/s)/s
+	}
+	return obj, grad, nil
+}
+
+func (gr Griewank) Objective(x []float64) (obj float64, err error) {
+	obj, _, err = gr.ObjGrad(x)
+	return obj, err
+}
+
+func (gr Griewank) Name() string {
+	return dimName("Griewank", gr.Dim)
+}
+
+func (gr Griewank) OptVal() float64 {
+	return 0
+}
+
+func (gr Griewank) OptLoc() []float64 {
+	return make([]float64, gr.Dim)
+}
+
+func (gr Griewank) InitLoc() []float64 {
+	return constant(gr.Dim, 300)
+}
+
+func (gr Griewank) Bounds() (lower, upper []float64) {
+	return constant(gr.Dim, -600), constant(gr.Dim, 600)
+}
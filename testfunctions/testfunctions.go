@@ -0,0 +1,115 @@
+// Package testfunctions provides standard functions for testing and
+// benchmarking optimizers. Each function implements optimize.MultiObj and
+// optimize.MultiObjGrad, and knows its standard starting location and the
+// value and location of its global minimum
+package testfunctions
+
+import (
+	"github.com/btracey/gofunopter/common/optimize"
+
+	"errors"
+	"strconv"
+)
+
+// Function is a test function with a known global minimum
+type Function interface {
+	optimize.MultiObj
+	optimize.MultiObjGrad
+	Name() string
+	OptVal() float64
+	// OptLoc returns a location of the global minimum. Some functions have
+	// several, in which case it is the one nearest the starting location
+	// listed in the literature
+	OptLoc() []float64
+	InitLoc() []float64
+}
+
+// Bounded is a test function which is usually searched over a box, as is
+// the case for the multimodal functions used to test global optimizers
+type Bounded interface {
+	Function
+	Bounds() (lower, upper []float64)
+}
+
+var errDimension = errors.New("testfunctions: location has the wrong dimension")
+
+// checkDim returns an error unless x has a positive length which is n if
+// n is not zero, and a multiple of m if m is not zero. The variable
+// dimension functions pass their Dim as n, so that a zero Dim accepts any
+// valid length
+func checkDim(x []float64, n, m int) error {
+	if len(x) == 0 || (n != 0 && len(x) != n) || (m != 0 && len(x)%m != 0) {
+		return errDimension
+	}
+	return nil
+}
+
+// leastSquares returns the sum of squares of the residuals r, and sets
+// grad to its gradient 2 J^T r, where J is the Jacobian of the residuals
+func leastSquares(r []float64, jac [][]float64, grad []float64) float64 {
+	for i := range grad {
+		grad[i] = 0
+	}
+	var f float64
+	for i, ri := range r {
+		f += ri * ri
+		for j, v := range jac[i] {
+			grad[j] += 2 * v * ri
+		}
+	}
+	return f
+}
+
+func newJacobian(m, n int) [][]float64 {
+	jac := make([][]float64, m)
+	for i := range jac {
+		jac[i] = make([]float64, n)
+	}
+	return jac
+}
+
+func constant(n int, v float64) []float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = v
+	}
+	return x
+}
+
+func dimName(name string, n int) string {
+	return name + ", nDim = " + strconv.Itoa(n)
+}
+
+// MoreGarbowHillstrom returns the problems used here from the collection
+// of Moré, Garbow and Hillstrom ("Testing unconstrained optimization
+// software", ACM TOMS 7, 1981), with the variable dimension problems at
+// dimension n. n must be a positive multiple of 4
+func MoreGarbowHillstrom(n int) []Function {
+	if n <= 0 || n%4 != 0 {
+		panic("testfunctions: dimension must be a positive multiple of 4")
+	}
+	return []Function{
+		ExtendedRosenbrock{Dim: 2},
+		FreudensteinRoth{},
+		PowellBadlyScaled{},
+		BrownBadlyScaled{},
+		Beale{},
+		HelicalValley{},
+		Box3D{},
+		PowellSingular{},
+		Wood{},
+		VariablyDimensioned{Dim: n},
+		Trigonometric{Dim: n},
+		ExtendedRosenbrock{Dim: n},
+		ExtendedPowellSingular{Dim: n},
+	}
+}
+
+// Multimodal returns the multimodal functions at dimension n
+func Multimodal(n int) []Bounded {
+	return []Bounded{
+		Rastrigin{Dim: n},
+		Ackley{Dim: n},
+		Griewank{Dim: n},
+	}
+}
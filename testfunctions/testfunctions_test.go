@@ -0,0 +1,111 @@
+package testfunctions
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func allFunctions() []Function {
+	funcs := MoreGarbowHillstrom(8)
+	for _, f := range Multimodal(5) {
+		funcs = append(funcs, f)
+	}
+	return funcs
+}
+
+// checkGradient compares the gradient with central differences, allowing
+// for the rounding error of the differences of large function values
+func checkGradient(t *testing.T, f Function, x []float64) {
+	obj, grad, err := f.ObjGrad(x)
+	if err != nil {
+		t.Errorf("%v: error evaluating at %v: %v", f.Name(), x, err)
+		return
+	}
+	xh := make([]float64, len(x))
+	copy(xh, x)
+	for i := range x {
+		h := 1E-6 * math.Max(1, math.Abs(x[i]))
+		xh[i] = x[i] + h
+		fp, _ := f.Objective(xh)
+		xh[i] = x[i] - h
+		fm, _ := f.Objective(xh)
+		xh[i] = x[i]
+		fd := (fp - fm) / (2 * h)
+		tol := 1E-4*math.Max(1, math.Abs(fd)) + 1E-14*math.Abs(obj)/h
+		if math.Abs(fd-grad[i]) > tol {
+			t.Errorf("%v: derivative %v at %v is %v, finite difference is %v", f.Name(), i, x, grad[i], fd)
+		}
+	}
+}
+
+func TestGradients(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, f := range allFunctions() {
+		init := f.InitLoc()
+		checkGradient(t, f, init)
+		x := make([]float64, len(init))
+		for i := range x {
+			x[i] = init[i] + rnd.Float64() - 0.5
+		}
+		checkGradient(t, f, x)
+	}
+}
+
+func TestOptimum(t *testing.T) {
+	for _, f := range allFunctions() {
+		opt := f.OptLoc()
+		if len(opt) != len(f.InitLoc()) {
+			t.Errorf("%v: optimum and starting location have different dimensions", f.Name())
+			continue
+		}
+		obj, grad, err := f.ObjGrad(opt)
+		if err != nil {
+			t.Errorf("%v: error evaluating at the optimum: %v", f.Name(), err)
+			continue
+		}
+		if math.Abs(obj-f.OptVal()) > 1E-12 {
+			t.Errorf("%v: value %v at the optimum, expected %v", f.Name(), obj, f.OptVal())
+		}
+		for i, g := range grad {
+			if math.Abs(g) > 1E-8 {
+				t.Errorf("%v: derivative %v is %v at the optimum", f.Name(), i, g)
+			}
+		}
+		init, _ := f.Objective(f.InitLoc())
+		if !(init > obj) {
+			t.Errorf("%v: starting value %v is not above the optimum", f.Name(), init)
+		}
+	}
+	for _, f := range Multimodal(3) {
+		lower, upper := f.Bounds()
+		for i, v := range f.OptLoc() {
+			if v < lower[i] || v > upper[i] {
+				t.Errorf("%v: optimum outside the bounds", f.Name())
+			}
+		}
+	}
+}
+
+func TestDimension(t *testing.T) {
+	if _, _, err := (Beale{}).ObjGrad([]float64{1, 2, 3}); err == nil {
+		t.Errorf("no error for the wrong dimension")
+	}
+	if _, err := (ExtendedPowellSingular{Dim: 4}).Objective([]float64{1, 2, 3}); err == nil {
+		t.Errorf("no error for a dimension which is not a multiple of 4")
+	}
+	for _, f := range append(MoreGarbowHillstrom(4), Rastrigin{Dim: 4}, Ackley{Dim: 4}, Griewank{Dim: 4}) {
+		if _, err := f.Objective(make([]float64, 8)); err == nil {
+			t.Errorf("%v: no error for a location longer than Dim", f.Name())
+		}
+	}
+	if _, err := (ExtendedRosenbrock{}).Objective(make([]float64, 6)); err != nil {
+		t.Errorf("error for a zero Dim: %v", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("no panic for a dimension which is not a multiple of 4")
+		}
+	}()
+	MoreGarbowHillstrom(6)
+}
@@ -0,0 +1,152 @@
+package univariate
+
+import (
+	"github.com/btracey/gofunopter/common/display"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+)
+
+// Bisection finds a minimum of a univariate function using only its
+// derivative. The first iteration brackets a sign change of the derivative
+// with BracketStationary starting from the initial location, and each later
+// iteration evaluates the derivative at the midpoint of the bracket and
+// keeps the half with the sign change. The current location is the
+// latest midpoint. It converges with status.StepAbsTol when the bracket
+// is narrower than WidthTolerance
+type Bisection struct {
+	// Tunable parameters
+	InitialStep    float64 // Distance to the first trial location of the bracketing
+	WidthTolerance float64 // Bracket width below which the search converges
+
+	// Other needed variables
+	bracketed bool
+	lo        float64 // derivative non-positive
+	hi        float64 // derivative non-negative
+}
+
+func NewBisection() *Bisection {
+	return &Bisection{
+		InitialStep:    1,
+		WidthTolerance: 1E-12,
+	}
+}
+
+func (b *Bisection) Initialize(loc *uni.Location, grad *uni.Gradient) error {
+	if b.InitialStep == 0 {
+		return errors.New("bisection: initial step must be non-zero")
+	}
+	if b.WidthTolerance < 0 {
+		return errors.New("bisection: width tolerance must be non-negative")
+	}
+	b.bracketed = false
+	return nil
+}
+
+func (b *Bisection) Iterate(loc *uni.Location, grad *uni.Gradient, fun optimize.UniGrad) (status.Status, error) {
+	if !b.bracketed {
+		lo, hi, glo, ghi, err := BracketStationary(fun, loc.Curr(), grad.Curr(), b.InitialStep)
+		if err == errNoBracket {
+			return status.OptimizerError, errors.New("bisection: " + err.Error())
+		}
+		if err != nil {
+			return optimize.EvalStatus("bisection", err)
+		}
+		b.lo, b.hi = lo, hi
+		b.bracketed = true
+		if -glo < ghi {
+			loc.SetCurr(lo)
+			grad.SetCurr(glo)
+		} else {
+			loc.SetCurr(hi)
+			grad.SetCurr(ghi)
+		}
+		return status.Continue, nil
+	}
+
+	mid := b.lo + (b.hi-b.lo)/2
+	if mid == b.lo || mid == b.hi {
+		// The bracket can't be resolved any further
+		return status.StepAbsTol, nil
+	}
+	g, err := fun.Gradient(mid)
+	if err != nil {
+		return optimize.EvalStatus("bisection", err)
+	}
+	switch {
+	case g < 0:
+		b.lo = mid
+	case g > 0:
+		b.hi = mid
+	case g == 0:
+		b.lo, b.hi = mid, mid
+	default:
+		return status.OptimizerError, errors.New("bisection: derivative is NaN")
+	}
+	loc.SetCurr(mid)
+	grad.SetCurr(g)
+	return status.Continue, nil
+}
+
+func (b *Bisection) Status() status.Status {
+	if b.bracketed && b.hi-b.lo < b.WidthTolerance {
+		return status.StepAbsTol
+	}
+	return status.Continue
+}
+
+func (b *Bisection) AddToDisplay(d []*display.Struct) []*display.Struct {
+	var width float64
+	if b.bracketed {
+		width = b.hi - b.lo
+	}
+	return append(d, &display.Struct{Value: width, Heading: "Width"})
+}
+
+// BoundedBisection finds a minimum of a univariate function on an interval
+// using its derivative, so the user defined function must implement
+// optimize.UniObjGrad. Each iteration evaluates the function at the
+// midpoint of the interval and keeps the half in which the function
+// decreases, so the interval shrinks towards an end if the minimum is
+// there
+type BoundedBisection struct{}
+
+func NewBoundedBisection() *BoundedBisection {
+	return &BoundedBisection{}
+}
+
+func (b *BoundedBisection) Initialize(loc *uni.Location, obj *uni.Objective, interval *uni.BoundedStep) error {
+	return nil
+}
+
+func (b *BoundedBisection) Iterate(loc *uni.Location, obj *uni.Objective, interval *uni.BoundedStep, fun optimize.UniObj) (status.Status, error) {
+	objGrad, ok := fun.(optimize.UniObjGrad)
+	if !ok {
+		return optimize.EvalStatus("bounded bisection", optimize.ErrNoDerivative)
+	}
+	mid := interval.Midpoint()
+	if mid == interval.Lb() || mid == interval.Ub() {
+		// The interval can't be resolved any further
+		return status.StepAbsTol, nil
+	}
+	f, g, err := objGrad.ObjGrad(mid)
+	if err != nil {
+		return optimize.EvalStatus("bounded bisection", err)
+	}
+	switch {
+	case g < 0:
+		interval.SetLb(mid)
+	case g > 0:
+		interval.SetUb(mid)
+	case g == 0:
+		interval.SetLb(mid)
+		interval.SetUb(mid)
+	default:
+		return status.OptimizerError, errors.New("bounded bisection: derivative is NaN")
+	}
+	loc.SetCurr(mid)
+	obj.SetCurr(f)
+	return status.Continue, nil
+}
@@ -0,0 +1,129 @@
+package univariate
+
+import (
+	"github.com/btracey/gofunopter/common/optimize"
+
+	"errors"
+	"math"
+)
+
+// goldenRatio is the factor by which the step grows while bracketing
+const goldenRatio = 1.618033988749895
+
+// maxExpansions is the number of times the step may grow while bracketing
+// before the function is assumed to be unbounded below (the step has grown
+// by a factor of about 1E20)
+const maxExpansions = 100
+
+var errNoBracket = errors.New("bracket: no minimum found, the function may be unbounded below")
+
+// MinBracket is three locations A < B < C for which the objective at B is
+// no larger than at A or C, so a local minimum lies in [A, C]
+type MinBracket struct {
+	A, B, C    float64
+	FA, FB, FC float64
+}
+
+// Width returns the width of the bracket
+func (b MinBracket) Width() float64 {
+	return b.C - b.A
+}
+
+// BracketMinimum brackets a minimum of fun starting from x, where the
+// objective value is fx (if fx is NaN it is evaluated). The first trial
+// location is x + step, and the search goes downhill with the step growing
+// by the golden ratio until the objective increases. A NaN objective value
+// is treated as an increase. Errors from fun are returned unchanged
+func BracketMinimum(fun optimize.UniObj, x, fx, step float64) (MinBracket, error) {
+	if step == 0 || math.IsNaN(step) || math.IsInf(step, 0) {
+		return MinBracket{}, errors.New("bracket: step must be finite and non-zero")
+	}
+	var err error
+	if math.IsNaN(fx) {
+		fx, err = fun.Objective(x)
+		if err != nil {
+			return MinBracket{}, err
+		}
+	}
+	a, fa := x, fx
+	b := x + step
+	fb, err := fun.Objective(b)
+	if err != nil {
+		return MinBracket{}, err
+	}
+	if !(fb <= fa) {
+		// Go downhill from b to a instead
+		a, b = b, a
+		fa, fb = fb, fa
+	}
+	c := b + goldenRatio*(b-a)
+	fc, err := fun.Objective(c)
+	if err != nil {
+		return MinBracket{}, err
+	}
+	for i := 0; fc < fb; i++ {
+		if i == maxExpansions {
+			return MinBracket{}, errNoBracket
+		}
+		a, fa = b, fb
+		b, fb = c, fc
+		c = b + goldenRatio*(b-a)
+		fc, err = fun.Objective(c)
+		if err != nil {
+			return MinBracket{}, err
+		}
+	}
+	if a > c {
+		a, c = c, a
+		fa, fc = fc, fa
+	}
+	return MinBracket{A: a, B: b, C: c, FA: fa, FB: fb, FC: fc}, nil
+}
+
+// BracketStationary brackets a zero of the derivative of a function
+// starting from x, where the derivative is gx (if gx is NaN it is
+// evaluated). The search goes downhill, with the step growing by the
+// golden ratio, until the derivative changes sign, so the zero bracketed
+// is a local minimum. It returns lo <= hi with the derivative at lo
+// non-positive and at hi non-negative. Errors from fun are returned
+// unchanged
+func BracketStationary(fun optimize.UniGrad, x, gx, step float64) (lo, hi, glo, ghi float64, err error) {
+	step = math.Abs(step)
+	if step == 0 || math.IsNaN(step) || math.IsInf(step, 0) {
+		return math.NaN(), math.NaN(), math.NaN(), math.NaN(), errors.New("bracket: step must be finite and non-zero")
+	}
+	if math.IsNaN(gx) {
+		gx, err = fun.Gradient(x)
+		if err != nil {
+			return math.NaN(), math.NaN(), math.NaN(), math.NaN(), err
+		}
+	}
+	if gx == 0 {
+		return x, x, gx, gx, nil
+	}
+	dir := 1.0
+	if gx > 0 {
+		dir = -1
+	}
+	y := x + dir*step
+	gy, err := fun.Gradient(y)
+	for i := 0; err == nil && gy*dir < 0; i++ {
+		if i == maxExpansions {
+			return math.NaN(), math.NaN(), math.NaN(), math.NaN(), errNoBracket
+		}
+		x, gx = y, gy
+		step *= goldenRatio
+		y = x + dir*step
+		gy, err = fun.Gradient(y)
+	}
+	if err != nil {
+		return math.NaN(), math.NaN(), math.NaN(), math.NaN(), err
+	}
+	if math.IsNaN(gy) {
+		return math.NaN(), math.NaN(), math.NaN(), math.NaN(), errors.New("bracket: derivative is NaN")
+	}
+	if dir < 0 {
+		return y, x, gy, gx, nil
+	}
+	return x, y, gx, gy, nil
+}
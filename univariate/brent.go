@@ -0,0 +1,145 @@
+package univariate
+
+import (
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+	"math"
+)
+
+// Brent is Brent's method for minimizing a function on an interval using
+// only function values. Each iteration fits a parabola through the three
+// best locations found so far, and evaluates its minimum if it lies well
+// inside the interval and the step is less than half the step two
+// iterations before. Otherwise it takes a golden section step into the
+// larger part of the interval. It converges with status.StepAbsTol when
+// the current location is within
+// 2 * (RelativeTolerance * |x| + tol / 3) of every point of the interval,
+// where tol is the width tolerance of the interval. As with golden section
+// search, the location of a minimum cannot be found more accurately than
+// about the square root of machine precision relative to the location
+type Brent struct {
+	// Tunable parameters
+	RelativeTolerance float64
+
+	// Other needed variables
+	interval *uni.BoundedStep
+	x        float64 // best location
+	w, v     float64 // second and third best locations
+	fw, fv   float64
+	d        float64 // last step
+	e        float64 // step before last
+}
+
+func NewBrent() *Brent {
+	return &Brent{
+		RelativeTolerance: 1.4901161193847656E-8, // square root of machine precision
+	}
+}
+
+func (b *Brent) Initialize(loc *uni.Location, obj *uni.Objective, interval *uni.BoundedStep) error {
+	if b.RelativeTolerance < 0 {
+		return errors.New("brent: relative tolerance must be non-negative")
+	}
+	b.interval = interval
+	b.x = loc.Curr()
+	b.w, b.v = loc.Curr(), loc.Curr()
+	b.fw, b.fv = obj.Curr(), obj.Curr()
+	b.d, b.e = 0, 0
+	return nil
+}
+
+// tol is the smallest distance from the current location at which a new
+// location is evaluated
+func (b *Brent) tol(x float64) float64 {
+	return b.RelativeTolerance*math.Abs(x) + b.interval.AbsTol()/3
+}
+
+func (b *Brent) Iterate(loc *uni.Location, obj *uni.Objective, interval *uni.BoundedStep, fun optimize.UniObj) (status.Status, error) {
+	x, fx := loc.Curr(), obj.Curr()
+	lo, hi := interval.Lb(), interval.Ub()
+	mid := interval.Midpoint()
+	tol1 := b.tol(x)
+	tol2 := 2 * tol1
+
+	golden := true
+	if math.Abs(b.e) > tol1 {
+		// Parabola through x, w and v
+		r := (x - b.w) * (fx - b.fv)
+		q := (x - b.v) * (fx - b.fw)
+		p := (x-b.v)*q - (x-b.w)*r
+		q = 2 * (q - r)
+		if q > 0 {
+			p = -p
+		}
+		q = math.Abs(q)
+		if math.Abs(p) < math.Abs(0.5*q*b.e) && p > q*(lo-x) && p < q*(hi-x) {
+			b.e = b.d
+			b.d = p / q
+			golden = false
+			// Don't evaluate too close to the ends
+			if u := x + b.d; u-lo < tol2 || hi-u < tol2 {
+				b.d = math.Copysign(tol1, mid-x)
+			}
+		}
+	}
+	if golden {
+		if x >= mid {
+			b.e = lo - x
+		} else {
+			b.e = hi - x
+		}
+		b.d = goldenFraction * b.e
+	}
+	u := x + b.d
+	if math.Abs(b.d) < tol1 {
+		u = x + math.Copysign(tol1, b.d)
+	}
+	fu, err := fun.Objective(u)
+	if err != nil {
+		return optimize.EvalStatus("brent", err)
+	}
+
+	if fu <= fx {
+		if u >= x {
+			interval.SetLb(x)
+		} else {
+			interval.SetUb(x)
+		}
+		b.v, b.fv = b.w, b.fw
+		b.w, b.fw = x, fx
+		b.x = u
+		loc.SetCurr(u)
+		obj.SetCurr(fu)
+		return status.Continue, nil
+	}
+	if u < x {
+		interval.SetLb(u)
+	} else {
+		interval.SetUb(u)
+	}
+	switch {
+	case fu <= b.fw || b.w == x:
+		b.v, b.fv = b.w, b.fw
+		b.w, b.fw = u, fu
+	case fu <= b.fv || b.v == x || b.v == b.w:
+		b.v, b.fv = u, fu
+	}
+	return status.Continue, nil
+}
+
+func (b *Brent) Status() status.Status {
+	if b.interval == nil {
+		return status.Continue
+	}
+	if math.Max(b.x-b.interval.Lb(), b.interval.Ub()-b.x) <= 2*b.tol(b.x) {
+		return status.StepAbsTol
+	}
+	return status.Continue
+}
+
+func (b *Brent) SetResult() {
+	b.interval = nil
+}
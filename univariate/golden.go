@@ -0,0 +1,113 @@
+package univariate
+
+import (
+	"github.com/btracey/gofunopter/common/display"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+)
+
+// goldenFraction is the fraction of the larger part of the bracket at
+// which golden section search places the next trial location
+const goldenFraction = 0.3819660112501051
+
+// GoldenSection minimizes a univariate function using only function
+// values. The first iteration brackets a minimum with BracketMinimum
+// starting from the initial location, and each later iteration evaluates
+// one location in the larger part of the bracket, shrinking the bracket by
+// the golden ratio every iteration. It converges with status.StepAbsTol
+// when the bracket is narrower than WidthTolerance. Comparing objective
+// values cannot locate a minimum more accurately than about the square
+// root of machine precision relative to the location
+type GoldenSection struct {
+	// Tunable parameters
+	InitialStep    float64 // Distance to the first trial location of the bracketing
+	WidthTolerance float64 // Bracket width below which the search converges
+
+	// Other needed variables
+	bracketed bool
+	br        MinBracket
+}
+
+func NewGoldenSection() *GoldenSection {
+	return &GoldenSection{
+		InitialStep:    1,
+		WidthTolerance: 1E-8,
+	}
+}
+
+func (g *GoldenSection) Initialize(loc *uni.Location, obj *uni.Objective) error {
+	if g.InitialStep == 0 {
+		return errors.New("golden section: initial step must be non-zero")
+	}
+	if g.WidthTolerance < 0 {
+		return errors.New("golden section: width tolerance must be non-negative")
+	}
+	g.bracketed = false
+	return nil
+}
+
+func (g *GoldenSection) Iterate(loc *uni.Location, obj *uni.Objective, fun optimize.UniObj) (status.Status, error) {
+	if !g.bracketed {
+		br, err := BracketMinimum(fun, loc.Curr(), obj.Curr(), g.InitialStep)
+		if err == errNoBracket {
+			return status.OptimizerError, errors.New("golden section: " + err.Error())
+		}
+		if err != nil {
+			return optimize.EvalStatus("golden section", err)
+		}
+		g.br = br
+		g.bracketed = true
+		loc.SetCurr(br.B)
+		obj.SetCurr(br.FB)
+		return status.Continue, nil
+	}
+
+	br := &g.br
+	var x float64
+	if br.C-br.B > br.B-br.A {
+		x = br.B + goldenFraction*(br.C-br.B)
+	} else {
+		x = br.B - goldenFraction*(br.B-br.A)
+	}
+	if x == br.B {
+		// The bracket can't be resolved any further
+		return status.StepAbsTol, nil
+	}
+	f, err := fun.Objective(x)
+	if err != nil {
+		return optimize.EvalStatus("golden section", err)
+	}
+	switch {
+	case f < br.FB && x > br.B:
+		br.A, br.FA = br.B, br.FB
+		br.B, br.FB = x, f
+	case f < br.FB:
+		br.C, br.FC = br.B, br.FB
+		br.B, br.FB = x, f
+	case x > br.B:
+		br.C, br.FC = x, f
+	default:
+		br.A, br.FA = x, f
+	}
+	loc.SetCurr(br.B)
+	obj.SetCurr(br.FB)
+	return status.Continue, nil
+}
+
+func (g *GoldenSection) Status() status.Status {
+	if g.bracketed && g.br.Width() < g.WidthTolerance {
+		return status.StepAbsTol
+	}
+	return status.Continue
+}
+
+func (g *GoldenSection) AddToDisplay(d []*display.Struct) []*display.Struct {
+	var width float64
+	if g.bracketed {
+		width = g.br.Width()
+	}
+	return append(d, &display.Struct{Value: width, Heading: "Width"})
+}
@@ -0,0 +1,218 @@
+package univariate
+
+import (
+	"github.com/btracey/gofunopter/common/display"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+	"math"
+)
+
+// derivBracket is the safeguard shared by Newton and Secant. It keeps the
+// largest location known to have a negative derivative and the smallest
+// known to have a positive derivative, which bracket a minimum once both
+// are finite
+type derivBracket struct {
+	lo   float64
+	hi   float64
+	step float64 // length of the next expansion step while unbracketed
+}
+
+func (d *derivBracket) reset(initialStep float64) {
+	d.lo = math.Inf(-1)
+	d.hi = math.Inf(1)
+	d.step = math.Abs(initialStep)
+}
+
+func (d *derivBracket) update(x, g float64) {
+	switch {
+	case g < 0:
+		d.lo = math.Max(d.lo, x)
+	case g > 0:
+		d.hi = math.Min(d.hi, x)
+	}
+}
+
+func (d *derivBracket) width() float64 {
+	return d.hi - d.lo
+}
+
+// next returns the next location from x, where the derivative is g, given
+// an estimate of the second derivative. The Newton step is used if the
+// curvature is positive and the step stays strictly within the bracket.
+// Otherwise the bracket is bisected, or if it is not yet closed the search
+// moves downhill with a step that doubles every time
+func (d *derivBracket) next(x, g, curv float64) float64 {
+	if curv > 0 {
+		t := x - g/curv
+		if t > d.lo && t < d.hi {
+			return t
+		}
+	}
+	if !math.IsInf(d.lo, 0) && !math.IsInf(d.hi, 0) {
+		return d.lo + (d.hi-d.lo)/2
+	}
+	step := d.step
+	d.step *= 2
+	if g > 0 {
+		return x - step
+	}
+	return x + step
+}
+
+// Newton minimizes a univariate function using its first and second
+// derivatives. The function must implement optimize.UniObjGradHess. Each
+// iteration takes the Newton step to the zero of the derivative. When the
+// second derivative is not positive or the step would leave the bracket of
+// the minimum found so far, the bracket is bisected instead (or expanded
+// in the downhill direction if it isn't yet closed). The first iteration
+// evaluates the second derivative at the initial location. It converges
+// with status.StepAbsTol if the bracket is narrower than WidthTolerance or
+// the step can no longer change the location
+type Newton struct {
+	// Tunable parameters
+	InitialStep    float64 // Step taken when the curvature can't be used before bracketing
+	WidthTolerance float64 // Bracket width below which the search converges
+
+	// Other needed variables
+	br   derivBracket
+	hess float64
+}
+
+func NewNewton() *Newton {
+	return &Newton{
+		InitialStep:    1,
+		WidthTolerance: 1E-12,
+	}
+}
+
+func (n *Newton) Initialize(loc *uni.Location, obj *uni.Objective, grad *uni.Gradient) error {
+	if n.InitialStep == 0 {
+		return errors.New("newton: initial step must be non-zero")
+	}
+	if n.WidthTolerance < 0 {
+		return errors.New("newton: width tolerance must be non-negative")
+	}
+	n.br.reset(n.InitialStep)
+	n.br.update(loc.Curr(), grad.Curr())
+	n.hess = math.NaN()
+	return nil
+}
+
+func (n *Newton) Iterate(loc *uni.Location, obj *uni.Objective, grad *uni.Gradient, fun optimize.UniObjGrad) (status.Status, error) {
+	h, ok := fun.(optimize.UniObjGradHess)
+	if !ok {
+		return status.OptimizerError, errors.New("newton: " + errNoHessian.Error())
+	}
+	x := loc.Curr()
+	if math.IsNaN(n.hess) {
+		_, _, hess, err := h.ObjGradHess(x)
+		if err != nil {
+			return newtonStatus(err)
+		}
+		n.hess = hess
+	}
+	t := n.br.next(x, grad.Curr(), n.hess)
+	if t == x {
+		return status.StepAbsTol, nil
+	}
+	f, g, hess, err := h.ObjGradHess(t)
+	if err != nil {
+		return newtonStatus(err)
+	}
+	n.hess = hess
+	n.br.update(t, g)
+	loc.SetCurr(t)
+	obj.SetCurr(f)
+	grad.SetCurr(g)
+	return status.Continue, nil
+}
+
+func newtonStatus(err error) (status.Status, error) {
+	if err == errNoHessian {
+		return status.OptimizerError, errors.New("newton: " + err.Error())
+	}
+	return status.UserFunctionError, errors.New("newton: user defined function error: " + err.Error())
+}
+
+func (n *Newton) Status() status.Status {
+	if n.br.width() < n.WidthTolerance {
+		return status.StepAbsTol
+	}
+	return status.Continue
+}
+
+func (n *Newton) AddToDisplay(d []*display.Struct) []*display.Struct {
+	return append(d, &display.Struct{Value: n.br.width(), Heading: "Width"})
+}
+
+// Secant minimizes a univariate function using the secant method on its
+// derivative, so the curvature is estimated from the last two derivatives.
+// It uses the same safeguard as Newton. The first iteration moves
+// InitialStep downhill
+type Secant struct {
+	// Tunable parameters
+	InitialStep    float64 // Step taken when the curvature can't be used before bracketing
+	WidthTolerance float64 // Bracket width below which the search converges
+
+	// Other needed variables
+	br    derivBracket
+	xPrev float64
+	gPrev float64
+}
+
+func NewSecant() *Secant {
+	return &Secant{
+		InitialStep:    1,
+		WidthTolerance: 1E-12,
+	}
+}
+
+func (s *Secant) Initialize(loc *uni.Location, obj *uni.Objective, grad *uni.Gradient) error {
+	if s.InitialStep == 0 {
+		return errors.New("secant: initial step must be non-zero")
+	}
+	if s.WidthTolerance < 0 {
+		return errors.New("secant: width tolerance must be non-negative")
+	}
+	s.br.reset(s.InitialStep)
+	s.br.update(loc.Curr(), grad.Curr())
+	s.xPrev = math.NaN()
+	s.gPrev = math.NaN()
+	return nil
+}
+
+func (s *Secant) Iterate(loc *uni.Location, obj *uni.Objective, grad *uni.Gradient, fun optimize.UniObjGrad) (status.Status, error) {
+	x := loc.Curr()
+	g := grad.Curr()
+	// NaN before the second location, which the safeguard treats as
+	// unusable curvature
+	curv := (g - s.gPrev) / (x - s.xPrev)
+	t := s.br.next(x, g, curv)
+	if t == x {
+		return status.StepAbsTol, nil
+	}
+	fNew, gNew, err := fun.ObjGrad(t)
+	if err != nil {
+		return status.UserFunctionError, errors.New("secant: user defined function error: " + err.Error())
+	}
+	s.xPrev, s.gPrev = x, g
+	s.br.update(t, gNew)
+	loc.SetCurr(t)
+	obj.SetCurr(fNew)
+	grad.SetCurr(gNew)
+	return status.Continue, nil
+}
+
+func (s *Secant) Status() status.Status {
+	if s.br.width() < s.WidthTolerance {
+		return status.StepAbsTol
+	}
+	return status.Continue
+}
+
+func (s *Secant) AddToDisplay(d []*display.Struct) []*display.Struct {
+	return append(d, &display.Struct{Value: s.br.width(), Heading: "Width"})
+}
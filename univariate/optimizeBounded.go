@@ -0,0 +1,228 @@
+package univariate
+
+import (
+	"github.com/btracey/gofunopter/common"
+	"github.com/btracey/gofunopter/common/display"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+	"math"
+)
+
+// BoundedOptimizer is a univariate optimizer which searches within an
+// interval. The lower and upper bounds of interval start at the ends of
+// the interval, and the optimizer should move them inward as it narrows
+// down the location of the minimum. The objective value set by the
+// optimizer should be the value at the current location. Optimizers which
+// use the derivative type assert fun to optimize.UniObjGrad
+type BoundedOptimizer interface {
+	Initialize(loc *uni.Location, obj *uni.Objective, interval *uni.BoundedStep) error
+	Iterate(loc *uni.Location, obj *uni.Objective, interval *uni.BoundedStep, fun optimize.UniObj) (status.Status, error)
+}
+
+// OptimizeBounded minimizes a univariate function on the interval
+// [lo, hi]. The search converges with status.StepAbsTol when the width of
+// the interval kept by the optimizer is less than
+// settings.WidthTolerance, or when the optimizer reports convergence. If
+// the interval then still reaches to lo or hi, the function is evaluated
+// at that end, and if the value there is no larger the search ends at the
+// end with status.BoundaryOptimum. If optimizer is nil, Brent is used
+func OptimizeBounded(function optimize.UniObj, lo, hi float64, settings *BoundedSettings, optimizer BoundedOptimizer) (optValue float64, optLocation float64, result *BoundedResult, err error) {
+
+	if settings == nil {
+		settings = NewBoundedSettings()
+	}
+
+	if optimizer == nil {
+		optimizer = NewBrent()
+	}
+
+	m := newBoundedStruct()
+	m.fun = &optimize.LimitedUniObj{
+		Fun:      function,
+		Loc:      m.loc,
+		Obj:      m.obj,
+		FunEvals: m.FunEvals,
+	}
+	m.settings = settings
+	m.optimizer = optimizer
+	m.lo = lo
+	m.hi = hi
+
+	m.loc.SetInit(lo + goldenFraction*(hi-lo))
+	err = optimize.OptimizeOpter(m, function)
+
+	return m.obj.Opt(), m.loc.Opt(), m.Result(), err
+}
+
+type BoundedResult struct {
+	*common.CommonResult
+	*uni.ObjectiveResult
+	*uni.LocationResult
+}
+
+type BoundedSettings struct {
+	*common.CommonSettings
+	*uni.ObjectiveSettings
+	*uni.LocationSettings
+	WidthTolerance float64 // Width of the interval below which the search converges
+}
+
+func NewBoundedSettings() *BoundedSettings {
+	return &BoundedSettings{
+		CommonSettings:    common.NewCommonSettings(),
+		ObjectiveSettings: uni.NewObjectiveSettings(),
+		LocationSettings:  uni.NewLocationSettings(),
+		WidthTolerance:    1E-10,
+	}
+}
+
+type boundedStruct struct {
+	*common.OptCommon
+
+	loc      *uni.Location
+	obj      *uni.Objective
+	interval *uni.BoundedStep
+
+	lo        float64
+	hi        float64
+	converged bool // the ends of the interval have been checked
+	atBound   bool
+
+	// User defined function
+	fun *optimize.LimitedUniObj
+
+	// Optimization model
+	optimizer BoundedOptimizer
+
+	// Settings
+	settings *BoundedSettings
+}
+
+func newBoundedStruct() *boundedStruct {
+	return &boundedStruct{
+		OptCommon: common.NewOptCommon(),
+		loc:       uni.NewLocation(),
+		obj:       uni.NewObjective(),
+		interval:  uni.NewBoundedStep(),
+	}
+}
+
+func (u *boundedStruct) CommonSettings() *common.CommonSettings {
+	return u.settings.CommonSettings
+}
+
+func (u *boundedStruct) SetSettings() error {
+	u.obj.SetSettings(u.settings.ObjectiveSettings)
+	u.loc.SetSettings(u.settings.LocationSettings)
+	u.interval.SetAbsTol(u.settings.WidthTolerance)
+	return nil
+}
+
+// interiorStatus is the status of the search within the interval
+func (u *boundedStruct) interiorStatus() status.Status {
+	c := status.CheckStatus(u.obj, u.interval)
+	if c != status.Continue {
+		return c
+	}
+	statuser, ok := u.optimizer.(status.Statuser)
+	if ok {
+		return statuser.Status()
+	}
+	return c
+}
+
+func (u *boundedStruct) Status() status.Status {
+	if u.atBound {
+		return status.BoundaryOptimum
+	}
+	return u.interiorStatus()
+}
+
+func (u *boundedStruct) AddToDisplay(d []*display.Struct) []*display.Struct {
+	d = display.AddToDisplay(d, u.loc, u.obj)
+	d = append(d, &display.Struct{Value: u.interval.Ub() - u.interval.Lb(), Heading: "Width"})
+	displayer, ok := u.optimizer.(display.Displayer)
+	if ok {
+		d = displayer.AddToDisplay(d)
+	}
+	return d
+}
+
+func (u *boundedStruct) Result() *BoundedResult {
+	return &BoundedResult{
+		CommonResult:    u.OptCommon.CommonResult(),
+		ObjectiveResult: u.obj.Result(),
+		LocationResult:  u.loc.Result(),
+	}
+}
+
+func (u *boundedStruct) SetResult() {
+	optimize.SetResult(u.loc, u.obj)
+
+	setResulter, ok := u.optimizer.(optimize.SetResulter)
+	if ok {
+		setResulter.SetResult()
+	}
+}
+
+func (u *boundedStruct) Initialize() error {
+	if !(u.lo <= u.hi) {
+		return errors.New("bounded: lower bound must not be greater than upper bound")
+	}
+	if x := u.loc.Init(); x < u.lo || x > u.hi {
+		return errors.New("bounded: initial location outside the interval")
+	}
+	if math.IsNaN(u.obj.Init()) {
+		initObj, err := u.fun.Objective(u.loc.Init())
+		if err != nil {
+			return errors.New("error calling function during optimization: \n" + err.Error())
+		}
+		u.obj.SetInit(initObj)
+	}
+	u.interval.SetLb(u.lo)
+	u.interval.SetUb(u.hi)
+	u.interval.SetInit(u.loc.Init())
+	u.converged = false
+	u.atBound = false
+
+	err := optimize.Initialize(u.loc, u.obj, u.interval)
+	if err != nil {
+		return err
+	}
+	return u.optimizer.Initialize(u.loc, u.obj, u.interval)
+}
+
+func (u *boundedStruct) Iterate() (status.Status, error) {
+	c, err := u.optimizer.Iterate(u.loc, u.obj, u.interval, u.fun)
+	if c < 0 || (c == status.Continue && u.interiorStatus() == status.Continue) || u.converged {
+		return c, err
+	}
+	// The search has converged within the interval, so check whether
+	// the minimum is at an end
+	u.converged = true
+	for _, end := range []float64{u.lo, u.hi} {
+		if !(end == u.interval.Lb() || end == u.interval.Ub()) {
+			continue
+		}
+		if u.loc.Curr() == end {
+			u.atBound = true
+			continue
+		}
+		f, err := u.fun.Objective(end)
+		if err != nil {
+			return optimize.EvalStatus("bounded", err)
+		}
+		if f <= u.obj.Curr() {
+			u.loc.SetCurr(end)
+			u.obj.SetCurr(f)
+			u.atBound = true
+		}
+	}
+	if c != status.Continue && u.atBound {
+		return status.BoundaryOptimum, nil
+	}
+	return c, nil
+}
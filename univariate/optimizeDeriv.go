@@ -0,0 +1,179 @@
+package univariate
+
+import (
+	"github.com/btracey/gofunopter/common"
+	"github.com/btracey/gofunopter/common/display"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+	"math"
+)
+
+type moddedDerivFun struct {
+	fun      optimize.UniGrad
+	loc      *uni.Location
+	grad     *uni.Gradient
+	funEvals *common.FunctionEvaluations
+}
+
+func (m *moddedDerivFun) Gradient(x float64) (grad float64, err error) {
+	if m.funEvals.Curr() >= m.funEvals.Max() {
+		return math.NaN(), optimize.ErrMaximumFunctionEvaluations
+	}
+	grad, err = m.fun.Gradient(x)
+	m.loc.AddToHist(x)
+	m.grad.AddToHist(grad)
+	m.funEvals.Add(1)
+	return
+}
+
+// UniDerivOptimizer is a univariate optimizer which uses only the
+// derivative of the function, and so finds a stationary point rather than
+// necessarily a minimum
+type UniDerivOptimizer interface {
+	Initialize(loc *uni.Location, grad *uni.Gradient) error
+	Iterate(loc *uni.Location, grad *uni.Gradient, fun optimize.UniGrad) (status.Status, error)
+}
+
+// OptimizeDeriv finds a zero of the derivative of a univariate function
+// for which only the derivative is available. If optimizer is nil,
+// Bisection is used
+func OptimizeDeriv(function optimize.UniGrad, initialLocation float64, settings *UniDerivSettings, optimizer UniDerivOptimizer) (optGrad float64, optLocation float64, result *UniDerivResult, err error) {
+
+	if settings == nil {
+		settings = NewUniDerivSettings()
+	}
+
+	if optimizer == nil {
+		optimizer = NewBisection()
+	}
+
+	m := newUniDerivStruct()
+	m.fun = &moddedDerivFun{
+		fun:      function,
+		loc:      m.loc,
+		grad:     m.grad,
+		funEvals: m.FunEvals,
+	}
+	m.settings = settings
+	m.optimizer = optimizer
+
+	m.loc.SetInit(initialLocation)
+	err = optimize.OptimizeOpter(m, function)
+
+	return m.grad.Opt(), m.loc.Opt(), m.Result(), err
+}
+
+type UniDerivResult struct {
+	*common.CommonResult
+	*uni.GradientResult
+	*uni.LocationResult
+}
+
+type UniDerivSettings struct {
+	*common.CommonSettings
+	*uni.GradientSettings
+	*uni.LocationSettings
+}
+
+func NewUniDerivSettings() *UniDerivSettings {
+	return &UniDerivSettings{
+		CommonSettings:   common.NewCommonSettings(),
+		GradientSettings: uni.NewGradientSettings(),
+		LocationSettings: uni.NewLocationSettings(),
+	}
+}
+
+type uniDerivStruct struct {
+	*common.OptCommon
+
+	loc  *uni.Location
+	grad *uni.Gradient
+
+	// User defined function
+	fun *moddedDerivFun
+
+	// Optimization model
+	optimizer UniDerivOptimizer
+
+	// Settings
+	settings *UniDerivSettings
+}
+
+func newUniDerivStruct() *uniDerivStruct {
+	return &uniDerivStruct{
+		OptCommon: common.NewOptCommon(),
+		loc:       uni.NewLocation(),
+		grad:      uni.NewGradient(),
+	}
+}
+
+func (u *uniDerivStruct) CommonSettings() *common.CommonSettings {
+	return u.settings.CommonSettings
+}
+
+func (u *uniDerivStruct) SetSettings() error {
+	u.grad.SetSettings(u.settings.GradientSettings)
+	u.loc.SetSettings(u.settings.LocationSettings)
+	return nil
+}
+
+func (u *uniDerivStruct) Status() status.Status {
+	c := status.CheckStatus(u.grad)
+	if c != status.Continue {
+		return c
+	}
+	statuser, ok := u.optimizer.(status.Statuser)
+	if ok {
+		return statuser.Status()
+	}
+	return c
+}
+
+func (u *uniDerivStruct) AddToDisplay(d []*display.Struct) []*display.Struct {
+	d = display.AddToDisplay(d, u.loc, u.grad)
+	displayer, ok := u.optimizer.(display.Displayer)
+	if ok {
+		d = displayer.AddToDisplay(d)
+	}
+	return d
+}
+
+func (u *uniDerivStruct) Result() *UniDerivResult {
+	return &UniDerivResult{
+		CommonResult:   u.OptCommon.CommonResult(),
+		GradientResult: u.grad.Result(),
+		LocationResult: u.loc.Result(),
+	}
+}
+
+func (u *uniDerivStruct) SetResult() {
+	optimize.SetResult(u.loc, u.grad)
+
+	setResulter, ok := u.optimizer.(optimize.SetResulter)
+	if ok {
+		setResulter.SetResult()
+	}
+}
+
+func (u *uniDerivStruct) Initialize() error {
+	if math.IsNaN(u.grad.Init()) {
+		initGrad, err := u.fun.Gradient(u.loc.Init())
+		if err != nil {
+			return errors.New("error calling function during optimization: \n" + err.Error())
+		}
+		u.grad.SetInit(initGrad)
+	}
+
+	err := optimize.Initialize(u.loc, u.grad)
+	if err != nil {
+		return err
+	}
+	return u.optimizer.Initialize(u.loc, u.grad)
+}
+
+func (u *uniDerivStruct) Iterate() (status.Status, error) {
+	return u.optimizer.Iterate(u.loc, u.grad, u.fun)
+}
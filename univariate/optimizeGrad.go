@@ -39,6 +39,24 @@ func (m *moddedFun) ObjGrad(x float64) (obj float64, grad float64, err error) {
 	return
 }
 
+var errNoHessian = errors.New("user defined function does not implement optimize.UniObjGradHess")
+
+// ObjGradHess evaluates the second derivative as well, for optimizers
+// which use it. It returns an error if the user defined function does not
+// provide the second derivative
+func (m *moddedFun) ObjGradHess(x float64) (obj float64, grad float64, hess float64, err error) {
+	h, ok := m.uni.(optimize.UniObjGradHess)
+	if !ok {
+		return math.NaN(), math.NaN(), math.NaN(), errNoHessian
+	}
+	obj, grad, hess, err = h.ObjGradHess(x)
+	m.loc.AddToHist(x)
+	m.obj.AddToHist(obj)
+	m.grad.AddToHist(grad)
+	m.funEvals.Add(1)
+	return
+}
+
 type UniGradOptimizer interface {
 	Initialize(loc *uni.Location, obj *uni.Objective, grad *uni.Gradient) error
 	Iterate(loc *uni.Location, obj *uni.Objective, grad *uni.Gradient, fun optimize.UniObjGrad) (status.Status, error)
@@ -136,11 +154,23 @@ func (m *uniGradStruct) SetSettings() error {
 }
 
 func (m *uniGradStruct) Status() status.Status {
-	return status.CheckStatus(m.obj, m.grad)
+	c := status.CheckStatus(m.obj, m.grad)
+	if c != status.Continue {
+		return c
+	}
+	statuser, ok := m.optimizer.(status.Statuser)
+	if ok {
+		return statuser.Status()
+	}
+	return c
 }
 
 func (m *uniGradStruct) AddToDisplay(d []*display.Struct) []*display.Struct {
 	d = display.AddToDisplay(d, m.loc, m.obj, m.grad)
+	displayer, ok := m.optimizer.(display.Displayer)
+	if ok {
+		d = displayer.AddToDisplay(d)
+	}
 	return d
 }
 
@@ -0,0 +1,159 @@
+package univariate
+
+import (
+	"github.com/btracey/gofunopter/common"
+	"github.com/btracey/gofunopter/common/display"
+	"github.com/btracey/gofunopter/common/optimize"
+	"github.com/btracey/gofunopter/common/status"
+	"github.com/btracey/gofunopter/common/uni"
+
+	"errors"
+	"math"
+)
+
+// UniObjOptimizer is a derivative-free univariate optimizer. The location
+// and objective value set by the optimizer should be the best found so far
+type UniObjOptimizer interface {
+	Initialize(loc *uni.Location, obj *uni.Objective) error
+	Iterate(loc *uni.Location, obj *uni.Objective, fun optimize.UniObj) (status.Status, error)
+}
+
+// OptimizeObj minimizes a univariate function for which only the
+// objective value is available. If optimizer is nil, GoldenSection is used
+func OptimizeObj(function optimize.UniObj, initialLocation float64, settings *UniObjSettings, optimizer UniObjOptimizer) (optValue float64, optLocation float64, result *UniObjResult, err error) {
+
+	if settings == nil {
+		settings = NewUniObjSettings()
+	}
+
+	if optimizer == nil {
+		optimizer = NewGoldenSection()
+	}
+
+	m := newUniObjStruct()
+	m.fun = &optimize.LimitedUniObj{
+		Fun:      function,
+		Loc:      m.loc,
+		Obj:      m.obj,
+		FunEvals: m.FunEvals,
+	}
+	m.settings = settings
+	m.optimizer = optimizer
+
+	m.loc.SetInit(initialLocation)
+	err = optimize.OptimizeOpter(m, function)
+
+	return m.obj.Opt(), m.loc.Opt(), m.Result(), err
+}
+
+type UniObjResult struct {
+	*common.CommonResult
+	*uni.ObjectiveResult
+	*uni.LocationResult
+}
+
+type UniObjSettings struct {
+	*common.CommonSettings
+	*uni.ObjectiveSettings
+	*uni.LocationSettings
+}
+
+func NewUniObjSettings() *UniObjSettings {
+	return &UniObjSettings{
+		CommonSettings:    common.NewCommonSettings(),
+		ObjectiveSettings: uni.NewObjectiveSettings(),
+		LocationSettings:  uni.NewLocationSettings(),
+	}
+}
+
+type uniObjStruct struct {
+	*common.OptCommon
+
+	loc *uni.Location
+	obj *uni.Objective
+
+	// User defined function
+	fun *optimize.LimitedUniObj
+
+	// Optimization model
+	optimizer UniObjOptimizer
+
+	// Settings
+	settings *UniObjSettings
+}
+
+func newUniObjStruct() *uniObjStruct {
+	return &uniObjStruct{
+		OptCommon: common.NewOptCommon(),
+		loc:       uni.NewLocation(),
+		obj:       uni.NewObjective(),
+	}
+}
+
+func (u *uniObjStruct) CommonSettings() *common.CommonSettings {
+	return u.settings.CommonSettings
+}
+
+func (u *uniObjStruct) SetSettings() error {
+	u.obj.SetSettings(u.settings.ObjectiveSettings)
+	u.loc.SetSettings(u.settings.LocationSettings)
+	return nil
+}
+
+func (u *uniObjStruct) Status() status.Status {
+	c := status.CheckStatus(u.obj)
+	if c != status.Continue {
+		return c
+	}
+	statuser, ok := u.optimizer.(status.Statuser)
+	if ok {
+		return statuser.Status()
+	}
+	return c
+}
+
+func (u *uniObjStruct) AddToDisplay(d []*display.Struct) []*display.Struct {
+	d = display.AddToDisplay(d, u.loc, u.obj)
+	displayer, ok := u.optimizer.(display.Displayer)
+	if ok {
+		d = displayer.AddToDisplay(d)
+	}
+	return d
+}
+
+func (u *uniObjStruct) Result() *UniObjResult {
+	return &UniObjResult{
+		CommonResult:    u.OptCommon.CommonResult(),
+		ObjectiveResult: u.obj.Result(),
+		LocationResult:  u.loc.Result(),
+	}
+}
+
+func (u *uniObjStruct) SetResult() {
+	optimize.SetResult(u.loc, u.obj)
+
+	setResulter, ok := u.optimizer.(optimize.SetResulter)
+	if ok {
+		setResulter.SetResult()
+	}
+}
+
+func (u *uniObjStruct) Initialize() error {
+	if math.IsNaN(u.obj.Init()) {
+		initObj, err := u.fun.Objective(u.loc.Init())
+		if err != nil {
+			return errors.New("error calling function during optimization: \n" + err.Error())
+		}
+		u.obj.SetInit(initObj)
+	}
+
+	err := optimize.Initialize(u.loc, u.obj)
+	if err != nil {
+		return err
+	}
+	return u.optimizer.Initialize(u.loc, u.obj)
+}
+
+func (u *uniObjStruct) Iterate() (status.Status, error) {
+	return u.optimizer.Iterate(u.loc, u.obj, u.fun)
+}
@@ -130,3 +130,81 @@ func TestCubic(t *testing.T) {
 	c := NewCubic()
 	SisoGradBasedTest(t, c)
 }
+
+func (s SumExpStruct) ObjGradHess(x float64) (f, g, h float64, err error) {
+	c1 := 0.3
+	c2 := 3.0
+	f, g, err = s.ObjGrad(x)
+	h = c1*c2*c2*math.Exp(-c2*(x-1)) + math.Exp((x - 1))
+	return f, g, h, err
+}
+
+// sumExpNoHess hides the second derivative of SumExp
+type sumExpNoHess struct{}
+
+func (sumExpNoHess) ObjGrad(x float64) (f, g float64, err error) {
+	return SumExpStruct{}.ObjGrad(x)
+}
+
+func TestNewton(t *testing.T) {
+	// The minimum is found in a handful of evaluations (Cubic takes 35
+	// from the same start)
+	for _, opter := range []UniGradOptimizer{NewNewton(), NewSecant()} {
+		settings := NewUniGradSettings()
+		settings.Display = false
+		settings.GradientAbsoluteTolerance = 1E-10
+		_, optLoc, result, err := OptimizeGrad(SumExpStruct{}, 3, settings, opter)
+		if err != nil {
+			t.Errorf("%T: error during optimization: %v", opter, err)
+			continue
+		}
+		if result.Status != status.GradAbsTol {
+			t.Errorf("%T: status is not GradAbsTol. %v found", opter, result.Status)
+		}
+		if math.Abs(optLoc-SumExpStruct{}.OptLoc()) > SISO_TOLERANCE {
+			t.Errorf("%T: optimum location not found. %v found, %v expected", opter, optLoc, SumExpStruct{}.OptLoc())
+		}
+		if result.FunctionEvaluations > 10 {
+			t.Errorf("%T: %v function evaluations", opter, result.FunctionEvaluations)
+		}
+	}
+
+	settings := NewUniGradSettings()
+	settings.Display = false
+	_, _, result, err := OptimizeGrad(sumExpNoHess{}, 2, settings, NewNewton())
+	if err == nil || result.Status != status.OptimizerError {
+		t.Errorf("no error for a function without a second derivative")
+	}
+}
+
+// shiftedSumExp is SumExp moved down so that it is negative around the
+// minimum
+type shiftedSumExp struct{}
+
+func (shiftedSumExp) ObjGrad(x float64) (f, g float64, err error) {
+	f, g, err = SumExpStruct{}.ObjGrad(x)
+	return f - 5, g, err
+}
+
+func TestNegativeObjective(t *testing.T) {
+	// With the default settings a negative objective is not a reason to
+	// stop, so the minimum is found by the gradient tolerance
+	settings := NewUniGradSettings()
+	settings.Display = false
+	settings.GradientAbsoluteTolerance = 1E-10
+	_, optLoc, result, err := OptimizeGrad(shiftedSumExp{}, 2, settings, NewCubic())
+	if err != nil {
+		t.Fatalf("error during optimization: %v", err)
+	}
+	if result.Status != status.GradAbsTol {
+		t.Errorf("status is not GradAbsTol. %v found", result.Status)
+	}
+	if math.Abs(optLoc-SumExpStruct{}.OptLoc()) > SISO_TOLERANCE {
+		t.Errorf("optimum location not found. %v found, %v expected", optLoc, SumExpStruct{}.OptLoc())
+	}
+}
+
+func TestSecant(t *testing.T) {
+	s := NewSecant()
+	SisoGradBasedTest(t, s)
+}
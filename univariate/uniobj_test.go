@@ -0,0 +1,185 @@
+package univariate
+
+import (
+	"github.com/btracey/gofunopter/common/status"
+
+	"math"
+	"testing"
+)
+
+// sumExpObj is SumExp using only the objective value
+type sumExpObj struct{}
+
+func (sumExpObj) Objective(x float64) (float64, error) {
+	f, _, err := SumExpStruct{}.ObjGrad(x)
+	return f, err
+}
+
+// sumExpDeriv is SumExp using only the derivative
+type sumExpDeriv struct{}
+
+func (sumExpDeriv) Gradient(x float64) (float64, error) {
+	_, g, err := SumExpStruct{}.ObjGrad(x)
+	return g, err
+}
+
+type linearObj struct{}
+
+func (linearObj) Objective(x float64) (float64, error) {
+	return x, nil
+}
+
+func TestBracketMinimum(t *testing.T) {
+	opt := SumExpStruct{}.OptLoc()
+	for _, x := range []float64{-5, 0.9, 10} {
+		br, err := BracketMinimum(sumExpObj{}, x, math.NaN(), 0.5)
+		if err != nil {
+			t.Errorf("x = %v: error bracketing: %v", x, err)
+			continue
+		}
+		if !(br.A < br.B && br.B < br.C) {
+			t.Errorf("x = %v: bracket locations not ordered: %v", x, br)
+		}
+		if br.FB > br.FA || br.FB > br.FC {
+			t.Errorf("x = %v: middle objective is not the smallest: %v", x, br)
+		}
+		if opt < br.A || opt > br.C {
+			t.Errorf("x = %v: minimum %v not in bracket %v", x, opt, br)
+		}
+	}
+
+	lo, hi, glo, ghi, err := BracketStationary(sumExpDeriv{}, 10, math.NaN(), 0.5)
+	if err != nil {
+		t.Fatalf("error bracketing the derivative: %v", err)
+	}
+	if glo > 0 || ghi < 0 || opt < lo || opt > hi {
+		t.Errorf("derivative bracket [%v, %v] with derivatives %v, %v does not contain %v", lo, hi, glo, ghi, opt)
+	}
+
+	if _, err := BracketMinimum(linearObj{}, 0, math.NaN(), 1); err != errNoBracket {
+		t.Errorf("unbounded function bracketed")
+	}
+}
+
+func TestGoldenSection(t *testing.T) {
+	fun := SumExpStruct{}
+	settings := NewUniObjSettings()
+	settings.Display = false
+	optVal, optLoc, result, err := OptimizeObj(sumExpObj{}, 2, settings, NewGoldenSection())
+	if err != nil {
+		t.Fatalf("error during golden section search: %v", err)
+	}
+	if result.Status != status.StepAbsTol {
+		t.Errorf("status is not StepAbsTol. %v found", result.Status)
+	}
+	if math.Abs(optVal-fun.OptVal()) > SISO_TOLERANCE {
+		t.Errorf("optimum value not found. %v found, %v expected", optVal, fun.OptVal())
+	}
+	if math.Abs(optLoc-fun.OptLoc()) > SISO_TOLERANCE {
+		t.Errorf("optimum location not found. %v found, %v expected", optLoc, fun.OptLoc())
+	}
+
+	// The budget holds part way through bracketing
+	settings.MaximumFunctionEvaluations = 3
+	_, _, result, err = OptimizeObj(sumExpObj{}, 20, settings, NewGoldenSection())
+	if err != nil {
+		t.Errorf("error when the budget runs out: %v", err)
+	}
+	if result.Status != status.MaximumFunctionEvaluations {
+		t.Errorf("status is not MaximumFunctionEvaluations. %v found", result.Status)
+	}
+	if result.FunctionEvaluations != 3 {
+		t.Errorf("%v function evaluations with a budget of 3", result.FunctionEvaluations)
+	}
+
+	settings = NewUniObjSettings()
+	settings.Display = false
+	_, _, result, err = OptimizeObj(linearObj{}, 0, settings, nil)
+	if err == nil || result.Status != status.OptimizerError {
+		t.Errorf("no error for an unbounded function. Status %v", result.Status)
+	}
+}
+
+func TestBisection(t *testing.T) {
+	fun := SumExpStruct{}
+	for _, x := range []float64{-3, 2} {
+		settings := NewUniDerivSettings()
+		settings.Display = false
+		settings.GradientAbsoluteTolerance = 1E-10
+		optGrad, optLoc, result, err := OptimizeDeriv(sumExpDeriv{}, x, settings, NewBisection())
+		if err != nil {
+			t.Errorf("x = %v: error during bisection: %v", x, err)
+			continue
+		}
+		if result.Status != status.GradAbsTol {
+			t.Errorf("x = %v: status is not GradAbsTol. %v found", x, result.Status)
+		}
+		if math.Abs(optGrad) > 1E-10 {
+			t.Errorf("x = %v: derivative %v at the optimum", x, optGrad)
+		}
+		if math.Abs(optLoc-fun.OptLoc()) > SISO_TOLERANCE {
+			t.Errorf("x = %v: optimum location not found. %v found, %v expected", x, optLoc, fun.OptLoc())
+		}
+	}
+}
+
+// sumExpBounded is SumExp with both the objective and the derivative
+type sumExpBounded struct {
+	SumExpStruct
+}
+
+func (sumExpBounded) Objective(x float64) (float64, error) {
+	f, _, err := SumExpStruct{}.ObjGrad(x)
+	return f, err
+}
+
+func TestOptimizeBounded(t *testing.T) {
+	fun := SumExpStruct{}
+	optimizers := []struct {
+		name      string
+		optimizer func() BoundedOptimizer
+	}{
+		{"Brent", func() BoundedOptimizer { return NewBrent() }},
+		{"BoundedBisection", func() BoundedOptimizer { return NewBoundedBisection() }},
+	}
+	for _, o := range optimizers {
+		for _, test := range []struct {
+			lo, hi float64
+			loc    float64
+			status status.Status
+		}{
+			{0, 3, fun.OptLoc(), status.StepAbsTol},
+			{1.5, 4, 1.5, status.BoundaryOptimum},
+			{-2, 0.5, 0.5, status.BoundaryOptimum},
+		} {
+			settings := NewBoundedSettings()
+			settings.Display = false
+			optVal, optLoc, result, err := OptimizeBounded(sumExpBounded{}, test.lo, test.hi, settings, o.optimizer())
+			if err != nil {
+				t.Errorf("%v [%v, %v]: error optimizing: %v", o.name, test.lo, test.hi, err)
+				continue
+			}
+			if result.Status != test.status {
+				t.Errorf("%v [%v, %v]: status %v, expected %v", o.name, test.lo, test.hi, result.Status, test.status)
+			}
+			if math.Abs(optLoc-test.loc) > SISO_TOLERANCE {
+				t.Errorf("%v [%v, %v]: optimum location %v, expected %v", o.name, test.lo, test.hi, optLoc, test.loc)
+			}
+			f, _ := sumExpBounded{}.Objective(optLoc)
+			if optVal != f {
+				t.Errorf("%v [%v, %v]: optimum value %v does not match the location", o.name, test.lo, test.hi, optVal)
+			}
+			if result.FunctionEvaluations > 60 {
+				t.Errorf("%v [%v, %v]: %v function evaluations", o.name, test.lo, test.hi, result.FunctionEvaluations)
+			}
+		}
+	}
+
+	// The bisection needs the derivative
+	settings := NewBoundedSettings()
+	settings.Display = false
+	_, _, _, err := OptimizeBounded(sumExpObj{}, 0, 3, settings, NewBoundedBisection())
+	if err == nil {
+		t.Errorf("no error for bounded bisection without a derivative")
+	}
+}